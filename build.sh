@@ -52,7 +52,7 @@ BuildWinArm64() {
   export CC=$(pwd)/wrapper/zcc-arm64
   export CXX=$(pwd)/wrapper/zcxx-arm64
   export CGO_ENABLED=1
-  go build -o "$1" -ldflags="$ldflags" -tags=jsoniter .
+  go build -o "$1" -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5 .
 }
 
 BuildDev() {
@@ -76,9 +76,9 @@ BuildDev() {
     export GOARCH=${os_arch##*-}
     export CC=${cgo_cc}
     export CGO_ENABLED=1
-    go build -o ./dist/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter .
+    go build -o ./dist/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter,sqlite_fts5 .
   done
-  xgo -targets=windows/amd64,darwin/amd64,darwin/arm64 -out "$appName" -ldflags="$ldflags" -tags=jsoniter .
+  xgo -targets=windows/amd64,darwin/amd64,darwin/arm64 -out "$appName" -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5 .
   mv alist-* dist
   cd dist
   cp ./alist-windows-amd64.exe ./alist-windows-amd64-upx.exe
@@ -88,7 +88,8 @@ BuildDev() {
 }
 
 BuildDocker() {
-  go build -o ./bin/alist -ldflags="$ldflags" -tags=jsoniter .
+  # the docker builder has fuse-dev installed and links dynamically, so the mount command is available
+  go build -o ./bin/alist -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5,fuse .
 }
 
 PrepareBuildDockerMusl() {
@@ -124,7 +125,7 @@ BuildDockerMultiplatform() {
     export GOARCH=$arch
     export CC=${cgo_cc}
     echo "building for $os_arch"
-    go build -o build/$os/$arch/alist -ldflags="$docker_lflags" -tags=jsoniter .
+    go build -o build/$os/$arch/alist -ldflags="$docker_lflags" -tags=jsoniter,sqlite_fts5 .
   done
 
   DOCKER_ARM_ARCHES=(linux-arm/v6 linux-arm/v7)
@@ -138,7 +139,7 @@ BuildDockerMultiplatform() {
     export GOARM=${GO_ARM[$i]}
     export CC=${cgo_cc}
     echo "building for $docker_arch"
-    go build -o build/${docker_arch%%-*}/${docker_arch##*-}/alist -ldflags="$docker_lflags" -tags=jsoniter .
+    go build -o build/${docker_arch%%-*}/${docker_arch##*-}/alist -ldflags="$docker_lflags" -tags=jsoniter,sqlite_fts5 .
   done
 }
 
@@ -146,7 +147,12 @@ BuildRelease() {
   rm -rf .git/
   mkdir -p "build"
   BuildWinArm64 ./build/alist-windows-arm64.exe
-  xgo -out "$appName" -ldflags="$ldflags" -tags=jsoniter .
+  # the linux builds get the mount command, the libfuse headers are installed in the xgo container by the setup hook
+  mkdir -p build/xgo-hooks
+  echo "apt-get update && apt-get install -y libfuse-dev" >build/xgo-hooks/setup.sh
+  xgo -targets=linux/* -hooksdir=build/xgo-hooks -out "$appName" -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5,fuse .
+  rm -rf build/xgo-hooks
+  xgo -targets=windows/*,darwin/* -out "$appName" -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5 .
   # why? Because some target platforms seem to have issues with upx compression
   upx -9 ./alist-linux-amd64
   cp ./alist-windows-amd64.exe ./alist-windows-amd64-upx.exe
@@ -176,7 +182,7 @@ BuildReleaseLinuxMusl() {
     export GOARCH=${os_arch##*-}
     export CC=${cgo_cc}
     export CGO_ENABLED=1
-    go build -o ./build/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter .
+    go build -o ./build/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter,sqlite_fts5 .
   done
 }
 
@@ -209,7 +215,7 @@ BuildReleaseLinuxMuslArm() {
     export CC=${cgo_cc}
     export CGO_ENABLED=1
     export GOARM=${arm}
-    go build -o ./build/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter .
+    go build -o ./build/$appName-$os_arch -ldflags="$muslflags" -tags=jsoniter,sqlite_fts5 .
   done
 }
 
@@ -229,7 +235,7 @@ BuildReleaseAndroid() {
     export GOARCH=${os_arch##*-}
     export CC=${cgo_cc}
     export CGO_ENABLED=1
-    go build -o ./build/$appName-android-$os_arch -ldflags="$ldflags" -tags=jsoniter .
+    go build -o ./build/$appName-android-$os_arch -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5 .
     android-ndk-r26b/toolchains/llvm/prebuilt/linux-x86_64/bin/llvm-strip ./build/$appName-android-$os_arch
   done
 }
@@ -253,7 +259,7 @@ BuildReleaseFreeBSD() {
     export CC=${cgo_cc}
     export CGO_ENABLED=1
     export CGO_LDFLAGS="-fuse-ld=lld"
-    go build -o ./build/$appName-freebsd-$os_arch -ldflags="$ldflags" -tags=jsoniter .
+    go build -o ./build/$appName-freebsd-$os_arch -ldflags="$ldflags" -tags=jsoniter,sqlite_fts5 .
   done
 }
 
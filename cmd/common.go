@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/bootstrap/data"
 	"github.com/alist-org/alist/v3/internal/db"
@@ -30,6 +31,7 @@ func Init() {
 }
 
 func Release() {
+	audit.Flush()
 	db.Close()
 }
 
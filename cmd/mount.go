@@ -0,0 +1,64 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mountOptions []string
+	mountUser    string
+)
+
+// MountCmd represents the mount command, it needs libfuse headers so it is only built with `-tags fuse`,
+// build.sh adds the tag to the dynamically linked linux builds, libfuse is loaded when mounting
+var MountCmd = &cobra.Command{
+	Use:   "mount <src> <dst>",
+	Short: "Mount a path of alist to a local directory via FUSE",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		bootstrap.InitOfflineDownloadTools()
+		bootstrap.LoadStorages()
+		bootstrap.InitTaskManager()
+		opts := make([]string, 0, len(mountOptions)*2)
+		for _, o := range mountOptions {
+			opts = append(opts, "-o", o)
+		}
+		user, err := op.GetAdmin()
+		if mountUser != "" {
+			user, err = op.GetUserByName(mountUser)
+		}
+		if err != nil {
+			utils.Log.Fatalf("failed get the mount user: %+v", err)
+		}
+		host := fuse.NewHost(args[0], user)
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-quit
+			utils.Log.Println("Unmounting...")
+			host.Unmount()
+		}()
+		utils.Log.Infof("mount [%s] to %s", args[0], args[1])
+		if !host.Mount(args[1], opts) {
+			utils.Log.Errorf("failed to mount %s", args[1])
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringVar(&mountUser, "user", "", "the user the changes are made as, the admin by default")
+	MountCmd.Flags().StringArrayVarP(&mountOptions, "option", "o", nil, "options passed to fuse, e.g. -o allow_other")
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server"
 	"github.com/gin-gonic/gin"
@@ -43,6 +44,14 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitTrash()
+		bootstrap.InitQuota()
+		bootstrap.InitAudit()
+		bootstrap.InitWebhook()
+		bootstrap.InitSyncJobs()
+		bootstrap.InitScheduler()
+		bootstrap.InitRssFeeds()
+		bootstrap.ResumeIndex()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -157,6 +166,17 @@ the address is defined in config file`,
 				}()
 			}
 		}
+		var metricsSrv *http.Server
+		if conf.Conf.Metrics.Listen != "" && conf.Conf.Metrics.Enable {
+			utils.Log.Infof("start metrics server @ %s", conf.Conf.Metrics.Listen)
+			metricsSrv = &http.Server{Addr: conf.Conf.Metrics.Listen, Handler: metrics.Handler()}
+			go func() {
+				err := metricsSrv.ListenAndServe()
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					utils.Log.Fatalf("failed to start metrics server: %s", err.Error())
+				}
+			}()
+		}
 		// Wait for interrupt signal to gracefully shutdown the server with
 		// a timeout of 1 second.
 		quit := make(chan os.Signal, 1)
@@ -217,6 +237,15 @@ the address is defined in config file`,
 				}
 			}()
 		}
+		if metricsSrv != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := metricsSrv.Shutdown(ctx); err != nil {
+					utils.Log.Fatal("metrics server shutdown err: ", err)
+				}
+			}()
+		}
 		wg.Wait()
 		utils.Log.Println("Server exit")
 	},
@@ -1,16 +1,17 @@
 package archives
 
 import (
+	"context"
 	"io"
 	"io/fs"
 	"os"
-	stdpath "path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/archive/tool"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/mholt/archives"
 )
 
 type Archives struct {
@@ -89,53 +90,85 @@ func (Archives) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs
 	return file, stat.Size(), nil
 }
 
-func (Archives) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+func (Archives) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
 	fsys, err := getFs(ss[0], args.ArchiveArgs)
 	if err != nil {
 		return err
 	}
-	isDir := false
-	path := strings.TrimPrefix(args.InnerPath, "/")
-	if path == "" {
-		isDir = true
-		path = "."
-	} else {
-		stat, err := fsys.Stat(path)
+	s := tool.NewSelector(args)
+	type selectedFile struct {
+		path   string
+		target string
+		info   fs.FileInfo
+	}
+	var files []selectedFile
+	var total int64
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return filterPassword(err)
+			return err
 		}
-		if stat.IsDir() {
-			isDir = true
-			outputPath = stdpath.Join(outputPath, stat.Name())
-			err = os.Mkdir(outputPath, 0700)
-			if err != nil {
-				return filterPassword(err)
-			}
+		target, ok := s.Target(p)
+		if !ok {
+			return nil
 		}
-	}
-	if isDir {
-		err = fs.WalkDir(fsys, path, func(p string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			relPath := strings.TrimPrefix(p, path+"/")
-			dstPath := stdpath.Join(outputPath, relPath)
-			if d.IsDir() {
-				err = os.MkdirAll(dstPath, 0700)
-			} else {
-				dir := stdpath.Dir(dstPath)
-				err = decompress(fsys, p, dir, func(_ float64) {})
-			}
+		info, err := d.Info()
+		if err != nil {
 			return err
-		})
-	} else {
-		err = decompress(fsys, path, outputPath, up)
+		}
+		files = append(files, selectedFile{path: p, target: target, info: info})
+		if !d.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return filterPassword(err)
+	}
+	if err = s.CheckSelected(len(files)); err != nil {
+		return err
 	}
-	return filterPassword(err)
+	pr := tool.NewProgress(total, up)
+	for _, f := range files {
+		if f.info.IsDir() {
+			err = tool.DecompressDir(outputPath, f.target)
+		} else {
+			err = decompress(fsys, f.path, outputPath, f.target, args.ConflictPolicy, pr.File(f.info.Size()))
+		}
+		if err != nil {
+			return filterPassword(err)
+		}
+	}
+	return nil
+}
+
+func (Archives) Test(ss []*stream.SeekableStream, args model.ArchiveArgs, up model.UpdateProgress) ([]model.ArchiveTestEntry, error) {
+	fsys, err := getFs(ss[0], args)
+	if err != nil {
+		return nil, err
+	}
+	// the entries are read in one pass, the progress is the part of the archive read
+	reader := &stream.ReaderUpdatingProgress{
+		Reader: &stream.SimpleReaderWithSize{
+			Reader: fsys.Stream,
+			Size:   ss[0].GetSize(),
+		},
+		UpdateProgress: up,
+	}
+	var entries []model.ArchiveTestEntry
+	err = fsys.Format.Extract(ss[0].Ctx, reader, func(ctx context.Context, info archives.FileInfo) error {
+		if info.IsDir() {
+			return nil
+		}
+		entries = append(entries, tool.MakeArchiveTestEntry(info.NameInArchive, info.Size(), testFile(info)))
+		return nil
+	})
+	return entries, filterPassword(err)
 }
 
 var _ tool.Tool = (*Archives)(nil)
+var _ tool.Tester = (*Archives)(nil)
 
 func init() {
 	tool.RegisterTool(Archives{})
+	tool.RegisterCompressor(Archives{})
 }
@@ -0,0 +1,76 @@
+package archives
+
+import (
+	"archive/tar"
+	stderrors "errors"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/mholt/archives"
+	"github.com/pkg/errors"
+)
+
+type tarWriter struct {
+	tw *tar.Writer
+	// compressor is the writer under the tar writer, it's nil for the plain tar
+	compressor io.WriteCloser
+}
+
+func (t *tarWriter) Create(name string, obj model.Obj) (io.Writer, error) {
+	hdr := &tar.Header{
+		Name:     name,
+		Size:     obj.GetSize(),
+		Mode:     0o644,
+		ModTime:  obj.ModTime(),
+		Typeflag: tar.TypeReg,
+	}
+	if obj.IsDir() {
+		hdr.Name += "/"
+		hdr.Size = 0
+		hdr.Mode = 0o755
+		hdr.Typeflag = tar.TypeDir
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarWriter) Close() error {
+	err := t.tw.Close()
+	if t.compressor != nil {
+		err = stderrors.Join(err, t.compressor.Close())
+	}
+	return err
+}
+
+func (Archives) CompressExtensions() []string {
+	return []string{".tar", ".tar.gz", ".tgz", ".tar.zst"}
+}
+
+func (Archives) NewWriter(w io.Writer, ext string, password string) (tool.ArchiveWriter, error) {
+	if password != "" {
+		return nil, errors.Errorf("%s archive doesn't support password", ext)
+	}
+	var compressor io.WriteCloser
+	var err error
+	switch ext {
+	case ".tar":
+	case ".tar.gz", ".tgz":
+		compressor, err = archives.Gz{}.OpenWriter(w)
+	case ".tar.zst":
+		compressor, err = archives.Zstd{}.OpenWriter(w)
+	default:
+		return nil, errors.Errorf("unsupported compress format %s", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if compressor != nil {
+		w = compressor
+	}
+	return &tarWriter{tw: tar.NewWriter(w), compressor: compressor}, nil
+}
+
+var _ tool.Compressor = (*Archives)(nil)
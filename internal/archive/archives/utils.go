@@ -1,16 +1,16 @@
 package archives
 
 import (
+	"fmt"
 	"io"
 	fs2 "io/fs"
 	"os"
-	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/archive/tool"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
-	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/mholt/archives"
 )
 
@@ -59,7 +59,7 @@ func filterPassword(err error) error {
 	return err
 }
 
-func decompress(fsys fs2.FS, filePath, targetPath string, up model.UpdateProgress) error {
+func decompress(fsys fs2.FS, filePath, outputPath, target, conflictPolicy string, up model.UpdateProgress) error {
 	rc, err := fsys.Open(filePath)
 	if err != nil {
 		return err
@@ -69,17 +69,22 @@ func decompress(fsys fs2.FS, filePath, targetPath string, up model.UpdateProgres
 	if err != nil {
 		return err
 	}
-	f, err := os.OpenFile(stdpath.Join(targetPath, stat.Name()), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	return tool.DecompressFile(rc, stat.Size(), outputPath, target, conflictPolicy, up)
+}
+
+// testFile reads the file to the end, the checksums of the compressed stream are verified by the decompressor
+func testFile(info archives.FileInfo) error {
+	rc, err := info.Open()
 	if err != nil {
-		return err
+		return filterPassword(err)
 	}
-	defer f.Close()
-	_, err = utils.CopyWithBuffer(f, &stream.ReaderUpdatingProgress{
-		Reader: &stream.SimpleReaderWithSize{
-			Reader: rc,
-			Size:   stat.Size(),
-		},
-		UpdateProgress: up,
-	})
-	return err
+	defer rc.Close()
+	n, err := io.Copy(io.Discard, rc)
+	if err != nil {
+		return filterPassword(err)
+	}
+	if n != info.Size() {
+		return fmt.Errorf("the size is %d, but %d bytes read", info.Size(), n)
+	}
+	return nil
 }
@@ -7,8 +7,6 @@ import (
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/kdomanski/iso9660"
 	"io"
-	"os"
-	stdpath "path"
 )
 
 type ISO9660 struct {
@@ -67,30 +65,45 @@ func (ISO9660) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs)
 	return io.NopCloser(obj.Reader()), obj.Size(), nil
 }
 
-func (ISO9660) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+func (ISO9660) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
 	img, err := getImage(ss[0])
 	if err != nil {
 		return err
 	}
-	obj, err := getObj(img, args.InnerPath)
+	root, err := img.RootDir()
 	if err != nil {
 		return err
 	}
-	if obj.IsDir() {
-		if args.InnerPath != "/" {
-			outputPath = stdpath.Join(outputPath, obj.Name())
-			if err = os.MkdirAll(outputPath, 0700); err != nil {
-				return err
-			}
+	s := tool.NewSelector(args)
+	var files []selectedFile
+	if err = walk(root, "", func(name string, f *iso9660.File) {
+		if target, ok := s.Target(name); ok {
+			files = append(files, selectedFile{file: f, target: target})
+		}
+	}); err != nil {
+		return err
+	}
+	if err = s.CheckSelected(len(files)); err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		if !f.file.IsDir() {
+			total += f.file.Size()
+		}
+	}
+	p := tool.NewProgress(total, up)
+	for _, f := range files {
+		if f.file.IsDir() {
+			err = tool.DecompressDir(outputPath, f.target)
+		} else {
+			err = tool.DecompressFile(f.file.Reader(), f.file.Size(), outputPath, f.target, args.ConflictPolicy, p.File(f.file.Size()))
 		}
-		var children []*iso9660.File
-		if children, err = obj.GetChildren(); err == nil {
-			err = decompressAll(children, outputPath)
+		if err != nil {
+			return err
 		}
-	} else {
-		err = decompress(obj, outputPath, up)
 	}
-	return err
+	return nil
 }
 
 var _ tool.Tool = (*ISO9660)(nil)
@@ -1,14 +1,12 @@
 package iso9660
 
 import (
-	"os"
 	stdpath "path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
-	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/kdomanski/iso9660"
 )
 
@@ -61,38 +59,22 @@ func toModelObj(file *iso9660.File) model.Obj {
 	}
 }
 
-func decompress(f *iso9660.File, path string, up model.UpdateProgress) error {
-	file, err := os.OpenFile(stdpath.Join(path, f.Name()), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+type selectedFile struct {
+	file   *iso9660.File
+	target string
+}
+
+// walk calls fn with the path in the image of each file under the dir
+func walk(dir *iso9660.File, dirPath string, fn func(name string, f *iso9660.File)) error {
+	children, err := dir.GetChildren()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	_, err = utils.CopyWithBuffer(file, &stream.ReaderUpdatingProgress{
-		Reader: &stream.SimpleReaderWithSize{
-			Reader: f.Reader(),
-			Size:   f.Size(),
-		},
-		UpdateProgress: up,
-	})
-	return err
-}
-
-func decompressAll(children []*iso9660.File, path string) error {
 	for _, child := range children {
+		name := stdpath.Join(dirPath, child.Name())
+		fn(name, child)
 		if child.IsDir() {
-			nextChildren, err := child.GetChildren()
-			if err != nil {
-				return err
-			}
-			nextPath := stdpath.Join(path, child.Name())
-			if err = os.MkdirAll(nextPath, 0700); err != nil {
-				return err
-			}
-			if err = decompressAll(nextChildren, nextPath); err != nil {
-				return err
-			}
-		} else {
-			if err := decompress(child, path, func(_ float64) {}); err != nil {
+			if err = walk(child, name, fn); err != nil {
 				return err
 			}
 		}
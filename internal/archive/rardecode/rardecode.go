@@ -1,14 +1,14 @@
 package rardecode
 
 import (
+	"errors"
+	"fmt"
 	"github.com/alist-org/alist/v3/internal/archive/tool"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/nwaples/rardecode/v2"
 	"io"
-	"os"
-	stdpath "path"
 	"strings"
 )
 
@@ -66,74 +66,95 @@ func (RarDecoder) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerAr
 	return nil, 0, errs.ObjectNotFound
 }
 
-func (RarDecoder) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+func (RarDecoder) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
+	s := tool.NewSelector(args)
+	var total int64
+	if l, err := list(ss, args.Password); err == nil {
+		for _, f := range l.files {
+			if _, ok := s.Target(f.Name); ok && !f.IsDir {
+				total += f.UnPackedSize
+			}
+		}
+	}
 	reader, err := getReader(ss, args.Password)
 	if err != nil {
 		return err
 	}
-	if args.InnerPath == "/" {
-		for {
-			var header *rardecode.FileHeader
-			header, err = reader.Next()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return err
-			}
-			name := header.Name
-			if header.IsDir {
-				name = name + "/"
-			}
-			err = decompress(reader, header, name, outputPath)
-			if err != nil {
-				return err
-			}
+	p := tool.NewProgress(total, up)
+	selected := 0
+	for {
+		var header *rardecode.FileHeader
+		header, err = reader.Next()
+		if err == io.EOF {
+			break
 		}
-	} else {
-		innerPath := strings.TrimPrefix(args.InnerPath, "/")
-		innerBase := stdpath.Base(innerPath)
-		createdBaseDir := false
-		for {
-			var header *rardecode.FileHeader
-			header, err = reader.Next()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return err
-			}
-			name := header.Name
-			if header.IsDir {
-				name = name + "/"
-			}
-			if name == innerPath {
-				err = _decompress(reader, header, outputPath, up)
-				if err != nil {
-					return err
-				}
-				break
-			} else if strings.HasPrefix(name, innerPath+"/") {
-				targetPath := stdpath.Join(outputPath, innerBase)
-				if !createdBaseDir {
-					err = os.Mkdir(targetPath, 0700)
-					if err != nil {
-						return err
-					}
-					createdBaseDir = true
-				}
-				restPath := strings.TrimPrefix(name, innerPath+"/")
-				err = decompress(reader, header, restPath, targetPath)
-				if err != nil {
-					return err
-				}
+		if err != nil {
+			return err
+		}
+		target, ok := s.Target(header.Name)
+		if !ok {
+			continue
+		}
+		selected++
+		if header.IsDir {
+			err = tool.DecompressDir(outputPath, target)
+		} else {
+			err = tool.DecompressFile(reader, header.UnPackedSize, outputPath, target, args.ConflictPolicy, p.File(header.UnPackedSize))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.CheckSelected(selected)
+}
+
+func (RarDecoder) Test(ss []*stream.SeekableStream, args model.ArchiveArgs, up model.UpdateProgress) ([]model.ArchiveTestEntry, error) {
+	var total int64
+	if l, err := list(ss, args.Password); err == nil {
+		for _, f := range l.files {
+			if !f.IsDir {
+				total += f.UnPackedSize
 			}
 		}
 	}
-	return nil
+	reader, err := getReader(ss, args.Password)
+	if err != nil {
+		return nil, filterVolume(err)
+	}
+	var entries []model.ArchiveTestEntry
+	var read int64
+	for {
+		var header *rardecode.FileHeader
+		header, err = reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entries, filterVolume(filterPassword(err))
+		}
+		if header.IsDir {
+			continue
+		}
+		// the checksum of the file is verified by the reader when the file is read to the end
+		n, err := io.Copy(io.Discard, reader)
+		if err == nil && !header.UnKnownSize && n != header.UnPackedSize {
+			err = fmt.Errorf("the size is %d, but %d bytes read", header.UnPackedSize, n)
+		}
+		err = filterVolume(err)
+		entries = append(entries, tool.MakeArchiveTestEntry(header.Name, header.UnPackedSize, err))
+		if errors.Is(err, errs.ArchiveVolumeMissing) {
+			return entries, err
+		}
+		read += header.UnPackedSize
+		if total > 0 {
+			up(float64(read) * 100.0 / float64(total))
+		}
+	}
+	return entries, nil
 }
 
 var _ tool.Tool = (*RarDecoder)(nil)
+var _ tool.Tester = (*RarDecoder)(nil)
 
 func init() {
 	tool.RegisterTool(RarDecoder{})
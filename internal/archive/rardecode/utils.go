@@ -1,15 +1,13 @@
 package rardecode
 
 import (
+	"errors"
 	"fmt"
 	"github.com/alist-org/alist/v3/internal/archive/tool"
 	"github.com/alist-org/alist/v3/internal/errs"
-	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/nwaples/rardecode/v2"
-	"io"
 	"io/fs"
-	"os"
 	stdpath "path"
 	"sort"
 	"strings"
@@ -179,47 +177,17 @@ func getReader(ss []*stream.SeekableStream, password string) (*rardecode.Reader,
 	return &rc.Reader, nil
 }
 
-func decompress(reader *rardecode.Reader, header *rardecode.FileHeader, filePath, outputPath string) error {
-	targetPath := outputPath
-	dir, base := stdpath.Split(filePath)
-	if dir != "" {
-		targetPath = stdpath.Join(targetPath, dir)
-		err := os.MkdirAll(targetPath, 0700)
-		if err != nil {
-			return err
-		}
-	}
-	if base != "" {
-		err := _decompress(reader, header, targetPath, func(_ float64) {})
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func _decompress(reader *rardecode.Reader, header *rardecode.FileHeader, targetPath string, up model.UpdateProgress) error {
-	f, err := os.OpenFile(stdpath.Join(targetPath, stdpath.Base(header.Name)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = f.Close() }()
-	_, err = io.Copy(f, &stream.ReaderUpdatingProgress{
-		Reader: &stream.SimpleReaderWithSize{
-			Reader: reader,
-			Size:   header.UnPackedSize,
-		},
-		UpdateProgress: up,
-	})
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func filterPassword(err error) error {
 	if err != nil && strings.Contains(err.Error(), "password") {
 		return errs.WrongArchivePassword
 	}
 	return err
 }
+
+// filterVolume converts the error opening the next volume, which isn't in the volumes given
+func filterVolume(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return errs.ArchiveVolumeMissing
+	}
+	return err
+}
@@ -57,7 +57,7 @@ func (SevenZip) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs
 	return nil, 0, errs.ObjectNotFound
 }
 
-func (SevenZip) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+func (SevenZip) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
 	reader, err := getReader(ss, args.Password)
 	if err != nil {
 		return err
@@ -65,7 +65,16 @@ func (SevenZip) Decompress(ss []*stream.SeekableStream, outputPath string, args
 	return tool.DecompressFromFolderTraversal(&WrapReader{Reader: reader}, outputPath, args, up)
 }
 
+func (SevenZip) Test(ss []*stream.SeekableStream, args model.ArchiveArgs, up model.UpdateProgress) ([]model.ArchiveTestEntry, error) {
+	reader, err := getReader(ss, args.Password)
+	if err != nil {
+		return nil, err
+	}
+	return tool.TestFromFolderTraversal(&WrapReader{Reader: reader}, args.Password, up), nil
+}
+
 var _ tool.Tool = (*SevenZip)(nil)
+var _ tool.Tester = (*SevenZip)(nil)
 
 func init() {
 	tool.RegisterTool(SevenZip{})
@@ -38,6 +38,11 @@ func (f *WrapFile) Open() (io.ReadCloser, error) {
 	return f.f.Open()
 }
 
+// CRC32 is recorded by 7z, but not verified by the reader
+func (f *WrapFile) CRC32() (uint32, bool) {
+	return f.f.CRC32, f.f.CRC32 != 0
+}
+
 func getReader(ss []*stream.SeekableStream, password string) (*sevenzip.Reader, error) {
 	readerAt, err := stream.NewMultiReaderAt(ss)
 	if err != nil {
@@ -17,5 +17,25 @@ type Tool interface {
 	GetMeta(ss []*stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error)
 	List(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) ([]model.Obj, error)
 	Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error)
-	Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error
+	// Decompress decompresses the entries selected by NewSelector into the output path
+	Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error
+}
+
+// Tester reads all the files in the archive to verify them, the files failed are reported in the entries
+// with the errors, and an error is returned with the entries tested so far only if the archive can't be
+// read any further
+type Tester interface {
+	Test(ss []*stream.SeekableStream, args model.ArchiveArgs, up model.UpdateProgress) ([]model.ArchiveTestEntry, error)
+}
+
+// ArchiveWriter writes the entries into the archive one by one, the content of an entry must be written before
+// the next entry is created, and the dir entries have no content
+type ArchiveWriter interface {
+	Create(name string, obj model.Obj) (io.Writer, error)
+	Close() error
+}
+
+type Compressor interface {
+	CompressExtensions() []string
+	NewWriter(w io.Writer, ext string, password string) (ArchiveWriter, error)
 }
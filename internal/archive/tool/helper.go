@@ -1,14 +1,18 @@
 package tool
 
 import (
+	"fmt"
+	"hash/crc32"
 	"io"
 	"io/fs"
 	"os"
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type SubFile interface {
@@ -22,6 +26,11 @@ type CanEncryptSubFile interface {
 	SetPassword(password string)
 }
 
+// CanVerifySubFile is the sub file which the archive records the CRC32 of, but the reader doesn't verify
+type CanVerifySubFile interface {
+	CRC32() (uint32, bool)
+}
+
 type ArchiveReader interface {
 	Files() []SubFile
 }
@@ -113,92 +122,262 @@ type WrapFileInfo struct {
 	model.Obj
 }
 
-func DecompressFromFolderTraversal(r ArchiveReader, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
-	var err error
-	files := r.Files()
-	if args.InnerPath == "/" {
-		for i, file := range files {
-			name := file.Name()
-			err = decompress(file, name, outputPath, args.Password)
-			if err != nil {
-				return err
-			}
-			up(float64(i+1) * 100.0 / float64(len(files)))
+// Selector selects the entries to decompress by the inner paths, which can be glob patterns matching the names
+// in the archive. The entry selected is decompressed into the output dir with its base name, and the entries in
+// the dir selected are decompressed into the dir
+type Selector struct {
+	all   bool
+	paths []string
+}
+
+func NewSelector(args model.ArchiveDecompressArgs) *Selector {
+	paths := args.InnerPaths
+	if len(paths) == 0 {
+		paths = []string{args.InnerPath}
+	}
+	s := &Selector{}
+	for _, p := range paths {
+		p = strings.Trim(p, "/")
+		if p == "" {
+			return &Selector{all: true}
 		}
-	} else {
-		innerPath := strings.TrimPrefix(args.InnerPath, "/")
-		innerBase := stdpath.Base(innerPath)
-		createdBaseDir := false
-		for _, file := range files {
-			name := file.Name()
-			if name == innerPath {
-				err = _decompress(file, outputPath, args.Password, up)
-				if err != nil {
-					return err
-				}
-				break
-			} else if strings.HasPrefix(name, innerPath+"/") {
-				targetPath := stdpath.Join(outputPath, innerBase)
-				if !createdBaseDir {
-					err = os.Mkdir(targetPath, 0700)
-					if err != nil {
-						return err
-					}
-					createdBaseDir = true
-				}
-				restPath := strings.TrimPrefix(name, innerPath+"/")
-				err = decompress(file, restPath, targetPath, args.Password)
-				if err != nil {
-					return err
-				}
-			}
+		s.paths = append(s.paths, p)
+	}
+	return s
+}
+
+// Target returns the path relative to the output dir the entry is decompressed to,
+// false is returned if the entry isn't selected
+func (s *Selector) Target(name string) (string, bool) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "", false
+	}
+	if s.all {
+		return name, true
+	}
+	names := strings.Split(name, "/")
+	for i := range names {
+		if s.match(strings.Join(names[:i+1], "/")) {
+			return strings.Join(names[i:], "/"), true
 		}
 	}
+	return "", false
+}
+
+func (s *Selector) match(name string) bool {
+	for _, p := range s.paths {
+		if p == name {
+			return true
+		}
+		if ok, _ := stdpath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSelected returns errs.ObjectNotFound if the inner paths select nothing
+func (s *Selector) CheckSelected(n int) error {
+	if n == 0 && !s.all {
+		return errs.ObjectNotFound
+	}
 	return nil
 }
 
-func decompress(file SubFile, filePath, outputPath, password string) error {
-	targetPath := outputPath
-	dir, base := stdpath.Split(filePath)
-	if dir != "" {
-		targetPath = stdpath.Join(targetPath, dir)
-		err := os.MkdirAll(targetPath, 0700)
-		if err != nil {
-			return err
+// CreateFile creates the file decompressed by the conflict policy if the file exists,
+// nil is returned without an error if the file is skipped
+func CreateFile(path, conflictPolicy string) (*os.File, error) {
+	switch conflictPolicy {
+	case model.ConflictSkip:
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			return nil, nil
+		}
+		return f, err
+	case model.ConflictRename:
+		dir, name := stdpath.Split(path)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		for i := 1; os.IsExist(err); i++ {
+			f, err = os.OpenFile(stdpath.Join(dir, utils.NumberedName(name, i)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		}
+		return f, err
+	default:
+		// the file existing is replaced instead of truncated, so that a link isn't written through
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	}
+}
+
+// outputTarget joins the target to the output dir, the target escaping the output dir by ".." is rejected
+func outputTarget(outputPath, target string) (string, error) {
+	path := stdpath.Join(outputPath, target)
+	if !utils.IsSubPath(outputPath, path) {
+		return "", fmt.Errorf("the entry [%s] is outside the output dir", target)
+	}
+	return path, nil
+}
+
+// DecompressDir creates the dir of the entry at the target relative to the output dir
+func DecompressDir(outputPath, target string) error {
+	path, err := outputTarget(outputPath, target)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, 0700)
+}
+
+// DecompressFile writes the content of the entry into the target relative to the output dir,
+// the dir of the target is created if not exists
+func DecompressFile(r io.Reader, size int64, outputPath, target, conflictPolicy string, up model.UpdateProgress) error {
+	path, err := outputTarget(outputPath, target)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(stdpath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := CreateFile(path, conflictPolicy)
+	if err != nil || f == nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = utils.CopyWithBuffer(f, &stream.ReaderUpdatingProgress{
+		Reader: &stream.SimpleReaderWithSize{
+			Reader: r,
+			Size:   size,
+		},
+		UpdateProgress: up,
+	})
+	return err
+}
+
+// Progress sums up the progress of the files decompressed one by one
+type Progress struct {
+	up    model.UpdateProgress
+	total int64
+	done  int64
+}
+
+func NewProgress(total int64, up model.UpdateProgress) *Progress {
+	return &Progress{up: up, total: total}
+}
+
+// File returns the progress of the next file, which is done when the progress of the file is 100
+func (p *Progress) File(size int64) model.UpdateProgress {
+	done := p.done
+	p.done += size
+	return func(percent float64) {
+		if p.total > 0 {
+			p.up((float64(done) + float64(size)*percent/100.0) * 100.0 / float64(p.total))
+		}
+	}
+}
+
+func DecompressFromFolderTraversal(r ArchiveReader, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
+	s := NewSelector(args)
+	type selectedFile struct {
+		file   SubFile
+		target string
+	}
+	var files []selectedFile
+	var total int64
+	for _, file := range r.Files() {
+		if target, ok := s.Target(file.Name()); ok {
+			files = append(files, selectedFile{file: file, target: target})
+			if !file.FileInfo().IsDir() {
+				total += file.FileInfo().Size()
+			}
 		}
 	}
-	if base != "" {
-		err := _decompress(file, targetPath, password, func(_ float64) {})
-		if err != nil {
+	if err := s.CheckSelected(len(files)); err != nil {
+		return err
+	}
+	p := NewProgress(total, up)
+	for _, f := range files {
+		if f.file.FileInfo().IsDir() {
+			if err := DecompressDir(outputPath, f.target); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decompress(f.file, outputPath, f.target, args, p.File(f.file.FileInfo().Size())); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func _decompress(file SubFile, targetPath, password string, up model.UpdateProgress) error {
+func decompress(file SubFile, outputPath, target string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
 	if encrypt, ok := file.(CanEncryptSubFile); ok && encrypt.IsEncrypted() {
-		encrypt.SetPassword(password)
+		encrypt.SetPassword(args.Password)
 	}
 	rc, err := file.Open()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = rc.Close() }()
-	f, err := os.OpenFile(stdpath.Join(targetPath, file.FileInfo().Name()), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	return DecompressFile(rc, file.FileInfo().Size(), outputPath, target, args.ConflictPolicy, up)
+}
+
+func MakeArchiveTestEntry(name string, size int64, err error) model.ArchiveTestEntry {
+	entry := model.ArchiveTestEntry{
+		Name:   strings.TrimPrefix(name, "/"),
+		Size:   size,
+		Passed: err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func TestFromFolderTraversal(r ArchiveReader, password string, up model.UpdateProgress) []model.ArchiveTestEntry {
+	files := r.Files()
+	var total, read int64
+	for _, file := range files {
+		if !file.FileInfo().IsDir() {
+			total += file.FileInfo().Size()
+		}
+	}
+	entries := make([]model.ArchiveTestEntry, 0, len(files))
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		size := file.FileInfo().Size()
+		entries = append(entries, MakeArchiveTestEntry(file.Name(), size, testSubFile(file, password)))
+		read += size
+		if total > 0 {
+			up(float64(read) * 100.0 / float64(total))
+		}
+	}
+	return entries
+}
+
+func testSubFile(file SubFile, password string) error {
+	if encrypt, ok := file.(CanEncryptSubFile); ok && encrypt.IsEncrypted() {
+		encrypt.SetPassword(password)
+	}
+	rc, err := file.Open()
 	if err != nil {
 		return err
 	}
-	defer func() { _ = f.Close() }()
-	_, err = io.Copy(f, &stream.ReaderUpdatingProgress{
-		Reader: &stream.SimpleReaderWithSize{
-			Reader: rc,
-			Size:   file.FileInfo().Size(),
-		},
-		UpdateProgress: up,
-	})
+	defer func() { _ = rc.Close() }()
+	h := crc32.NewIEEE()
+	n, err := io.Copy(h, rc)
 	if err != nil {
 		return err
 	}
+	if n != file.FileInfo().Size() {
+		return fmt.Errorf("the size is %d, but %d bytes read", file.FileInfo().Size(), n)
+	}
+	if v, ok := file.(CanVerifySubFile); ok {
+		if sum, ok := v.CRC32(); ok && sum != h.Sum32() {
+			return fmt.Errorf("checksum error, expected %08x, got %08x", sum, h.Sum32())
+		}
+	}
 	return nil
 }
@@ -1,12 +1,15 @@
 package tool
 
 import (
+	"strings"
+
 	"github.com/alist-org/alist/v3/internal/errs"
 )
 
 var (
 	Tools               = make(map[string]Tool)
 	MultipartExtensions = make(map[string]MultipartExtension)
+	Compressors         = make(map[string]Compressor)
 )
 
 func RegisterTool(tool Tool) {
@@ -30,3 +33,24 @@ func GetArchiveTool(ext string) (*MultipartExtension, Tool, error) {
 	}
 	return &partExt, t, nil
 }
+
+func RegisterCompressor(c Compressor) {
+	for _, ext := range c.CompressExtensions() {
+		Compressors[ext] = c
+	}
+}
+
+// GetCompressor returns the compressor by the longest extension the name ends with, so that .tar.gz isn't taken as .gz
+func GetCompressor(name string) (string, Compressor, error) {
+	name = strings.ToLower(name)
+	var ext string
+	for e := range Compressors {
+		if strings.HasSuffix(name, e) && len(e) > len(ext) {
+			ext = e
+		}
+	}
+	if ext == "" {
+		return "", nil, errs.UnknownArchiveFormat
+	}
+	return ext, Compressors[ext], nil
+}
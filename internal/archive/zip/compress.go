@@ -0,0 +1,54 @@
+package zip
+
+import (
+	"io"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/yeka/zip"
+)
+
+// utf8Flag marks the name is encoded in UTF-8
+const utf8Flag = 0x800
+
+type zipWriter struct {
+	w        *zip.Writer
+	password string
+}
+
+func (z *zipWriter) Create(name string, obj model.Obj) (io.Writer, error) {
+	fh := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+		Flags:  utf8Flag,
+	}
+	fh.SetModTime(obj.ModTime())
+	if obj.IsDir() {
+		fh.Name += "/"
+		fh.Method = zip.Store
+		fh.SetMode(os.ModeDir | 0o755)
+		return z.w.CreateHeader(fh)
+	}
+	fh.SetMode(0o644)
+	fh.UncompressedSize64 = uint64(obj.GetSize())
+	if z.password != "" {
+		fh.SetPassword(z.password)
+		fh.SetEncryptionMethod(zip.AES256Encryption)
+	}
+	return z.w.CreateHeader(fh)
+}
+
+func (z *zipWriter) Close() error {
+	return z.w.Close()
+}
+
+func (Zip) CompressExtensions() []string {
+	return []string{".zip"}
+}
+
+func (Zip) NewWriter(w io.Writer, ext string, password string) (tool.ArchiveWriter, error) {
+	return &zipWriter{w: zip.NewWriter(w), password: password}, nil
+}
+
+var _ tool.Compressor = (*Zip)(nil)
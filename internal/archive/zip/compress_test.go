@@ -0,0 +1,55 @@
+package zip
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/yeka/zip"
+)
+
+func TestCompressWithPassword(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := Zip{}.NewWriter(&buf, ".zip", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("hello alist")
+	if _, err = w.Create("dir", &model.Object{Name: "dir", IsFolder: true, Modified: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := w.Create("dir/文件.txt", &model.Object{Name: "文件.txt", Size: int64(len(content)), Modified: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed read archive: %+v", err)
+	}
+	if len(r.File) != 2 || !r.File[0].FileInfo().IsDir() || r.File[1].Name != "dir/文件.txt" {
+		t.Fatalf("unexpected files in archive")
+	}
+	f := r.File[1]
+	if !f.IsEncrypted() {
+		t.Fatalf("the file should be encrypted")
+	}
+	f.SetPassword("secret")
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("content is %q, %v, want %q", got, err, content)
+	}
+}
@@ -117,7 +117,7 @@ func (Zip) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io
 	return nil, 0, errs.ObjectNotFound
 }
 
-func (Zip) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+func (Zip) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveDecompressArgs, up model.UpdateProgress) error {
 	zipReader, err := getReader(ss)
 	if err != nil {
 		return err
@@ -125,8 +125,18 @@ func (Zip) Decompress(ss []*stream.SeekableStream, outputPath string, args model
 	return tool.DecompressFromFolderTraversal(&WrapReader{Reader: zipReader}, outputPath, args, up)
 }
 
+func (Zip) Test(ss []*stream.SeekableStream, args model.ArchiveArgs, up model.UpdateProgress) ([]model.ArchiveTestEntry, error) {
+	zipReader, err := getReader(ss)
+	if err != nil {
+		return nil, err
+	}
+	return tool.TestFromFolderTraversal(&WrapReader{Reader: zipReader}, args.Password, up), nil
+}
+
 var _ tool.Tool = (*Zip)(nil)
+var _ tool.Tester = (*Zip)(nil)
 
 func init() {
 	tool.RegisterTool(Zip{})
+	tool.RegisterCompressor(Zip{})
 }
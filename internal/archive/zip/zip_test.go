@@ -0,0 +1,172 @@
+package zip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (bytesFile) Close() error {
+	return nil
+}
+
+func TestTestCorrupted(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := Zip{}.NewWriter(&buf, ".zip", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"good.txt", "bad.txt"} {
+		content := bytes.Repeat([]byte(name), 64)
+		fw, err := w.Create(name, &model.Object{Name: name, Size: int64(len(content)), Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// flip a byte of the data of bad.txt after its local file header
+	h := bytes.Index(data, []byte("bad.txt")) - 30
+	if h < 0 || !bytes.Equal(data[h:h+4], []byte("PK\x03\x04")) {
+		t.Fatalf("failed find the local file header of bad.txt")
+	}
+	start := h + 30 + int(binary.LittleEndian.Uint16(data[h+26:])) + int(binary.LittleEndian.Uint16(data[h+28:]))
+	data[start+1] ^= 0xff
+
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj:    &model.Object{Name: "test.zip", Size: int64(len(data))},
+		Reader: bytesFile{Reader: bytes.NewReader(data)},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress float64
+	entries, err := Zip{}.Test([]*stream.SeekableStream{ss}, model.ArchiveArgs{}, func(p float64) { progress = p })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "good.txt" || entries[1].Name != "bad.txt" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if !entries[0].Passed || entries[1].Passed || entries[1].Error == "" {
+		t.Errorf("only bad.txt should fail: %+v", entries)
+	}
+	if progress != 100 {
+		t.Errorf("progress is %v, want 100", progress)
+	}
+}
+
+func TestDecompressSelected(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := Zip{}.NewWriter(&buf, ".zip", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"a/x.txt": "a", "b/x.txt": "b", "c/d/y.md": "y", "z.md": "z"}
+	for _, name := range []string{"a/x.txt", "b/x.txt", "c/d/y.md", "z.md"} {
+		fw, err := w.Create(name, &model.Object{Name: name, Size: int64(len(files[name])), Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	decompress := func(conflictPolicy string, innerPaths ...string) map[string]string {
+		ss, err := stream.NewSeekableStream(stream.FileStream{
+			Obj:    &model.Object{Name: "test.zip", Size: int64(buf.Len())},
+			Reader: bytesFile{Reader: bytes.NewReader(buf.Bytes())},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		err = Zip{}.Decompress([]*stream.SeekableStream{ss}, dir, model.ArchiveDecompressArgs{
+			InnerPaths:     innerPaths,
+			ConflictPolicy: conflictPolicy,
+		}, func(float64) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]string)
+		_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				content, _ := os.ReadFile(p)
+				rel, _ := filepath.Rel(dir, p)
+				got[filepath.ToSlash(rel)] = string(content)
+			}
+			return err
+		})
+		return got
+	}
+
+	got := decompress(model.ConflictRename, "*/x.txt", "/c")
+	want := map[string]string{"x.txt": "a", "x (1).txt": "b", "c/d/y.md": "y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decompressed %v, want %v", got, want)
+	}
+	got = decompress(model.ConflictSkip, "*/x.txt")
+	if want = map[string]string{"x.txt": "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("decompressed %v, want %v", got, want)
+	}
+	got = decompress(model.ConflictOverwrite, "*/x.txt", "c/d")
+	if want = map[string]string{"x.txt": "b", "d/y.md": "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("decompressed %v, want %v", got, want)
+	}
+}
+
+func TestDecompressOutside(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := Zip{}.NewWriter(&buf, ".zip", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw, err := w.Create("../../x.txt", &model.Object{Name: "x.txt", Size: 1, Modified: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fw.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj:    &model.Object{Name: "test.zip", Size: int64(buf.Len())},
+		Reader: bytesFile{Reader: bytes.NewReader(buf.Bytes())},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	err = Zip{}.Decompress([]*stream.SeekableStream{ss}, dir, model.ArchiveDecompressArgs{
+		ConflictPolicy: model.ConflictOverwrite,
+	}, func(float64) {})
+	if err == nil {
+		t.Error("the entry outside the output dir should be rejected")
+	}
+	if _, err = os.Stat(filepath.Join(root, "x.txt")); !os.IsNotExist(err) {
+		t.Errorf("the entry is decompressed outside the output dir: %v", err)
+	}
+}
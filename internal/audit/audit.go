@@ -0,0 +1,151 @@
+// Package audit records who did what in the audit log
+package audit
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ActionMkdir      = "fs.mkdir"
+	ActionPut        = "fs.put"
+	ActionMove       = "fs.move"
+	ActionRename     = "fs.rename"
+	ActionRemove     = "fs.remove"
+	ActionCopy       = "fs.copy"
+	ActionRestore    = "fs.restore"
+	ActionPurge      = "fs.purge"
+	ActionDecompress = "fs.decompress"
+	ActionCompress   = "fs.compress"
+	ActionTest       = "fs.archive_test"
+	ActionDownload   = "fs.download"
+
+	ActionLogin      = "auth.login"
+	ActionLogout     = "auth.logout"
+	ActionEnable2FA  = "auth.2fa.enable"
+	ActionDisable2FA = "auth.2fa.disable"
+
+	ActionSaveSettings  = "admin.setting.save"
+	ActionDeleteSetting = "admin.setting.delete"
+	ActionResetToken    = "admin.setting.reset_token"
+)
+
+// Log saves the log, the user, ip and device key missing from it are taken from the ctx
+func Log(ctx context.Context, l model.AuditLog, err error) {
+	if !setting.GetBool(conf.AuditEnabled) {
+		return
+	}
+	if l.Username == "" {
+		if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+			l.UserID, l.Username = user.ID, user.Username
+		}
+	}
+	if l.IP == "" {
+		l.IP = clientIP(ctx)
+	}
+	if l.DeviceKey == "" {
+		l.DeviceKey, _ = ctx.Value("device_key").(string)
+	}
+	l.Success = err == nil
+	if err != nil {
+		l.Error = err.Error()
+	}
+	l.Created = time.Now()
+	writerOnce.Do(func() { go write() })
+	select {
+	case pending <- l:
+	default:
+		log.Warnf("too many audit logs pending, dropped %s of %s", l.Action, l.Username)
+	}
+}
+
+const (
+	batchSize     = 100
+	flushInterval = time.Second
+)
+
+var (
+	// pending keeps the logs to be saved, they are saved in batches so the requests don't wait for the database
+	pending    = make(chan model.AuditLog, 10*batchSize)
+	writerOnce sync.Once
+	flushReq   = make(chan chan struct{})
+)
+
+func write() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	batch := make([]model.AuditLog, 0, batchSize)
+	save := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := op.CreateAuditLogs(batch); err != nil {
+			log.Errorf("failed create %d audit logs: %+v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case l := <-pending:
+			if batch = append(batch, l); len(batch) >= batchSize {
+				save()
+			}
+		case <-ticker.C:
+			save()
+		case done := <-flushReq:
+			for len(pending) > 0 {
+				batch = append(batch, <-pending)
+			}
+			save()
+			close(done)
+		}
+	}
+}
+
+// Flush saves the pending logs, it's called before the database is closed
+func Flush() {
+	writerOnce.Do(func() { go write() })
+	done := make(chan struct{})
+	flushReq <- done
+	<-done
+}
+
+// Fs logs an action on the filesystem
+func Fs(ctx context.Context, action, path, dstPath string, bytes int64, err error) {
+	Log(ctx, model.AuditLog{Action: action, Path: path, DstPath: dstPath, Bytes: bytes}, err)
+}
+
+func clientIP(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.ClientIP()
+	}
+	// set by the servers of the other protocols, it may contain the port
+	ip, _ := ctx.Value("client_ip").(string)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
+
+// PurgeExpired removes the logs older than the retention days
+func PurgeExpired() {
+	days := setting.GetInt(conf.AuditRetentionDays, 0)
+	if days <= 0 {
+		return
+	}
+	n, err := op.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed delete expired audit logs: %+v", err)
+	} else if n > 0 {
+		log.Infof("deleted %d expired audit logs", n)
+	}
+}
@@ -0,0 +1,14 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitAudit starts the job removing the audit logs older than the retention days
+func InitAudit() {
+	c := cron.NewCron(time.Hour)
+	c.Do(audit.PurgeExpired)
+}
@@ -147,6 +147,7 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AudioAutoplay, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		{Key: conf.VideoAutoplay, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		{Key: conf.PreviewArchivesByDefault, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
+		{Key: conf.ArchiveBrowseDepth, Value: "1", Type: conf.TypeNumber, Group: model.PREVIEW, Flag: model.PRIVATE},
 		{Key: conf.ReadMeAutoRender, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		{Key: conf.FilterReadMeScripts, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		// global settings
@@ -168,13 +169,20 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.MaxDevices, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL},
 		{Key: conf.DeviceEvictPolicy, Value: "deny", Type: conf.TypeSelect, Options: "deny,evict_oldest", Group: model.GLOBAL},
 		{Key: conf.DeviceSessionTTL, Value: "86400", Type: conf.TypeNumber, Group: model.GLOBAL},
+		{Key: conf.TrashAutoPurgeDays, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `objects in the trash are removed permanently after the days, 0 to keep them forever`},
+		{Key: conf.QuotaRecalculateHours, Value: "24", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `the usage of the users having a quota is recalculated every the hours, 0 to disable, takes effect after restart`},
+		{Key: conf.AuditEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `record the user actions in the audit log`},
+		{Key: conf.AuditRetentionDays, Value: "180", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `audit logs older than the days are removed, 0 to keep them forever`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
-		{Key: conf.SearchIndex, Value: "none", Type: conf.TypeSelect, Options: "database,database_non_full_text,bleve,meilisearch,none", Group: model.INDEX},
+		{Key: conf.SearchIndex, Value: "none", Type: conf.TypeSelect, Options: "database,database_non_full_text,database_fts,bleve,meilisearch,none", Group: model.INDEX, Help: `bleve can't be updated automatically or incrementally`},
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.SearchContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the content of the text, pdf and office files, only for bleve and meilisearch`},
+		{Key: conf.SearchContentMaxSize, Value: "1024", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max KB of the text indexed for a file`},
+		{Key: conf.SearchContentMaxFileSize, Value: "50", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max MB of the pdf and office files to extract the text from`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -230,6 +238,8 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.StreamMaxClientUploadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxServerDownloadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxServerUploadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
+		// one rule per line like "22:00-07:00 download=-1 upload=-1", overriding the max server speeds
+		{Key: conf.BandwidthSchedule, Value: "", Type: conf.TypeText, Group: model.TRAFFIC, Flag: model.PRIVATE},
 	}
 	initialSettingItems = append(initialSettingItems, tool.Tools.Items()...)
 	if flags.Dev {
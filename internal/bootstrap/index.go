@@ -1,7 +1,12 @@
 package bootstrap
 
 import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -11,8 +16,26 @@ func InitIndex() {
 		log.Errorf("init index error: %+v", err)
 		return
 	}
-	if !progress.IsDone {
+	// the incremental index is resumed by ResumeIndex
+	if !progress.IsDone && progress.Generation == 0 {
 		progress.IsDone = true
 		search.WriteProgress(progress)
 	}
 }
+
+// ResumeIndex resumes the incremental index stopped by the exit after the storages are loaded
+func ResumeIndex() {
+	progress, err := search.Progress()
+	if err != nil || progress.IsDone || progress.Generation == 0 {
+		return
+	}
+	go func() {
+		for !conf.StoragesLoaded {
+			time.Sleep(time.Second)
+		}
+		err := search.BuildIndexIncremental(context.Background(), []string{"/"}, setting.GetInt(conf.MaxIndexDepth, 20))
+		if err != nil {
+			log.Errorf("resume incremental index error: %+v", err)
+		}
+	}()
+}
@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitQuota starts the job fixing the usage which drifted from what is actually stored,
+// e.g. after the files are changed outside alist
+func InitQuota() {
+	hours := setting.GetInt(conf.QuotaRecalculateHours, 24)
+	if hours <= 0 {
+		return
+	}
+	c := cron.NewCron(time.Duration(hours) * time.Hour)
+	c.Do(func() {
+		fs.RecalculateQuotaUsages(context.Background())
+	})
+}
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/rss"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// InitRssFeeds schedules the enabled feeds having an interval
+func InitRssFeeds() {
+	feeds, err := op.GetEnabledRssFeeds()
+	if err != nil {
+		utils.Log.Errorf("failed get rss feeds: %+v", err)
+		return
+	}
+	for _, feed := range feeds {
+		rss.ScheduleFeed(feed)
+	}
+}
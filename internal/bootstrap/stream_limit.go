@@ -1,33 +1,19 @@
 package bootstrap
 
 import (
-	"context"
+	"sync"
+	"time"
+
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
 
-type blockBurstLimiter struct {
-	*rate.Limiter
-}
-
-func (l blockBurstLimiter) WaitN(ctx context.Context, total int) error {
-	for total > 0 {
-		n := l.Burst()
-		if l.Limiter.Limit() == rate.Inf || n > total {
-			n = total
-		}
-		err := l.Limiter.WaitN(ctx, n)
-		if err != nil {
-			return err
-		}
-		total -= n
-	}
-	return nil
-}
-
 func streamFilterNegative(limit int) (rate.Limit, int) {
 	if limit < 0 {
 		return rate.Inf, 0
@@ -35,19 +21,89 @@ func streamFilterNegative(limit int) (rate.Limit, int) {
 	return rate.Limit(limit) * 1024.0, limit * 1024
 }
 
-func initLimiter(limiter *stream.Limiter, s string) {
-	clientDownLimit, burst := streamFilterNegative(setting.GetInt(s, -1))
-	*limiter = blockBurstLimiter{Limiter: rate.NewLimiter(clientDownLimit, burst)}
+func initLimiter(limiter *stream.Limiter, name string, speed func() int) {
+	clientDownLimit, burst := streamFilterNegative(speed())
+	*limiter = stream.BlockBurstLimiter{Limiter: rate.NewLimiter(clientDownLimit, burst), Name: name}
 	op.RegisterSettingChangingCallback(func() {
-		newLimit, newBurst := streamFilterNegative(setting.GetInt(s, -1))
-		(*limiter).SetLimit(newLimit)
-		(*limiter).SetBurst(newBurst)
+		setLimiterSpeed(*limiter, speed())
 	})
 }
 
+func setLimiterSpeed(limiter stream.Limiter, speed int) {
+	newLimit, newBurst := streamFilterNegative(speed)
+	if limiter.Limit() != newLimit || limiter.Burst() != newBurst {
+		limiter.SetLimit(newLimit)
+		limiter.SetBurst(newBurst)
+	}
+}
+
+func settingSpeed(key string) func() int {
+	return func() int {
+		return setting.GetInt(key, -1)
+	}
+}
+
+// serverSpeeds returns the max server speeds in KB/s now, the bandwidth schedule overrides the settings
+func serverSpeeds() (download, upload int) {
+	download = setting.GetInt(conf.StreamMaxServerDownloadSpeed, -1)
+	upload = setting.GetInt(conf.StreamMaxServerUploadSpeed, -1)
+	rules, err := stream.ParseBandwidthSchedule(setting.GetStr(conf.BandwidthSchedule))
+	if err != nil {
+		log.Warnf("invalid bandwidth schedule: %+v", err)
+		return
+	}
+	return stream.ScheduledSpeed(rules, time.Now(), download, upload)
+}
+
+// toolSpeeds are the speeds set to the offline download tools, the tools are only set once there's a schedule,
+// so that the speeds set in the tools themselves are kept otherwise
+var toolSpeeds = struct {
+	sync.Mutex
+	m map[string][2]int
+}{m: make(map[string][2]int)}
+
+// applyToolSpeeds sets the server speeds to the offline download tools ready, if they are changed
+func applyToolSpeeds(download, upload int) {
+	toolSpeeds.Lock()
+	defer toolSpeeds.Unlock()
+	scheduled := setting.GetStr(conf.BandwidthSchedule) != ""
+	for name, t := range tool.Tools {
+		limiter, ok := t.(tool.SpeedLimitTool)
+		if !ok || !t.IsReady() {
+			continue
+		}
+		applied, ok := toolSpeeds.m[name]
+		if (!ok && !scheduled) || applied == [2]int{download, upload} {
+			continue
+		}
+		if err := limiter.SetSpeedLimit(download, upload); err != nil {
+			log.Warnf("failed set speed limit of tool %s: %+v", name, err)
+			continue
+		}
+		toolSpeeds.m[name] = [2]int{download, upload}
+	}
+}
+
 func InitStreamLimit() {
-	initLimiter(&stream.ClientDownloadLimit, conf.StreamMaxClientDownloadSpeed)
-	initLimiter(&stream.ClientUploadLimit, conf.StreamMaxClientUploadSpeed)
-	initLimiter(&stream.ServerDownloadLimit, conf.StreamMaxServerDownloadSpeed)
-	initLimiter(&stream.ServerUploadLimit, conf.StreamMaxServerUploadSpeed)
+	initLimiter(&stream.ClientDownloadLimit, "client_download", settingSpeed(conf.StreamMaxClientDownloadSpeed))
+	initLimiter(&stream.ClientUploadLimit, "client_upload", settingSpeed(conf.StreamMaxClientUploadSpeed))
+	initLimiter(&stream.ServerDownloadLimit, "server_download", func() int {
+		download, _ := serverSpeeds()
+		return download
+	})
+	initLimiter(&stream.ServerUploadLimit, "server_upload", func() int {
+		_, upload := serverSpeeds()
+		return upload
+	})
+	op.RegisterSettingChangingCallback(func() {
+		applyToolSpeeds(serverSpeeds())
+	})
+	// the speeds of the schedule change with the time of the day
+	c := cron.NewCron(time.Minute)
+	c.Do(func() {
+		download, upload := serverSpeeds()
+		setLimiterSpeed(stream.ServerDownloadLimit, download)
+		setLimiterSpeed(stream.ServerUploadLimit, upload)
+		applyToolSpeeds(download, upload)
+	})
 }
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// InitSyncJobs schedules the enabled sync jobs having an interval
+func InitSyncJobs() {
+	jobs, err := op.GetEnabledSyncJobs()
+	if err != nil {
+		utils.Log.Errorf("failed get sync jobs: %+v", err)
+		return
+	}
+	for _, job := range jobs {
+		fs.ScheduleSyncJob(job)
+	}
+}
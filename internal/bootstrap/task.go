@@ -4,9 +4,11 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/xhofe/tache"
 )
 
@@ -57,4 +59,26 @@ func InitTaskManager() {
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
 	})
+	fs.ArchiveCompressTaskManager = tache.NewManager[*fs.ArchiveCompressTask](tache.WithWorks(conf.Conf.Tasks.Compress.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant), db.UpdateTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Compress.MaxRetry))
+	fs.ArchiveTestTaskManager = tache.NewManager[*fs.ArchiveTestTask](tache.WithWorks(conf.Conf.Tasks.ArchiveTest.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("archive_test", conf.Conf.Tasks.ArchiveTest.TaskPersistant), db.UpdateTaskDataFunc("archive_test", conf.Conf.Tasks.ArchiveTest.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.ArchiveTest.MaxRetry))
+	fs.SyncTaskManager = tache.NewManager[*fs.SyncTask](tache.WithWorks(conf.Conf.Tasks.Sync.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant), db.UpdateTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Sync.MaxRetry))
+	fs.TrashTaskManager = tache.NewManager[*fs.TrashTask](tache.WithWorks(conf.Conf.Tasks.Trash.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("trash", conf.Conf.Tasks.Trash.TaskPersistant), db.UpdateTaskDataFunc("trash", conf.Conf.Tasks.Trash.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Trash.MaxRetry))
+	registerTaskManager("upload", fs.UploadTaskManager)
+	registerTaskManager("copy", fs.CopyTaskManager)
+	registerTaskManager("offline_download", tool.DownloadTaskManager)
+	registerTaskManager("offline_download_transfer", tool.TransferTaskManager)
+	registerTaskManager("s3_transition", fs.S3TransitionTaskManager)
+	registerTaskManager("decompress", fs.ArchiveDownloadTaskManager)
+	registerTaskManager("decompress_upload", fs.ArchiveContentUploadTaskManager)
+	registerTaskManager("compress", fs.ArchiveCompressTaskManager)
+	registerTaskManager("archive_test", fs.ArchiveTestTaskManager)
+	registerTaskManager("sync", fs.SyncTaskManager)
+	registerTaskManager("trash", fs.TrashTaskManager)
+	// the recovered queued downloads are kept errored until they are started
+	tool.StartQueuedDownloads()
+}
+
+func registerTaskManager[T task.TaskExtensionInfo](name string, manager task.Manager[T]) {
+	task.RegisterManager(name, manager)
+	metrics.RegisterTaskManager(name, manager)
 }
@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitTrash starts the job purging the objects which stayed in the trash for too long
+func InitTrash() {
+	c := cron.NewCron(time.Hour)
+	c.Do(func() {
+		fs.PurgeExpiredTrash(context.Background())
+	})
+}
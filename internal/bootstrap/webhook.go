@@ -0,0 +1,14 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitWebhook starts the job removing the old deliveries of the webhooks
+func InitWebhook() {
+	c := cron.NewCron(time.Hour)
+	c.Do(webhook.PurgeDeliveries)
+}
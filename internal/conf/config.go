@@ -60,7 +60,11 @@ type TasksConfig struct {
 	Copy               TaskConfig `json:"copy" envPrefix:"COPY_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Compress           TaskConfig `json:"compress" envPrefix:"COMPRESS_"`
+	ArchiveTest        TaskConfig `json:"archive_test" envPrefix:"ARCHIVE_TEST_"`
 	S3Transition       TaskConfig `json:"s3_transition" envPrefix:"S3_TRANSITION_"`
+	Sync               TaskConfig `json:"sync" envPrefix:"SYNC_"`
+	Trash              TaskConfig `json:"trash" envPrefix:"TRASH_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -94,6 +98,18 @@ type SFTP struct {
 	Listen string `json:"listen" env:"LISTEN"`
 }
 
+type WebDAV struct {
+	// LockSystem is where the LOCK tokens are kept, "memory" or "database"
+	LockSystem string `json:"lock_system" env:"LOCK_SYSTEM"`
+}
+
+type Metrics struct {
+	Enable bool `json:"enable" env:"ENABLE"`
+	// Listen serves the metrics on a separate address without auth,
+	// if empty they are served at /metrics of the main server for admins
+	Listen string `json:"listen" env:"LISTEN"`
+}
+
 type Config struct {
 	Force                 bool        `json:"force" env:"FORCE"`
 	SiteURL               string      `json:"site_url" env:"SITE_URL"`
@@ -116,6 +132,8 @@ type Config struct {
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
 	FTP                   FTP         `json:"ftp" envPrefix:"FTP_"`
 	SFTP                  SFTP        `json:"sftp" envPrefix:"SFTP_"`
+	WebDAV                WebDAV      `json:"webdav" envPrefix:"WEBDAV_"`
+	Metrics               Metrics     `json:"metrics" envPrefix:"METRICS_"`
 	LastLaunchedVersion   string      `json:"last_launched_version"`
 }
 
@@ -185,11 +203,31 @@ func DefaultConfig() *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Compress: TaskConfig{
+				Workers:  5,
+				MaxRetry: 2,
+				// TaskPersistant: true,
+			},
+			ArchiveTest: TaskConfig{
+				Workers: 2,
+				// the archive broken fails the same way again
+				MaxRetry: 0,
+				// TaskPersistant: true,
+			},
 			S3Transition: TaskConfig{
 				Workers:  5,
 				MaxRetry: 2,
 				// TaskPersistant: true,
 			},
+			Sync: TaskConfig{
+				Workers: 2,
+				// TaskPersistant: true,
+			},
+			Trash: TaskConfig{
+				Workers:  2,
+				MaxRetry: 2,
+				// TaskPersistant: true,
+			},
 			AllowRetryCanceled: false,
 		},
 		Cors: Cors{
@@ -218,6 +256,13 @@ func DefaultConfig() *Config {
 			Enable: false,
 			Listen: ":5222",
 		},
+		WebDAV: WebDAV{
+			LockSystem: "memory",
+		},
+		Metrics: Metrics{
+			Enable: false,
+			Listen: "",
+		},
 		LastLaunchedVersion: "",
 	}
 }
@@ -34,6 +34,7 @@ const (
 	AudioAutoplay            = "audio_autoplay"
 	VideoAutoplay            = "video_autoplay"
 	PreviewArchivesByDefault = "preview_archives_by_default"
+	ArchiveBrowseDepth       = "archive_browse_depth"
 	ReadMeAutoRender         = "readme_autorender"
 	FilterReadMeScripts      = "filter_readme_scripts"
 	// global
@@ -51,12 +52,19 @@ const (
 	MaxDevices              = "max_devices"
 	DeviceEvictPolicy       = "device_evict_policy"
 	DeviceSessionTTL        = "device_session_ttl"
+	TrashAutoPurgeDays      = "trash_auto_purge_days"
+	QuotaRecalculateHours   = "quota_recalculate_hours"
+	AuditEnabled            = "audit_enabled"
+	AuditRetentionDays      = "audit_retention_days"
 
 	// index
-	SearchIndex     = "search_index"
-	AutoUpdateIndex = "auto_update_index"
-	IgnorePaths     = "ignore_paths"
-	MaxIndexDepth   = "max_index_depth"
+	SearchIndex              = "search_index"
+	AutoUpdateIndex          = "auto_update_index"
+	IgnorePaths              = "ignore_paths"
+	MaxIndexDepth            = "max_index_depth"
+	SearchContent            = "search_content"
+	SearchContentMaxSize     = "search_content_max_size"
+	SearchContentMaxFileSize = "search_content_max_file_size"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -66,6 +74,9 @@ const (
 	TransmissionUri      = "transmission_uri"
 	TransmissionSeedtime = "transmission_seedtime"
 
+	// simple http
+	SimpleHttpConnections = "simple_http_connections"
+
 	// 115
 	Pan115TempDir = "115_temp_dir"
 
@@ -75,6 +86,12 @@ const (
 	// thunder
 	ThunderTempDir = "thunder_temp_dir"
 
+	// yt-dlp
+	YtDlpPath       = "ytdlp_path"
+	YtDlpFfmpegPath = "ytdlp_ffmpeg_path"
+	YtDlpFormat     = "ytdlp_format"
+	YtDlpArgs       = "ytdlp_args"
+
 	// single
 	Token         = "token"
 	IndexProgress = "index_progress"
@@ -135,6 +152,7 @@ const (
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
 	StreamMaxServerUploadSpeed            = "max_server_upload_speed"
+	BandwidthSchedule                     = "bandwidth_schedule"
 )
 
 const (
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateAuditLogs(ls []model.AuditLog) error {
+	return errors.WithStack(db.CreateInBatches(ls, 100).Error)
+}
+
+func filterAuditLogs(f model.AuditLogFilter) *gorm.DB {
+	auditDB := db.Model(&model.AuditLog{})
+	if f.Username != "" {
+		auditDB = auditDB.Where("username = ?", f.Username)
+	}
+	if f.Action != "" {
+		auditDB = auditDB.Where(columnName("action")+" = ?", f.Action)
+	}
+	if f.Path != "" {
+		path := strings.TrimSuffix(f.Path, "/")
+		auditDB = auditDB.Where(
+			db.Where(columnName("path")+" = ?", path).Or(columnName("path")+" LIKE ?", path+"/%").
+				Or("dst_path = ?", path).Or("dst_path LIKE ?", path+"/%"))
+	}
+	if f.IP != "" {
+		auditDB = auditDB.Where("ip = ?", f.IP)
+	}
+	if f.Success != nil {
+		auditDB = auditDB.Where("success = ?", *f.Success)
+	}
+	if f.Start > 0 {
+		auditDB = auditDB.Where("created >= ?", time.Unix(f.Start, 0))
+	}
+	if f.End > 0 {
+		auditDB = auditDB.Where("created < ?", time.Unix(f.End, 0))
+	}
+	return auditDB
+}
+
+func GetAuditLogs(f model.AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	auditDB := filterAuditLogs(f)
+	if err = auditDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = auditDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// WalkAuditLogs calls fn with the matched logs in batches ordered by id
+func WalkAuditLogs(f model.AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error {
+	var logs []model.AuditLog
+	return errors.WithStack(filterAuditLogs(f).FindInBatches(&logs, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(logs)
+	}).Error)
+}
+
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	res := db.Where("created < ?", t).Delete(&model.AuditLog{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
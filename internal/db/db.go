@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.Role), new(model.Label), new(model.LabelFileBinding), new(model.ObjFile), new(model.Session))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.Role), new(model.Label), new(model.LabelFileBinding), new(model.ObjFile), new(model.Session), new(model.WebDAVLock), new(model.Share), new(model.TrashItem), new(model.Webhook), new(model.WebhookDelivery), new(model.QuotaUsage), new(model.AuditLog), new(model.SyncJob), new(model.ScheduledJob), new(model.IndexDir), new(model.RssFeed), new(model.RssItem))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetIndexDir returns nil if the dir isn't indexed
+func GetIndexDir(path string) (*model.IndexDir, error) {
+	var dir model.IndexDir
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).First(&dir).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed get index dir")
+	}
+	return &dir, nil
+}
+
+func SaveIndexDir(dir *model.IndexDir) error {
+	return errors.WithStack(db.Save(dir).Error)
+}
+
+func SetIndexDirDone(path string, done bool) error {
+	return errors.WithStack(db.Model(&model.IndexDir{}).
+		Where(fmt.Sprintf("%s = ?", columnName("path")), path).Update("done", done).Error)
+}
+
+// UndoneIndexDirs marks the dirs not done, so that the incremental index doesn't skip them
+func UndoneIndexDirs(paths []string) error {
+	return errors.WithStack(db.Model(&model.IndexDir{}).
+		Where(fmt.Sprintf("%s IN ?", columnName("path")), paths).Update("done", false).Error)
+}
+
+// DeleteIndexDirs deletes the dir and the dirs in it
+func DeleteIndexDirs(path string) error {
+	if path == "/" {
+		return ClearIndexDirs()
+	}
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ? OR %s LIKE ?", columnName("path"), columnName("path")),
+		path, fmt.Sprintf("%s/%%", path)).Delete(&model.IndexDir{}).Error)
+}
+
+func ClearIndexDirs() error {
+	return errors.WithStack(db.Where("1 = 1").Delete(&model.IndexDir{}).Error)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetQuotaUsage returns the usage of the user, an empty usage if nothing is recorded yet
+func GetQuotaUsage(userId uint) (*model.QuotaUsage, error) {
+	usage := model.QuotaUsage{UserID: userId}
+	if err := db.Where("user_id = ?", userId).First(&usage).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(err, "failed get quota usage")
+	}
+	return &usage, nil
+}
+
+func SaveQuotaUsage(usage *model.QuotaUsage) error {
+	return errors.WithStack(db.Save(usage).Error)
+}
+
+// IncreaseQuotaUsage adds the bytes and files to the usage of the user, they may be negative
+func IncreaseQuotaUsage(userId uint, bytes, files int64) error {
+	usage := model.QuotaUsage{UserID: userId, UsedBytes: bytes, UsedFiles: files, Updated: time.Now()}
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"used_bytes": gorm.Expr(columnName("used_bytes")+" + ?", bytes),
+			"used_files": gorm.Expr(columnName("used_files")+" + ?", files),
+			"updated":    usage.Updated,
+		}),
+	}).Create(&usage).Error)
+}
+
+func DeleteQuotaUsage(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.QuotaUsage{}).Error)
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetRssFeedById(id uint) (*model.RssFeed, error) {
+	var f model.RssFeed
+	if err := db.First(&f, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get rss feed")
+	}
+	return &f, nil
+}
+
+// GetRssFeeds returns the feeds of the user, all feeds if the user id is 0
+func GetRssFeeds(userID uint, pageIndex, pageSize int) (feeds []model.RssFeed, count int64, err error) {
+	feedDB := db.Model(&model.RssFeed{})
+	if userID != 0 {
+		feedDB = feedDB.Where(columnName("user_id")+" = ?", userID)
+	}
+	if err = feedDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get rss feeds count")
+	}
+	if err = feedDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&feeds).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find rss feeds")
+	}
+	return feeds, count, nil
+}
+
+func GetEnabledRssFeeds() (feeds []model.RssFeed, err error) {
+	if err = db.Where(columnName("disabled")+" = ?", false).Find(&feeds).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find rss feeds")
+	}
+	return feeds, nil
+}
+
+func CreateRssFeed(f *model.RssFeed) error {
+	return errors.WithStack(db.Create(f).Error)
+}
+
+// UpdateRssFeed saves the definition of the feed, the owner and the result of the last poll are kept
+func UpdateRssFeed(f *model.RssFeed) error {
+	return errors.WithStack(db.Model(f).
+		Select("name", "url", "interval", "include", "exclude", "dst_path", "tool", "delete_policy", "disabled").
+		Updates(f).Error)
+}
+
+func UpdateRssFeedResult(id uint, lastCheck time.Time, result string) error {
+	return errors.WithStack(db.Model(&model.RssFeed{ID: id}).
+		Updates(map[string]interface{}{"last_check": lastCheck, "last_result": result}).Error)
+}
+
+// DeleteRssFeedById deletes the feed with its items
+func DeleteRssFeedById(id uint) error {
+	if err := db.Where(columnName("feed_id")+" = ?", id).Delete(&model.RssItem{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.RssFeed{}, id).Error)
+}
+
+// GetRssItemByGUID returns nil if the item isn't found
+func GetRssItemByGUID(feedID uint, guid string) (*model.RssItem, error) {
+	var items []model.RssItem
+	err := db.Where(columnName("feed_id")+" = ? AND "+columnName("guid_hash")+" = ?", feedID, model.RssGUIDHash(guid)).Limit(1).Find(&items).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get rss item")
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return &items[0], nil
+}
+
+func SaveRssItem(item *model.RssItem) error {
+	return errors.WithStack(db.Save(item).Error)
+}
+
+func GetRssItems(feedID uint, pageIndex, pageSize int) (items []model.RssItem, count int64, err error) {
+	itemDB := db.Model(&model.RssItem{}).Where(columnName("feed_id")+" = ?", feedID)
+	if err = itemDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get rss items count")
+	}
+	if err = itemDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find rss items")
+	}
+	return items, count, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetScheduledJobById(id uint) (*model.ScheduledJob, error) {
+	var j model.ScheduledJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get scheduled job")
+	}
+	return &j, nil
+}
+
+func GetScheduledJobs(pageIndex, pageSize int) (jobs []model.ScheduledJob, count int64, err error) {
+	jobDB := db.Model(&model.ScheduledJob{})
+	if err = jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scheduled jobs count")
+	}
+	if err = jobDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scheduled jobs")
+	}
+	return jobs, count, nil
+}
+
+func GetEnabledScheduledJobs() (jobs []model.ScheduledJob, err error) {
+	if err = db.Where(columnName("disabled")+" = ?", false).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find scheduled jobs")
+	}
+	return jobs, nil
+}
+
+func CreateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+// UpdateScheduledJob saves the definition of the job, the run info is kept
+func UpdateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Model(j).Select("name", "cron", "type", "args", "disabled").Updates(j).Error)
+}
+
+func UpdateScheduledJobNextRun(id uint, nextRun *time.Time) error {
+	return errors.WithStack(db.Model(&model.ScheduledJob{ID: id}).Update("next_run", nextRun).Error)
+}
+
+func UpdateScheduledJobResult(id uint, lastRun time.Time, success bool, result string) error {
+	return errors.WithStack(db.Model(&model.ScheduledJob{ID: id}).Updates(map[string]interface{}{
+		"last_run":     lastRun,
+		"last_success": success,
+		"last_result":  result,
+	}).Error)
+}
+
+func DeleteScheduledJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ScheduledJob{}, id).Error)
+}
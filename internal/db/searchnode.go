@@ -4,6 +4,7 @@ import (
 	"fmt"
 	stdpath "path"
 	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -55,37 +56,157 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 }
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
-	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	// sqlite has no regexp, the names are matched after the other filters
+	matchInMemory := req.Match != model.MatchKeywords && conf.Conf.Database.Type == "sqlite3"
+	if req.Access != nil && req.Access.Hidden != nil {
+		matchInMemory = true
+	}
+	switch {
+	case req.Match != model.MatchKeywords:
+		switch conf.Conf.Database.Type {
+		case "mysql":
+			searchDB = searchDB.Where(fmt.Sprintf("%s REGEXP ?", columnName("name")), req.NamePattern())
+		case "postgres":
+			searchDB = searchDB.Where(fmt.Sprintf("%s ~ ?", columnName("name")), req.NamePattern())
+		}
+	case !useFullText || conf.Conf.Database.Type == "sqlite3":
 		keywordsClause := db.Where("1 = 1")
 		for _, keyword := range strings.Fields(req.Keywords) {
 			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
 		}
-		searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
-	} else {
+		searchDB = searchDB.Where(keywordsClause)
+	default:
 		switch conf.Conf.Database.Type {
 		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			searchDB = searchDB.Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
 		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			searchDB = searchDB.Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
 		}
 	}
+	searchDB = whereSearchFilters(searchDB, req)
+	searchDB = searchDB.Order(searchOrder(req))
 
-	if req.Scope != 0 {
-		isDir := req.Scope == 1
-		searchDB.Where(db.Where("is_dir = ?", isDir))
+	if matchInMemory {
+		match, err := req.NameMatcher()
+		if err != nil {
+			return nil, 0, err
+		}
+		return searchNodeInMemory(searchDB, req, match)
 	}
-
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+func whereSearchFilters(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	if req.Scope != 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s = ?", columnName("is_dir")), req.Scope == 1)
+	}
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if req.ModifiedAfter > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("modified")), time.Unix(req.ModifiedAfter, 0))
+	}
+	if req.ModifiedBefore > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("modified")), time.Unix(req.ModifiedBefore, 0))
+	}
+	if len(req.Exts) > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s IN ?", columnName("ext")), req.Exts)
+	}
+	if req.Hash != "" {
+		searchDB = searchDB.Where(fmt.Sprintf("%s LIKE ?", columnName("hash")), fmt.Sprintf("%%%s%%", req.Hash))
+	}
+	return whereSearchAccess(searchDB, req.Access)
+}
+
+// whereSearchAccess filters the nodes out of the roots and in the denied dirs, the hidden ones are checked in memory
+func whereSearchAccess(searchDB *gorm.DB, access *model.SearchAccess) *gorm.DB {
+	if access == nil {
+		return searchDB
+	}
+	if access.Roots != nil {
+		clauses := []string{"1 = 0"}
+		var args []interface{}
+		for _, root := range access.Roots {
+			clause, clauseArgs := inTreeClause(root)
+			dir, name := stdpath.Split(root)
+			clauses = append(clauses, clause, fmt.Sprintf("(%s = ? AND %s = ?)", columnName("parent"), columnName("name")))
+			args = append(append(args, clauseArgs...), utils.FixAndCleanPath(dir), name)
+		}
+		searchDB = searchDB.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+	for _, denied := range access.Denied {
+		if !denied.Sub {
+			searchDB = searchDB.Where(fmt.Sprintf("%s <> ?", columnName("parent")), denied.Path)
+			continue
+		}
+		clause, args := inTreeClause(denied.Path)
+		for _, except := range denied.Except {
+			exceptClause, exceptArgs := inTreeClause(except)
+			clause += " AND NOT " + exceptClause
+			args = append(args, exceptArgs...)
+		}
+		searchDB = searchDB.Where("NOT ("+clause+")", args...)
+	}
+	return searchDB
+}
+
+// inTreeClause matches the nodes whose parent is the path or under it
+func inTreeClause(path string) (string, []interface{}) {
+	if path == "/" {
+		return "1 = 1", nil
+	}
+	return fmt.Sprintf("(%s = ? OR %s LIKE ?)", columnName("parent"), columnName("parent")),
+		[]interface{}{path, path + "/%"}
+}
+
+func searchOrder(req model.SearchReq) string {
+	orderBy, direction := "name", "asc"
+	if req.OrderBy != "" {
+		orderBy = req.OrderBy
+	}
+	if req.OrderDirection != "" {
+		direction = req.OrderDirection
+	}
+	return columnName(orderBy) + " " + direction
+}
+
+// searchNodeInMemory matches the names and checks the hide rules of the nodes filtered by the query
+func searchNodeInMemory(searchDB *gorm.DB, req model.SearchReq, match func(name string) bool) ([]model.SearchNode, int64, error) {
+	rows, err := searchDB.Rows()
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var (
+		count int64
+		files []model.SearchNode
+		from  = int64((req.Page - 1) * req.PerPage)
+	)
+	for rows.Next() {
+		var node model.SearchNode
+		if err = db.ScanRows(rows, &node); err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		if !match(node.Name) || !req.Access.Allowed(&node) {
+			continue
+		}
+		if count >= from && len(files) < req.PerPage {
+			files = append(files, node)
+		}
+		count++
+	}
+	return files, count, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// the tokens of the search nodes are kept in a fts5 virtual table for sqlite, or a table with a gin index for postgres,
+// the columns are prefixed by node_ so that they aren't ambiguous in the join with the search nodes
+
+func ftsTable() string {
+	return conf.Conf.Database.TablePrefix + "search_node_fts"
+}
+
+// InitSearchNodeFts creates the fts table if it doesn't exist
+func InitSearchNodeFts() error {
+	table := ftsTable()
+	switch conf.Conf.Database.Type {
+	case "sqlite3":
+		err := db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5("+
+			"node_parent UNINDEXED, node_name UNINDEXED, tokens, tokenize = 'unicode61')", table)).Error
+		if err != nil && strings.Contains(err.Error(), "no such module") {
+			return errors.New("sqlite is built without fts5, build with the sqlite_fts5 tag")
+		}
+		return errors.WithStack(err)
+	case "postgres":
+		stmts := []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (node_parent text NOT NULL, node_name text NOT NULL, tokens text NOT NULL, `+
+				`tokens_tsv tsvector GENERATED ALWAYS AS (to_tsvector('simple', tokens)) STORED)`, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_tsv ON %s USING GIN (tokens_tsv)", table, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_parent ON %s (node_parent)", table, table),
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("full text search table isn't supported for %s", conf.Conf.Database.Type)
+	}
+}
+
+// BatchCreateSearchNodesFts creates the nodes and their tokens, the tokens are joined by spaces
+func BatchCreateSearchNodesFts(nodes []model.SearchNode, tokens []string) error {
+	rows := make([]map[string]interface{}, len(nodes))
+	for i := range nodes {
+		rows[i] = map[string]interface{}{
+			"node_parent": nodes[i].Parent,
+			"node_name":   nodes[i].Name,
+			"tokens":      tokens[i],
+		}
+	}
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&nodes, 1000).Error; err != nil {
+			return err
+		}
+		return tx.Table(ftsTable()).CreateInBatches(&rows, 1000).Error
+	}))
+}
+
+func DeleteSearchNodesFtsByParent(path string) error {
+	path = utils.FixAndCleanPath(path)
+	err := DeleteSearchNodesByParent(path)
+	if err != nil {
+		return err
+	}
+	fts := db.Table(ftsTable())
+	if path != "/" {
+		fts = fts.Where("node_parent LIKE ? OR node_parent = ?", fmt.Sprintf("%s/%%", path), path)
+	} else {
+		fts = fts.Where("1 = 1")
+	}
+	if err = fts.Delete(map[string]interface{}{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	dir, name := stdpath.Dir(path), stdpath.Base(path)
+	return errors.WithStack(db.Table(ftsTable()).Where("node_parent = ? AND node_name = ?", dir, name).Delete(map[string]interface{}{}).Error)
+}
+
+func ClearSearchNodesFts() error {
+	if err := ClearSearchNodes(); err != nil {
+		return err
+	}
+	return errors.WithStack(db.Table(ftsTable()).Where("1 = 1").Delete(map[string]interface{}{}).Error)
+}
+
+// SearchNodeFts searches the nodes having all the tokens, the last token of each word is matched as a prefix,
+// they are ordered by the rank unless the order is given in the req
+func SearchNodeFts(req model.SearchReq, tokens []string) ([]model.SearchNode, int64, error) {
+	table := ftsTable()
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
+		Joins(fmt.Sprintf("JOIN %s ON %s.node_parent = %s AND %s.node_name = %s",
+			table, table, columnName("parent"), table, columnName("name")))
+	var rank string
+	switch conf.Conf.Database.Type {
+	case "sqlite3":
+		terms := make([]string, len(tokens))
+		for i, token := range tokens {
+			terms[i] = `"` + strings.ReplaceAll(token, `"`, `""`) + `"*`
+		}
+		searchDB = searchDB.Where(fmt.Sprintf("%s MATCH ?", table), strings.Join(terms, " AND "))
+		rank = fmt.Sprintf("bm25(%s)", table)
+	case "postgres":
+		terms := make([]string, len(tokens))
+		for i, token := range tokens {
+			terms[i] = "'" + strings.ReplaceAll(token, "'", "''") + "':*"
+		}
+		query := strings.Join(terms, " & ")
+		searchDB = searchDB.Where(fmt.Sprintf("%s.tokens_tsv @@ to_tsquery('simple', ?)", table), query)
+		rank = fmt.Sprintf("ts_rank(%s.tokens_tsv, to_tsquery('simple', '%s')) DESC", table, strings.ReplaceAll(query, "'", "''"))
+	default:
+		return nil, 0, errors.Errorf("full text search isn't supported for %s", conf.Conf.Database.Type)
+	}
+	searchDB = whereSearchFilters(searchDB, req)
+	order := rank
+	if req.OrderBy != "" {
+		order = searchOrder(req)
+	}
+	selected := fmt.Sprintf("%s.*", db.NamingStrategy.TableName("SearchNode"))
+	if req.Access != nil && req.Access.Hidden != nil {
+		return searchNodeInMemory(searchDB.Order(order).Select(selected), req, func(string) bool { return true })
+	}
+	var count int64
+	if err := searchDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get search items count")
+	}
+	var files []model.SearchNode
+	if err := searchDB.Order(order).Select(selected).
+		Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).Find(&files).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return files, count, nil
+}
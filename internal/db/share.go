@@ -0,0 +1,57 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func GetShares(pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	return getShares(db.Model(&model.Share{}), pageIndex, pageSize)
+}
+
+func GetSharesByUserId(userId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	return getShares(db.Model(&model.Share{}).Where("user_id = ?", userId), pageIndex, pageSize)
+}
+
+func getShares(shareDB *gorm.DB, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	if err := shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err := shareDB.Order(columnName("created") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+// UpdateShare updates the settings of a share, the download count is left untouched
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Model(s).Select("pwd_hash", "salt", "has_password", "expires", "max_downloads", "disabled", "remark").Updates(s).Error)
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.Share{}).Error)
+}
+
+// IncreaseShareDownloads counts a download, it returns false if the download limit is reached
+func IncreaseShareDownloads(id string) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where("id = ? AND (max_downloads = 0 OR downloads < max_downloads)", id).
+		Update("downloads", gorm.Expr("downloads + ?", 1))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
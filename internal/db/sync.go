@@ -0,0 +1,62 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	var j model.SyncJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sync job")
+	}
+	return &j, nil
+}
+
+func GetSyncJobs(pageIndex, pageSize int) (jobs []model.SyncJob, count int64, err error) {
+	jobDB := db.Model(&model.SyncJob{})
+	if err = jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get sync jobs count")
+	}
+	if err = jobDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find sync jobs")
+	}
+	return jobs, count, nil
+}
+
+func GetEnabledSyncJobs() (jobs []model.SyncJob, err error) {
+	if err = db.Where(columnName("disabled")+" = ?", false).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sync jobs")
+	}
+	return jobs, nil
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+// UpdateSyncJob saves the definition of the job, the result of the last run is kept
+func UpdateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Model(j).
+		Select("name", "src_path", "dst_path", "mode", "propagate_delete", "interval", "disabled").
+		Updates(j).Error)
+}
+
+// UpdateSyncJobResult saves the result of a run, the state is only saved if it's not nil
+func UpdateSyncJobResult(id uint, lastRun time.Time, result string, state *string) error {
+	values := map[string]interface{}{"last_run": lastRun, "last_result": result}
+	if state != nil {
+		values["state"] = *state
+	}
+	return errors.WithStack(db.Model(&model.SyncJob{ID: id}).Updates(values).Error)
+}
+
+func ResetSyncJobState(id uint) error {
+	return errors.WithStack(db.Model(&model.SyncJob{ID: id}).Update("state", "").Error)
+}
+
+func DeleteSyncJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.SyncJob{}, id).Error)
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	var item model.TrashItem
+	if err := db.First(&item, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get trash item")
+	}
+	return &item, nil
+}
+
+func GetTrashItems(pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	return getTrashItems(db.Model(&model.TrashItem{}), pageIndex, pageSize)
+}
+
+func GetTrashItemsByUserId(userId uint, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	return getTrashItems(db.Model(&model.TrashItem{}).Where("user_id = ?", userId), pageIndex, pageSize)
+}
+
+func getTrashItems(trashDB *gorm.DB, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	if err := trashDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get trash items count")
+	}
+	if err := trashDB.Order(columnName("deleted") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find trash items")
+	}
+	return items, count, nil
+}
+
+func GetTrashItemsDeletedBefore(t time.Time) (items []model.TrashItem, err error) {
+	if err := db.Where(columnName("deleted")+" < ?", t).Find(&items).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find trash items")
+	}
+	return items, nil
+}
+
+func CreateTrashItem(item *model.TrashItem) error {
+	return errors.WithStack(db.Create(item).Error)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrashItem{}, id).Error)
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webDAVLockGuard is the token of the row locked by WebDAVLockTransaction, it is never a lock
+const webDAVLockGuard = "guard"
+
+// WebDAVLockTransaction runs fn in a transaction holding the row lock of the guard row,
+// so the changes of the locks made by fn are never interleaved with the ones of other instances
+func WebDAVLockTransaction(fn func(tx *gorm.DB) error) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		guard := model.WebDAVLock{Token: webDAVLockGuard}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&guard).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", webDAVLockGuard).First(&guard).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	}))
+}
+
+func GetWebDAVLocks(tx *gorm.DB) ([]model.WebDAVLock, error) {
+	var locks []model.WebDAVLock
+	if err := tx.Where("token <> ?", webDAVLockGuard).Find(&locks).Error; err != nil {
+		return nil, errors.Wrap(err, "failed find webdav locks")
+	}
+	return locks, nil
+}
+
+func SaveWebDAVLock(tx *gorm.DB, l *model.WebDAVLock) error {
+	return errors.WithStack(tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(l).Error)
+}
+
+func DeleteWebDAVLocks(tx *gorm.DB, tokens ...string) error {
+	return errors.WithStack(tx.Where("token IN ?", tokens).Delete(&model.WebDAVLock{}).Error)
+}
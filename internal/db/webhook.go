@@ -0,0 +1,92 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := db.First(&w, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webhook")
+	}
+	return &w, nil
+}
+
+func GetWebhooks(pageIndex, pageSize int) (webhooks []model.Webhook, count int64, err error) {
+	webhookDB := db.Model(&model.Webhook{})
+	if err = webhookDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhooks count")
+	}
+	if err = webhookDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&webhooks).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhooks")
+	}
+	return webhooks, count, nil
+}
+
+func GetEnabledWebhooks() (webhooks []model.Webhook, err error) {
+	if err = db.Where(columnName("disabled")+" = ?", false).Find(&webhooks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webhooks")
+	}
+	return webhooks, nil
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Create(w).Error)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Save(w).Error)
+}
+
+func DeleteWebhookById(id uint) error {
+	if err := db.Where("webhook_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.Webhook{}, id).Error)
+}
+
+func GetWebhookDeliveries(webhookId uint, pageIndex, pageSize int) (deliveries []model.WebhookDelivery, count int64, err error) {
+	deliveryDB := db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", webhookId)
+	if err = deliveryDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhook deliveries count")
+	}
+	if err = deliveryDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhook deliveries")
+	}
+	return deliveries, count, nil
+}
+
+// DeleteWebhookDeliveriesBeyond keeps only the latest deliveries of each webhook
+func DeleteWebhookDeliveriesBeyond(keep int) (int64, error) {
+	var webhookIds []uint
+	if err := db.Model(&model.WebhookDelivery{}).Distinct("webhook_id").Pluck("webhook_id", &webhookIds).Error; err != nil {
+		return 0, errors.Wrapf(err, "failed find webhooks of deliveries")
+	}
+	var n int64
+	for _, id := range webhookIds {
+		var last []uint
+		err := db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", id).Order(columnName("id")+" DESC").
+			Offset(keep).Limit(1).Pluck(columnName("id"), &last).Error
+		if err != nil {
+			return n, errors.Wrapf(err, "failed find webhook deliveries")
+		}
+		if len(last) == 0 {
+			continue
+		}
+		res := db.Where("webhook_id = ? AND "+columnName("id")+" <= ?", id, last[0]).Delete(&model.WebhookDelivery{})
+		if res.Error != nil {
+			return n, errors.WithStack(res.Error)
+		}
+		n += res.RowsAffected
+	}
+	return n, nil
+}
+
+func CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func UpdateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Save(d).Error)
+}
@@ -22,6 +22,7 @@ var (
 
 	UnknownArchiveFormat      = errors.New("unknown archive format")
 	WrongArchivePassword      = errors.New("wrong archive password")
+	ArchiveVolumeMissing      = errors.New("archive volume missing")
 	DriverExtractNotSupported = errors.New("driver extraction not supported")
 )
 
@@ -0,0 +1,5 @@
+package errs
+
+import "errors"
+
+var QuotaExceeded = errors.New("storage quota exceeded")
@@ -0,0 +1,11 @@
+package errs
+
+import "errors"
+
+var (
+	ShareNotFound             = errors.New("share not found")
+	ShareExpired              = errors.New("share is expired")
+	ShareDisabled             = errors.New("share is disabled")
+	ShareDownloadLimitReached = errors.New("download limit of the share is reached")
+	WrongSharePassword        = errors.New("share password is incorrect")
+)
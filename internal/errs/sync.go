@@ -0,0 +1,5 @@
+package errs
+
+import "errors"
+
+var SyncJobRunning = errors.New("the sync job is running already")
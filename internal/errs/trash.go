@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	TrashItemNotFound   = errors.New("trash item not found")
+	RestoreTargetExists = errors.New("an object with the same name already exists at the original path")
+)
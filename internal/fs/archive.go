@@ -22,6 +22,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
@@ -40,8 +41,12 @@ type ArchiveDownloadTask struct {
 }
 
 func (t *ArchiveDownloadTask) GetName() string {
+	innerPath := t.InnerPath
+	if len(t.InnerPaths) > 0 {
+		innerPath = strings.Join(t.InnerPaths, ", ")
+	}
 	return fmt.Sprintf("decompress [%s](%s)[%s] to [%s](%s) with password <%s>", t.SrcStorageMp, t.SrcObjPath,
-		t.InnerPath, t.DstStorageMp, t.DstDirPath, t.Password)
+		innerPath, t.DstStorageMp, t.DstDirPath, t.Password)
 }
 
 func (t *ArchiveDownloadTask) GetStatus() string {
@@ -110,7 +115,7 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 	if err != nil {
 		return nil, err
 	}
-	err = tool.Decompress(ss, dir, t.ArchiveInnerArgs, decompressUp)
+	err = tool.Decompress(ss, dir, t.ArchiveDecompressArgs, decompressUp)
 	if err != nil {
 		return nil, err
 	}
@@ -119,12 +124,13 @@ func (t *ArchiveDownloadTask) RunWithoutPushUploadTask() (*ArchiveContentUploadT
 		TaskExtension: task.TaskExtension{
 			Creator: t.GetCreator(),
 		},
-		ObjName:      baseName,
-		InPlace:      !t.PutIntoNewDir,
-		FilePath:     dir,
-		DstDirPath:   t.DstDirPath,
-		dstStorage:   t.dstStorage,
-		DstStorageMp: t.DstStorageMp,
+		ObjName:        baseName,
+		InPlace:        !t.PutIntoNewDir,
+		FilePath:       dir,
+		DstDirPath:     t.DstDirPath,
+		dstStorage:     t.dstStorage,
+		DstStorageMp:   t.DstStorageMp,
+		ConflictPolicy: t.ConflictPolicy,
 	}
 	return uploadTask, nil
 }
@@ -133,14 +139,15 @@ var ArchiveDownloadTaskManager *tache.Manager[*ArchiveDownloadTask]
 
 type ArchiveContentUploadTask struct {
 	task.TaskExtension
-	status       string
-	ObjName      string
-	InPlace      bool
-	FilePath     string
-	DstDirPath   string
-	dstStorage   driver.Driver
-	DstStorageMp string
-	finalized    bool
+	status         string
+	ObjName        string
+	InPlace        bool
+	FilePath       string
+	DstDirPath     string
+	dstStorage     driver.Driver
+	DstStorageMp   string
+	ConflictPolicy string
+	finalized      bool
 }
 
 func (t *ArchiveContentUploadTask) GetName() string {
@@ -201,12 +208,13 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTsk *Archi
 				TaskExtension: task.TaskExtension{
 					Creator: t.GetCreator(),
 				},
-				ObjName:      entry.Name(),
-				InPlace:      false,
-				FilePath:     nextFilePath,
-				DstDirPath:   nextDstPath,
-				dstStorage:   t.dstStorage,
-				DstStorageMp: t.DstStorageMp,
+				ObjName:        entry.Name(),
+				InPlace:        false,
+				FilePath:       nextFilePath,
+				DstDirPath:     nextDstPath,
+				dstStorage:     t.dstStorage,
+				DstStorageMp:   t.DstStorageMp,
+				ConflictPolicy: t.ConflictPolicy,
 			})
 			if err != nil {
 				es = stderrors.Join(es, err)
@@ -216,6 +224,15 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTsk *Archi
 			return es
 		}
 	} else {
+		skip, err := t.resolveConflict()
+		if err != nil {
+			return err
+		}
+		if skip {
+			t.status = "skipped, the file exists"
+			t.deleteSrcFile()
+			return nil
+		}
 		t.SetTotalBytes(info.Size())
 		file, err := os.Open(t.FilePath)
 		if err != nil {
@@ -242,6 +259,41 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTsk *Archi
 	return nil
 }
 
+// resolveConflict checks the file existing in the dst dir by the conflict policy, the file is renamed
+// to a name not existing for the rename policy
+func (t *ArchiveContentUploadTask) resolveConflict() (bool, error) {
+	if t.ConflictPolicy != model.ConflictSkip && t.ConflictPolicy != model.ConflictRename {
+		return false, nil
+	}
+	exists := func(name string) (bool, error) {
+		_, err := op.Get(t.Ctx(), t.dstStorage, stdpath.Join(t.DstDirPath, name))
+		if err == nil {
+			return true, nil
+		}
+		if errs.IsObjectNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	ok, err := exists(t.ObjName)
+	if err != nil || !ok {
+		return false, err
+	}
+	if t.ConflictPolicy == model.ConflictSkip {
+		return true, nil
+	}
+	for i := 1; ; i++ {
+		name := utils.NumberedName(t.ObjName, i)
+		if ok, err = exists(name); err != nil {
+			return false, err
+		}
+		if !ok {
+			t.ObjName = name
+			return false, nil
+		}
+	}
+}
+
 func (t *ArchiveContentUploadTask) Cancel() {
 	t.TaskExtension.Cancel()
 	if !conf.Conf.Tasks.AllowRetryCanceled {
@@ -345,7 +397,15 @@ func archiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
-	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+	for _, p := range args.InnerPaths {
+		if _, err = stdpath.Match(p, ""); err != nil {
+			return nil, errors.WithMessagef(err, "invalid inner path [%s]", p)
+		}
+	}
+	// the drivers decompress a single inner path and overwrite the files existing
+	driverDecompress := len(args.InnerPaths) == 0 &&
+		(args.ConflictPolicy == "" || args.ConflictPolicy == model.ConflictOverwrite)
+	if driverDecompress && srcStorage.GetStorage() == dstStorage.GetStorage() {
 		err = op.ArchiveDecompress(ctx, srcStorage, srcObjActualPath, dstDirActualPath, args, lazyCache...)
 		if !errors.Is(err, errs.NotImplement) {
 			return nil, err
@@ -0,0 +1,304 @@
+package fs
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"os"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ArchiveObj is an obj in the archive browsed as a read-only dir, the archive itself is an ArchiveObj dir
+// when browsed
+type ArchiveObj struct {
+	model.Obj
+}
+
+// InArchive reports whether the obj is got by ArchiveBrowseGet or ArchiveBrowseList
+func InArchive(obj model.Obj) bool {
+	_, ok := obj.(*ArchiveObj)
+	return ok
+}
+
+// archiveChain is a path going into the archives, /a.zip/dir/b.tar/c.txt is the archive /a.zip and
+// the inner paths [/dir/b.tar /c.txt], the inner paths but the last are the archives nested
+type archiveChain struct {
+	storage    driver.Driver
+	actualPath string
+	archive    model.Obj
+	innerPaths []string
+}
+
+func isArchive(name string) bool {
+	_, _, err := op.GetArchiveTool(name)
+	return err == nil
+}
+
+// resolveArchiveChain finds the archive the path goes into, the path of an archive is its root dir.
+// errs.ObjectNotFound is returned if the path doesn't go into an archive or goes deeper than archive_browse_depth
+func resolveArchiveChain(ctx context.Context, path string) (*archiveChain, error) {
+	path = utils.FixAndCleanPath(path)
+	archivePath := path
+	var archive model.Obj
+	for {
+		obj, err := Get(ctx, archivePath, &GetArgs{NoLog: true})
+		if err == nil {
+			if obj.IsDir() || !isArchive(obj.GetName()) {
+				return nil, errors.WithStack(errs.ObjectNotFound)
+			}
+			archive = obj
+			break
+		}
+		if archivePath == "/" {
+			return nil, errors.WithStack(errs.ObjectNotFound)
+		}
+		archivePath = stdpath.Dir(archivePath)
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(archivePath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	a := &archiveChain{storage: storage, actualPath: actualPath, archive: archive}
+	inner := strings.TrimPrefix(path, archivePath)
+	current := ""
+	names := strings.Split(strings.Trim(inner, "/"), "/")
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		current += "/" + name
+		if i < len(names)-1 && isArchive(name) {
+			a.innerPaths = append(a.innerPaths, current)
+			current = ""
+		}
+	}
+	a.innerPaths = append(a.innerPaths, utils.FixAndCleanPath(current))
+	return a, a.checkDepth()
+}
+
+// resolveArchiveDir resolves the path as a dir, the archive file in the archive is taken as its root dir
+func resolveArchiveDir(ctx context.Context, path string) (*archiveChain, error) {
+	a, err := resolveArchiveChain(ctx, path)
+	if err != nil || a.innerPath() == "/" {
+		return a, err
+	}
+	obj, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if obj.IsDir() {
+		return a, nil
+	}
+	if !isArchive(obj.GetName()) {
+		return nil, errors.WithStack(errs.NotFolder)
+	}
+	a.innerPaths = append(a.innerPaths, "/")
+	return a, a.checkDepth()
+}
+
+func (a *archiveChain) checkDepth() error {
+	if len(a.innerPaths) > setting.GetInt(conf.ArchiveBrowseDepth, 1) {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	return nil
+}
+
+func (a *archiveChain) innerPath() string {
+	return a.innerPaths[len(a.innerPaths)-1]
+}
+
+// open opens the innermost archive nested, the archives are extracted into the temp files level by level
+func (a *archiveChain) open(ctx context.Context) (tool.Tool, *stream.SeekableStream, error) {
+	rc, size, err := op.InternalExtract(ctx, a.storage, a.actualPath, model.ArchiveInnerArgs{InnerPath: a.innerPaths[0]})
+	if err != nil {
+		return nil, nil, err
+	}
+	ss, err := cacheArchive(ctx, rc, size, a.innerPaths[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 1; i < len(a.innerPaths)-1; i++ {
+		_, t, err := op.GetArchiveTool(ss.GetName())
+		if err != nil {
+			_ = ss.Close()
+			return nil, nil, err
+		}
+		rc, size, err = t.Extract([]*stream.SeekableStream{ss}, model.ArchiveInnerArgs{InnerPath: a.innerPaths[i]})
+		if err != nil {
+			_ = ss.Close()
+			return nil, nil, err
+		}
+		next, err := cacheArchive(ctx, rc, size, a.innerPaths[i])
+		_ = ss.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		ss = next
+	}
+	_, t, err := op.GetArchiveTool(ss.GetName())
+	if err != nil {
+		_ = ss.Close()
+		return nil, nil, err
+	}
+	return t, ss, nil
+}
+
+// cacheArchive saves the archive extracted into a temp file, so that it can be read by the archive tools
+func cacheArchive(ctx context.Context, rc io.ReadCloser, size int64, innerPath string) (*stream.SeekableStream, error) {
+	defer rc.Close()
+	file, err := utils.CreateTempFile(rc, size)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name: stdpath.Base(innerPath),
+			Size: size,
+		},
+		Reader: file,
+	}, nil)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, err
+	}
+	ss.Closers.Add(utils.CloseFunc(func() error {
+		return os.Remove(file.Name())
+	}))
+	return ss, nil
+}
+
+func (a *archiveChain) list(ctx context.Context) ([]model.Obj, error) {
+	if len(a.innerPaths) == 1 {
+		return op.ListArchive(ctx, a.storage, a.actualPath, model.ArchiveListArgs{
+			ArchiveInnerArgs: model.ArchiveInnerArgs{InnerPath: a.innerPath()},
+		})
+	}
+	t, ss, err := a.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.Close()
+	objs, err := t.List([]*stream.SeekableStream{ss}, model.ArchiveInnerArgs{InnerPath: a.innerPath()})
+	if err != nil {
+		return nil, err
+	}
+	model.WrapObjsName(objs)
+	return objs, nil
+}
+
+func (a *archiveChain) get(ctx context.Context) (model.Obj, error) {
+	if a.innerPath() == "/" {
+		name := a.archive.GetName()
+		if len(a.innerPaths) > 1 {
+			name = stdpath.Base(a.innerPaths[len(a.innerPaths)-2])
+		}
+		return &model.Object{
+			Name:     name,
+			Modified: a.archive.ModTime(),
+			IsFolder: true,
+		}, nil
+	}
+	parent := &archiveChain{
+		storage:    a.storage,
+		actualPath: a.actualPath,
+		archive:    a.archive,
+		innerPaths: append(append([]string{}, a.innerPaths[:len(a.innerPaths)-1]...), stdpath.Dir(a.innerPath())),
+	}
+	objs, err := parent.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := stdpath.Base(a.innerPath())
+	for _, obj := range objs {
+		if obj.GetName() == name {
+			return obj, nil
+		}
+	}
+	return nil, errors.WithStack(errs.ObjectNotFound)
+}
+
+// ArchiveBrowseGet gets the obj in the archive by the path like /a.zip/dir/b.tar/c.txt,
+// the path of an archive file is its root dir
+func ArchiveBrowseGet(ctx context.Context, path string) (model.Obj, error) {
+	a, err := resolveArchiveChain(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveObj{Obj: obj}, nil
+}
+
+// ArchiveBrowseDir gets the dir in the archive like ArchiveBrowseGet, but the archive file got is taken as
+// its root dir if archive_browse_depth allows
+func ArchiveBrowseDir(ctx context.Context, path string) (model.Obj, error) {
+	a, err := resolveArchiveDir(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveObj{Obj: obj}, nil
+}
+
+// ArchiveBrowseList lists the dir in the archive, the archive file is listed as its root dir
+// if archive_browse_depth allows
+func ArchiveBrowseList(ctx context.Context, path string) ([]model.Obj, error) {
+	a, err := resolveArchiveDir(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := a.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]model.Obj, len(objs))
+	for i, obj := range objs {
+		ret[i] = &ArchiveObj{Obj: obj}
+	}
+	return ret, nil
+}
+
+// ArchiveBrowseOpen opens the file in the archive, the size of the file is returned with the reader
+func ArchiveBrowseOpen(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	a, err := resolveArchiveChain(ctx, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if a.innerPath() == "/" {
+		return nil, 0, errors.WithStack(errs.NotFile)
+	}
+	if len(a.innerPaths) == 1 {
+		return op.InternalExtract(ctx, a.storage, a.actualPath, model.ArchiveInnerArgs{InnerPath: a.innerPath()})
+	}
+	t, ss, err := a.open(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	rc, size, err := t.Extract([]*stream.SeekableStream{ss}, model.ArchiveInnerArgs{InnerPath: a.innerPath()})
+	if err != nil {
+		_ = ss.Close()
+		return nil, 0, err
+	}
+	return utils.NewReadCloser(rc, func() error {
+		return stderrors.Join(rc.Close(), ss.Close())
+	}), size, nil
+}
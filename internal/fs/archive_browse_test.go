@@ -0,0 +1,100 @@
+package fs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	_ "github.com/alist-org/alist/v3/internal/archive/zip"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func zipFiles(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveBrowse(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Conf = conf.DefaultConfig()
+	conf.Conf.TempDir = t.TempDir()
+	db.Init(dB)
+
+	root := t.TempDir()
+	inner := zipFiles(t, map[string][]byte{"deep.txt": []byte("deep")})
+	outer := zipFiles(t, map[string][]byte{"dir/a.txt": []byte("hello"), "dir/inner.zip": inner})
+	if err = os.WriteFile(filepath.Join(root, "outer.zip"), outer, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err = op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/browse",
+		Addition: `{"root_folder_path":` + strconv.Quote(root) + `}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := fs.ArchiveBrowseList(ctx, "/browse/outer.zip")
+	if err != nil || len(objs) != 1 || objs[0].GetName() != "dir" || !fs.InArchive(objs[0]) {
+		t.Fatalf("unexpected root of the archive %v: %+v", objs, err)
+	}
+	obj, err := fs.ArchiveBrowseGet(ctx, "/browse/outer.zip/dir/a.txt")
+	if err != nil || obj.IsDir() || obj.GetSize() != 5 {
+		t.Fatalf("unexpected obj %v: %+v", obj, err)
+	}
+	rc, _, err := fs.ArchiveBrowseOpen(ctx, "/browse/outer.zip/dir/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(content) != "hello" {
+		t.Errorf("content is %q, want hello", content)
+	}
+
+	// the nested archive is a file until the depth allows
+	if _, err = fs.ArchiveBrowseList(ctx, "/browse/outer.zip/dir/inner.zip"); err == nil {
+		t.Errorf("the nested archive shouldn't be browsed with the depth 1")
+	}
+	if err = op.SaveSettingItem(&model.SettingItem{Key: conf.ArchiveBrowseDepth, Value: "2", Type: conf.TypeNumber}); err != nil {
+		t.Fatal(err)
+	}
+	objs, err = fs.ArchiveBrowseList(ctx, "/browse/outer.zip/dir/inner.zip")
+	if err != nil || len(objs) != 1 || objs[0].GetName() != "deep.txt" {
+		t.Fatalf("unexpected root of the nested archive %v: %+v", objs, err)
+	}
+	rc, size, err := fs.ArchiveBrowseOpen(ctx, "/browse/outer.zip/dir/inner.zip/deep.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ = io.ReadAll(rc)
+	_ = rc.Close()
+	if string(content) != "deep" || size != 4 {
+		t.Errorf("content is %q of size %d, want deep", content, size)
+	}
+}
@@ -0,0 +1,257 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// ArchiveCompressTask compresses the objs in the src dir into an archive in the temp dir,
+// then puts the archive into the dst dir as an upload task
+type ArchiveCompressTask struct {
+	task.TaskExtension
+	status      string
+	SrcDirPath  string   `json:"src_dir_path"`
+	Names       []string `json:"names"`
+	DstDirPath  string   `json:"dst_dir_path"`
+	ArchiveName string   `json:"archive_name"`
+	// Password isn't persisted, the task of an encrypted archive can't be resumed after a restart
+	Password  string `json:"-"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+func (t *ArchiveCompressTask) GetName() string {
+	return fmt.Sprintf("compress %v in [%s] to [%s](%s)", t.Names, t.SrcDirPath, t.DstDirPath, t.ArchiveName)
+}
+
+func (t *ArchiveCompressTask) GetStatus() string {
+	return t.status
+}
+
+func (t *ArchiveCompressTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	if t.Encrypted && t.Password == "" {
+		return tache.Unrecoverable(errors.New("the archive password is lost after the restart"))
+	}
+	ext, compressor, err := tool.GetCompressor(t.ArchiveName)
+	if err != nil {
+		return err
+	}
+	t.status = "walking src objs"
+	entries, total, err := t.walk()
+	if err != nil {
+		return err
+	}
+	t.SetTotalBytes(total)
+	file, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	uploading := false
+	defer func() {
+		if !uploading {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+	aw, err := compressor.NewWriter(file, ext, t.Password)
+	if err != nil {
+		return err
+	}
+	t.status = "compressing"
+	var written int64
+	for _, e := range entries {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		w, err := aw.Create(e.name, e.obj)
+		if err != nil {
+			return errors.WithMessagef(err, "failed create [%s] in archive", e.name)
+		}
+		if e.obj.IsDir() {
+			continue
+		}
+		n, err := t.copyObj(w, stdpath.Join(t.SrcDirPath, e.name), func(n int64) {
+			written += n
+			if total > 0 {
+				t.SetProgress(float64(written) / float64(total) * 100)
+			}
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "failed compress [%s]", e.name)
+		}
+		if n != e.obj.GetSize() {
+			return errors.Errorf("the size of [%s] is %d, but %d bytes read", e.name, e.obj.GetSize(), n)
+		}
+	}
+	if err = aw.Close(); err != nil {
+		return errors.WithMessage(err, "failed close archive")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	t.status = "putting archive"
+	fs := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     t.ArchiveName,
+			Size:     info.Size(),
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(t.ArchiveName),
+		Reader:   file,
+	}
+	fs.Closers.Add(file)
+	fs.Closers.Add(utils.CloseFunc(func() error {
+		return os.Remove(file.Name())
+	}))
+	// the archive is removed by the upload task from now on
+	uploading = true
+	_, err = PutAsTask(t.Ctx(), t.DstDirPath, fs)
+	if err != nil {
+		_ = fs.Close()
+		return err
+	}
+	t.SetProgress(100)
+	return nil
+}
+
+type compressEntry struct {
+	// name is the path relative to the src dir
+	name string
+	obj  model.Obj
+}
+
+// walk lists the objs to be compressed recursively and sums up the size of the files,
+// the objs the creator can't access are skipped
+func (t *ArchiveCompressTask) walk() ([]compressEntry, int64, error) {
+	var entries []compressEntry
+	var total int64
+	srcMeta, err := op.GetNearestMeta(t.SrcDirPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, 0, err
+	}
+	for _, name := range t.Names {
+		srcPath := stdpath.Join(t.SrcDirPath, name)
+		obj, err := Get(t.Ctx(), srcPath, &GetArgs{NoLog: true})
+		if err != nil {
+			return nil, 0, errors.WithMessagef(err, "failed get [%s]", srcPath)
+		}
+		err = WalkFS(t.Ctx(), -1, srcPath, obj, func(reqPath string, info model.Obj) error {
+			ok, err := t.canCompress(srcMeta, reqPath)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel := strings.TrimPrefix(strings.TrimPrefix(reqPath, t.SrcDirPath), "/")
+			entries = append(entries, compressEntry{name: rel, obj: info})
+			if !info.IsDir() {
+				total += info.GetSize()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return entries, total, nil
+}
+
+// canCompress reports whether the creator can access the path, only the password of the meta of the src dir
+// is checked when the task is added, the paths protected by other passwords are skipped
+func (t *ArchiveCompressTask) canCompress(srcMeta *model.Meta, path string) (bool, error) {
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false, err
+	}
+	var password string
+	if meta != nil && srcMeta != nil && meta.ID == srcMeta.ID {
+		password = meta.Password
+	}
+	return common.CanAccessWithRoles(t.GetCreator(), meta, path, password), nil
+}
+
+func (t *ArchiveCompressTask) copyObj(w io.Writer, path string, read func(int64)) (int64, error) {
+	link, obj, err := Link(t.Ctx(), path, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return 0, err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: obj,
+		Ctx: t.Ctx(),
+	}, link)
+	if err != nil {
+		return 0, err
+	}
+	defer ss.Close()
+	return utils.CopyWithBuffer(&progressWriter{Writer: w, written: read}, ss)
+}
+
+type progressWriter struct {
+	io.Writer
+	written func(int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.written(int64(n))
+	return n, err
+}
+
+var ArchiveCompressTaskManager *tache.Manager[*ArchiveCompressTask]
+
+func archiveCompress(ctx context.Context, srcDirPath string, names []string, dstDirPath, archiveName, password string) (task.TaskExtensionInfo, error) {
+	ext, compressor, err := tool.GetCompressor(archiveName)
+	if err != nil {
+		return nil, err
+	}
+	// fail early if the password isn't supported by the format
+	if _, err = compressor.NewWriter(io.Discard, ext, password); err != nil {
+		return nil, err
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	tsk := &ArchiveCompressTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		SrcDirPath:  srcDirPath,
+		Names:       names,
+		DstDirPath:  dstDirPath,
+		ArchiveName: archiveName,
+		Password:    password,
+		Encrypted:   password != "",
+	}
+	ArchiveCompressTaskManager.Add(tsk)
+	return tsk, nil
+}
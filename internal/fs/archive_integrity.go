@@ -0,0 +1,235 @@
+package fs
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+// ArchiveTestTask reads all the files in the archive to verify them without decompressing into the temp dir,
+// the result of each file and the volumes missing are reported through the task API
+type ArchiveTestTask struct {
+	task.TaskExtension
+	status     string
+	SrcObjPath string `json:"src_obj_path"`
+	// Password isn't persisted, the test of an encrypted archive can't be resumed after a restart
+	Password  string `json:"-"`
+	Encrypted bool   `json:"encrypted"`
+	// mu guards the result read by the task API while the task runs
+	mu             sync.RWMutex
+	Entries        []model.ArchiveTestEntry `json:"entries"`
+	MissingVolumes []string                 `json:"missing_volumes"`
+}
+
+// ArchiveTestReport is the summary listed with the tasks
+type ArchiveTestReport struct {
+	Passed         int      `json:"passed"`
+	Failed         int      `json:"failed"`
+	MissingVolumes []string `json:"missing_volumes"`
+}
+
+// ArchiveTestReportDetail is the report with the result of each file, it's got by the tid
+type ArchiveTestReportDetail struct {
+	ArchiveTestReport
+	Entries []model.ArchiveTestEntry `json:"entries"`
+}
+
+func (t *ArchiveTestTask) GetName() string {
+	return fmt.Sprintf("test archive [%s]", t.SrcObjPath)
+}
+
+func (t *ArchiveTestTask) GetStatus() string {
+	return t.status
+}
+
+func (t *ArchiveTestTask) GetReport() any {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.report()
+}
+
+func (t *ArchiveTestTask) GetReportDetail() any {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return ArchiveTestReportDetail{
+		ArchiveTestReport: t.report(),
+		Entries:           t.Entries,
+	}
+}
+
+func (t *ArchiveTestTask) report() ArchiveTestReport {
+	report := ArchiveTestReport{
+		MissingVolumes: t.MissingVolumes,
+	}
+	for _, e := range t.Entries {
+		if e.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+func (t *ArchiveTestTask) setResult(entries []model.ArchiveTestEntry, missingVolumes []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Entries = entries
+	t.MissingVolumes = missingVolumes
+}
+
+func (t *ArchiveTestTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	if t.Encrypted && t.Password == "" {
+		return tache.Unrecoverable(errors.New("the archive password is lost after the restart"))
+	}
+	t.setResult(nil, nil)
+	storage, actualPath, err := op.GetStorageAndActualPath(t.SrcObjPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	partExt, tester, err := getArchiveTester(stdpath.Base(actualPath))
+	if err != nil {
+		return err
+	}
+	var missing []string
+	if partExt != nil {
+		t.status = "checking volumes"
+		missing, err = missingVolumes(t.Ctx(), storage, actualPath, partExt)
+		if err != nil {
+			return err
+		}
+		t.setResult(nil, missing)
+	}
+	t.status = "getting archive"
+	_, _, ss, err := op.GetArchiveToolAndStream(t.Ctx(), storage, actualPath, model.LinkArgs{Header: http.Header{}})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		var e error
+		for _, s := range ss {
+			e = stderrors.Join(e, s.Close())
+		}
+		if e != nil {
+			log.Errorf("failed to close file streamer, %v", e)
+		}
+	}()
+	var total int64
+	for _, s := range ss {
+		total += s.GetSize()
+	}
+	t.SetTotalBytes(total)
+	t.status = "testing"
+	entries, err := tester.Test(ss, model.ArchiveArgs{Password: t.Password}, t.SetProgress)
+	if err != nil {
+		if partExt != nil && len(missing) == 0 {
+			// the volumes are got until one is missing, the archive may fail for lack of the volumes after
+			baseName, _, _ := strings.Cut(stdpath.Base(actualPath), ".")
+			missing = append(missing, baseName+fmt.Sprintf(partExt.PartFileFormat, partExt.SecondPartIndex+len(ss)-1))
+		}
+		t.setResult(entries, missing)
+		t.status = fmt.Sprintf("failed after %d files tested", len(entries))
+		return errors.WithMessagef(err, "failed test archive [%s]", t.SrcObjPath)
+	}
+	t.setResult(entries, missing)
+	report := t.GetReport().(ArchiveTestReport)
+	t.status = fmt.Sprintf("%d files passed, %d failed", report.Passed, report.Failed)
+	t.SetProgress(100)
+	if len(missing) > 0 {
+		return errors.Errorf("%d of %d files failed, volumes missing: %s", report.Failed, len(entries), strings.Join(missing, ", "))
+	}
+	if report.Failed > 0 {
+		return errors.Errorf("%d of %d files failed", report.Failed, len(entries))
+	}
+	return nil
+}
+
+func getArchiveTester(name string) (*tool.MultipartExtension, tool.Tester, error) {
+	partExt, t, err := op.GetArchiveTool(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	tester, ok := t.(tool.Tester)
+	if !ok {
+		return nil, nil, errors.WithMessagef(errs.NotSupport, "failed test [%s]", name)
+	}
+	return partExt, tester, nil
+}
+
+// missingVolumes finds the volumes missing before the last one in the dir of the archive,
+// the volumes after a missing one are never read by the archive tools
+func missingVolumes(ctx context.Context, storage driver.Driver, path string, partExt *tool.MultipartExtension) ([]string, error) {
+	objs, err := op.List(ctx, storage, stdpath.Dir(path), model.ListArgs{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed list the volumes")
+	}
+	baseName, _, _ := strings.Cut(stdpath.Base(path), ".")
+	// the part file format has one verb like .part%d.rar or .z%.2d
+	prefix, rest, _ := strings.Cut(partExt.PartFileFormat, "%")
+	_, suffix, _ := strings.Cut(rest, "d")
+	found := make(map[int]struct{})
+	last := partExt.SecondPartIndex - 1
+	for _, obj := range objs {
+		name, ok := strings.CutPrefix(obj.GetName(), baseName+prefix)
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(name)
+		if err != nil || i < partExt.SecondPartIndex || fmt.Sprintf(partExt.PartFileFormat, i) != prefix+name+suffix {
+			continue
+		}
+		found[i] = struct{}{}
+		last = max(last, i)
+	}
+	var missing []string
+	for i := partExt.SecondPartIndex; i < last; i++ {
+		if _, ok := found[i]; !ok {
+			missing = append(missing, baseName+fmt.Sprintf(partExt.PartFileFormat, i))
+		}
+	}
+	return missing, nil
+}
+
+var ArchiveTestTaskManager *tache.Manager[*ArchiveTestTask]
+
+func archiveTest(ctx context.Context, srcObjPath, password string) (task.TaskExtensionInfo, error) {
+	srcObjPath = stdpath.Clean(srcObjPath)
+	if _, _, err := getArchiveTester(stdpath.Base(srcObjPath)); err != nil {
+		return nil, err
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	tsk := &ArchiveTestTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		SrcObjPath: srcObjPath,
+		Password:   password,
+		Encrypted:  password != "",
+	}
+	ArchiveTestTaskManager.Add(tsk)
+	return tsk, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
@@ -71,7 +72,17 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	}
 	// copy if in the same storage, just call driver.Copy
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		srcObj, err := op.Get(ctx, srcStorage, srcObjActualPath)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
+		}
+		if err = checkObjQuota(ctx, srcObj); err != nil {
+			return nil, err
+		}
 		err = op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		if err == nil {
+			addObjUsage(ctx, srcObj, 1)
+		}
 		if !errors.Is(err, errs.NotImplement) && !errors.Is(err, errs.NotSupport) {
 			return nil, err
 		}
@@ -98,7 +109,7 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 			if err != nil {
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			return nil, op.PutWithQuota(ctx, dstStorage, dstDirActualPath, ss, nil, false)
 		}
 	}
 	// not in the same storage
@@ -175,5 +186,10 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	err = op.PutWithQuota(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	if err == nil {
+		webhook.FireFs(tsk.Ctx(), webhook.EventCopy, utils.GetFullPath(tsk.SrcStorageMp, srcFilePath),
+			stdpath.Join(utils.GetFullPath(tsk.DstStorageMp, dstDirPath), srcFile.GetName()))
+	}
+	return err
 }
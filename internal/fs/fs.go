@@ -4,12 +4,15 @@ import (
 	"context"
 	log "github.com/sirupsen/logrus"
 	"io"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/pkg/errors"
 )
 
@@ -59,54 +62,92 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	err := makeDir(ctx, path, lazyCache...)
+	audit.Fs(ctx, audit.ActionMkdir, path, "", 0, err)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
+	} else {
+		webhook.FireFs(ctx, webhook.EventMkdir, path)
 	}
 	return err
 }
 
 func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
 	err := move(ctx, srcPath, dstDirPath, lazyCache...)
+	audit.Fs(ctx, audit.ActionMove, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)), 0, err)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else {
+		webhook.FireFs(ctx, webhook.EventMove, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	}
 	return err
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
+	audit.Fs(ctx, audit.ActionCopy, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)), 0, err)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
+	} else if res == nil {
+		// the copy task fires the event after each file is copied
+		webhook.FireFs(ctx, webhook.EventCopy, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)))
 	}
 	return res, err
 }
 
 func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
 	err := rename(ctx, srcPath, dstName, lazyCache...)
+	audit.Fs(ctx, audit.ActionRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), 0, err)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
+	} else {
+		webhook.FireFs(ctx, webhook.EventRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
 	}
 	return err
 }
 
 func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
+	audit.Fs(ctx, audit.ActionRemove, path, "", 0, err)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
+	} else {
+		webhook.FireFs(ctx, webhook.EventRemove, path)
+	}
+	return err
+}
+
+func RestoreTrashItem(ctx context.Context, item *model.TrashItem) error {
+	err := restoreTrashItem(ctx, item)
+	audit.Fs(ctx, audit.ActionRestore, item.TrashPath, stdpath.Join(item.OriginPath, item.Name), item.Size, err)
+	if err != nil {
+		log.Errorf("failed restore %s: %+v", stdpath.Join(item.OriginPath, item.Name), err)
+	}
+	return err
+}
+
+func PurgeTrashItem(ctx context.Context, item *model.TrashItem) error {
+	err := purgeTrashItem(ctx, item)
+	audit.Fs(ctx, audit.ActionPurge, item.TrashPath, "", item.Size, err)
+	if err != nil {
+		log.Errorf("failed purge %s: %+v", item.TrashPath, err)
 	}
 	return err
 }
 
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
 	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	audit.Fs(ctx, audit.ActionPut, stdpath.Join(dstDirPath, file.GetName()), "", file.GetSize(), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
+	} else {
+		webhook.FireFs(ctx, webhook.EventPut, stdpath.Join(dstDirPath, file.GetName()))
 	}
 	return err
 }
 
 func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
 	t, err := putAsTask(ctx, dstDirPath, file)
+	audit.Fs(ctx, audit.ActionPut, stdpath.Join(dstDirPath, file.GetName()), "", file.GetSize(), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -131,12 +172,31 @@ func ArchiveList(ctx context.Context, path string, args model.ArchiveListArgs) (
 
 func ArchiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	t, err := archiveDecompress(ctx, srcObjPath, dstDirPath, args, lazyCache...)
+	audit.Fs(ctx, audit.ActionDecompress, srcObjPath, dstDirPath, 0, err)
 	if err != nil {
 		log.Errorf("failed decompress [%s]%s: %+v", srcObjPath, args.InnerPath, err)
 	}
 	return t, err
 }
 
+func ArchiveCompress(ctx context.Context, srcDirPath string, names []string, dstDirPath, archiveName, password string) (task.TaskExtensionInfo, error) {
+	t, err := archiveCompress(ctx, srcDirPath, names, dstDirPath, archiveName, password)
+	audit.Fs(ctx, audit.ActionCompress, srcDirPath, stdpath.Join(dstDirPath, archiveName), 0, err)
+	if err != nil {
+		log.Errorf("failed compress %v in %s to %s: %+v", names, srcDirPath, archiveName, err)
+	}
+	return t, err
+}
+
+func ArchiveTest(ctx context.Context, srcObjPath, password string) (task.TaskExtensionInfo, error) {
+	t, err := archiveTest(ctx, srcObjPath, password)
+	audit.Fs(ctx, audit.ActionTest, srcObjPath, "", 0, err)
+	if err != nil {
+		log.Errorf("failed test archive %s: %+v", srcObjPath, err)
+	}
+	return t, err
+}
+
 func ArchiveDriverExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (*model.Link, model.Obj, error) {
 	l, obj, err := archiveDriverExtract(ctx, path, args)
 	if err != nil {
@@ -35,6 +35,9 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 				return nil, errors.WithMessage(err, "failed get objs")
 			}
 		}
+		if actualPath == "/" {
+			_objs = hideTrashDir(_objs)
+		}
 	}
 
 	om := model.NewObjMerge()
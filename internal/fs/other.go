@@ -50,7 +50,23 @@ func remove(ctx context.Context, path string) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Remove(ctx, storage, actualPath)
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		// if object not found, it's ok
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed get object")
+	}
+	// objects in the trash don't count towards the quota, the usage is released once they are moved there
+	if root, ok := trashRoot(storage, path, actualPath); ok {
+		return moveToTrash(ctx, path, root)
+	}
+	err = op.Remove(ctx, storage, actualPath)
+	if err == nil {
+		addObjUsage(ctx, obj, -1)
+	}
+	return err
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
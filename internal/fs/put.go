@@ -8,8 +8,11 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
+	stdpath "path"
 	"time"
 )
 
@@ -32,7 +35,12 @@ func (t *UploadTask) Run() error {
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
-	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	err := op.PutWithQuota(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	if err == nil {
+		dstPath := stdpath.Join(utils.GetFullPath(t.storage.GetStorage().MountPath, t.dstDirActualPath), t.file.GetName())
+		webhook.FireFs(t.Ctx(), webhook.EventPut, dstPath)
+	}
+	return err
 }
 
 var UploadTaskManager *tache.Manager[*UploadTask]
@@ -55,6 +63,10 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 		//file.SetTmpFile(tempFile)
 	}
 	taskCreator, _ := ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
+	// fail early, the quota is checked again when the task runs
+	if err = op.CheckQuota(op.GetQuotaUser(ctx), file.GetSize(), 1); err != nil {
+		return nil, err
+	}
 	t := &UploadTask{
 		TaskExtension: task.TaskExtension{
 			Creator: taskCreator,
@@ -77,5 +89,5 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	return op.PutWithQuota(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
 }
@@ -0,0 +1,119 @@
+package fs
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// recalculating keeps the ids of the users whose usage is being recalculated
+var recalculating sync.Map
+
+// pendingRecalculations keeps the ids of the users whose usage is going to be recalculated
+var pendingRecalculations sync.Map
+
+// recalculateDelay gathers the changes of dirs made in a short time into one recalculation
+const recalculateDelay = time.Minute
+
+// checkObjQuota checks whether the user in the ctx can store one more copy of the object.
+// Dirs are not walked in advance, the usage of their content is fixed by a recalculation.
+func checkObjQuota(ctx context.Context, obj model.Obj) error {
+	if obj.IsDir() {
+		return nil
+	}
+	return op.CheckQuota(op.GetQuotaUser(ctx), obj.GetSize(), 1)
+}
+
+// addObjUsage records the object stored (sign 1) or released (sign -1) by the user in the ctx
+func addObjUsage(ctx context.Context, obj model.Obj, sign int64) {
+	user := op.GetQuotaUser(ctx)
+	if user == nil {
+		return
+	}
+	if obj.IsDir() {
+		recalculateLater(user)
+		return
+	}
+	if err := op.AddQuotaUsage(user, sign*obj.GetSize(), sign); err != nil {
+		log.Errorf("failed update quota usage of user [%s]: %+v", user.Username, err)
+	}
+}
+
+// recalculateLater recalculates the usage of the user after recalculateDelay,
+// the calls before that share the same recalculation
+func recalculateLater(user *model.User) {
+	if _, loaded := pendingRecalculations.LoadOrStore(user.ID, struct{}{}); loaded {
+		return
+	}
+	time.AfterFunc(recalculateDelay, func() {
+		pendingRecalculations.Delete(user.ID)
+		if err := RecalculateQuotaUsage(context.Background(), user); err != nil {
+			log.Errorf("failed recalculate quota usage of user [%s]: %+v", user.Username, err)
+		}
+	})
+}
+
+// RecalculateQuotaUsage walks the base path of the user and saves what is stored there as the usage.
+// Who uploaded an object isn't recorded, so the objects under the base paths of the other users
+// having a quota nested in the base path are left to those users.
+func RecalculateQuotaUsage(ctx context.Context, user *model.User) error {
+	if _, loaded := recalculating.LoadOrStore(user.ID, struct{}{}); loaded {
+		return nil
+	}
+	defer recalculating.Delete(user.ID)
+	ctx = context.WithValue(ctx, "user", user)
+	root, err := get(ctx, user.BasePath)
+	if err != nil {
+		return err
+	}
+	nested := op.GetNestedQuotaPaths(user)
+	usage := &model.QuotaUsage{UserID: user.ID}
+	err = WalkFS(ctx, -1, user.BasePath, root, func(path string, obj model.Obj) error {
+		if utils.IsCanceled(ctx) {
+			return filepath.SkipDir
+		}
+		if obj.IsDir() {
+			if utils.SliceContains(nested, utils.FixAndCleanPath(path)) {
+				return filepath.SkipDir
+			}
+		} else {
+			usage.UsedBytes += obj.GetSize()
+			usage.UsedFiles++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if utils.IsCanceled(ctx) {
+		return ctx.Err()
+	}
+	usage.Updated = time.Now()
+	return op.SaveQuotaUsage(usage)
+}
+
+// RecalculateQuotaUsages recalculates the usage of all users having a quota
+func RecalculateQuotaUsages(ctx context.Context) {
+	users, err := op.GetAllUsers()
+	if err != nil {
+		log.Errorf("failed get users: %+v", err)
+		return
+	}
+	for i := range users {
+		if utils.IsCanceled(ctx) {
+			return
+		}
+		if bytes, files := op.GetQuotaLimit(&users[i]); bytes <= 0 && files <= 0 {
+			continue
+		}
+		if err = RecalculateQuotaUsage(ctx, &users[i]); err != nil {
+			log.Errorf("failed recalculate quota usage of user [%s]: %+v", users[i].Username, err)
+		}
+	}
+}
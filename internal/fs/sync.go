@@ -0,0 +1,421 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+type SyncTask struct {
+	task.TaskExtension
+	Status          string             `json:"-"`
+	JobID           uint               `json:"job_id"`
+	SrcPath         string             `json:"src_path"`
+	DstPath         string             `json:"dst_path"`
+	Mode            string             `json:"mode"`
+	PropagateDelete bool               `json:"propagate_delete"`
+	DryRun          bool               `json:"dry_run"`
+	Actions         []model.SyncAction `json:"actions"`
+}
+
+func (t *SyncTask) GetName() string {
+	name := fmt.Sprintf("sync [%s] to [%s] in %s mode", t.SrcPath, t.DstPath, t.Mode)
+	if t.DryRun {
+		return "dry run " + name
+	}
+	return name
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *SyncTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	state, err := t.run()
+	t.saveResult(state, err)
+	return err
+}
+
+var SyncTaskManager *tache.Manager[*SyncTask]
+
+// syncState is what a path was like at both sides after the last bidirectional run
+type syncState struct {
+	Size   int64 `json:"size"`
+	SrcMod int64 `json:"src_mod"`
+	DstMod int64 `json:"dst_mod"`
+}
+
+func (t *SyncTask) run() (*string, error) {
+	prev := map[string]syncState{}
+	if t.Mode == model.SyncBidirectional && t.JobID != 0 {
+		job, err := op.GetSyncJobById(t.JobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.State != "" {
+			if err = utils.Json.UnmarshalFromString(job.State, &prev); err != nil {
+				log.Warnf("failed decode state of sync job [%s], comparing without it: %+v", job.Name, err)
+				prev = map[string]syncState{}
+			}
+		}
+	}
+	src, dst, err := t.listBoth()
+	if err != nil {
+		return nil, err
+	}
+	t.Status = "comparing"
+	t.Actions = diffSync(t.Mode, t.PropagateDelete, src, dst, prev)
+	if t.DryRun {
+		t.Status = fmt.Sprintf("dry run done, %d actions", len(t.Actions))
+		t.SetProgress(100)
+		return nil, nil
+	}
+	failed := 0
+	for i := range t.Actions {
+		if utils.IsCanceled(t.Ctx()) {
+			return nil, t.Ctx().Err()
+		}
+		a := &t.Actions[i]
+		t.Status = fmt.Sprintf("%s %s", a.Op, a.Path)
+		if err = t.apply(a); err != nil {
+			a.Error = err.Error()
+			failed++
+		} else {
+			a.Done = true
+		}
+		t.SetProgress(float64(i+1) / float64(len(t.Actions)) * 100)
+	}
+	t.SetProgress(100)
+	t.Status = fmt.Sprintf("done, %d actions", len(t.Actions))
+	var state *string
+	if t.Mode == model.SyncBidirectional {
+		state = t.newState()
+	}
+	if failed > 0 {
+		return state, errors.Errorf("%d of %d actions failed", failed, len(t.Actions))
+	}
+	return state, nil
+}
+
+func (t *SyncTask) listBoth() (map[string]model.Obj, map[string]model.Obj, error) {
+	t.Status = "listing src"
+	src, err := listSyncTree(t.Ctx(), t.SrcPath)
+	if err != nil {
+		// never take a missing src as empty, the dst would be cleared
+		return nil, nil, errors.WithMessagef(err, "failed list src [%s]", t.SrcPath)
+	}
+	t.Status = "listing dst"
+	dst, err := listSyncTree(t.Ctx(), t.DstPath)
+	if err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return nil, nil, errors.WithMessagef(err, "failed list dst [%s]", t.DstPath)
+		}
+		dst = map[string]model.Obj{}
+	}
+	return src, dst, nil
+}
+
+// newState lists both sides again and returns the encoded state, nil if it fails
+func (t *SyncTask) newState() *string {
+	src, dst, err := t.listBoth()
+	if err != nil {
+		log.Warnf("failed list for the state of sync job %d: %+v", t.JobID, err)
+		return nil
+	}
+	state := make(map[string]syncState)
+	for p, s := range src {
+		d, ok := dst[p]
+		if !ok || s.IsDir() != d.IsDir() {
+			continue
+		}
+		state[p] = syncState{Size: s.GetSize(), SrcMod: s.ModTime().Unix(), DstMod: d.ModTime().Unix()}
+	}
+	str, err := utils.Json.MarshalToString(state)
+	if err != nil {
+		log.Warnf("failed encode state of sync job %d: %+v", t.JobID, err)
+		return nil
+	}
+	return &str
+}
+
+func (t *SyncTask) apply(a *model.SyncAction) error {
+	srcPath, dstPath := stdpath.Join(t.SrcPath, a.Path), stdpath.Join(t.DstPath, a.Path)
+	switch a.Op {
+	case model.SyncOpCopy:
+		return syncCopy(t.Ctx(), srcPath, stdpath.Dir(dstPath))
+	case model.SyncOpCopyBack:
+		return syncCopy(t.Ctx(), dstPath, stdpath.Dir(srcPath))
+	case model.SyncOpMkdir:
+		return makeDir(t.Ctx(), dstPath)
+	case model.SyncOpMkdirBack:
+		return makeDir(t.Ctx(), srcPath)
+	case model.SyncOpDelete:
+		return remove(t.Ctx(), dstPath)
+	case model.SyncOpDeleteSrc:
+		return remove(t.Ctx(), srcPath)
+	}
+	return nil
+}
+
+func (t *SyncTask) saveResult(state *string, err error) {
+	if t.JobID == 0 {
+		return
+	}
+	var result string
+	switch {
+	case err != nil:
+		result = "failed: " + err.Error()
+	case t.DryRun:
+		result = fmt.Sprintf("dry run, %d actions", len(t.Actions))
+	default:
+		result = fmt.Sprintf("succeeded, %d actions", len(t.Actions))
+	}
+	if err := op.UpdateSyncJobResult(t.JobID, time.Now(), result, state); err != nil {
+		log.Errorf("failed save result of sync job %d: %+v", t.JobID, err)
+	}
+}
+
+func syncCopy(ctx context.Context, srcPath, dstDirPath string) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	return transferBetween2Storages(ctx, srcStorage, dstStorage, srcActualPath, dstDirActualPath)
+}
+
+// listSyncTree returns all objects under the root keyed by the path relative to it
+func listSyncTree(ctx context.Context, root string) (map[string]model.Obj, error) {
+	if _, err := get(ctx, root); err != nil {
+		return nil, err
+	}
+	objs := make(map[string]model.Obj)
+	dirs := []string{""}
+	for len(dirs) > 0 {
+		if utils.IsCanceled(ctx) {
+			return nil, ctx.Err()
+		}
+		dir := dirs[0]
+		dirs = dirs[1:]
+		children, err := list(ctx, stdpath.Join(root, dir), &ListArgs{Refresh: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range children {
+			p := stdpath.Join(dir, obj.GetName())
+			objs[p] = obj
+			if obj.IsDir() {
+				dirs = append(dirs, p)
+			}
+		}
+	}
+	return objs, nil
+}
+
+// sameContent compares the sizes and the hashes of the files, known is false if they have no hash in common
+func sameContent(a, b model.Obj) (same, known bool) {
+	if a.GetSize() != b.GetSize() {
+		return false, true
+	}
+	bHash := b.GetHash()
+	for ht, v := range a.GetHash().All() {
+		if w := bHash.GetHash(ht); v != "" && w != "" {
+			return strings.EqualFold(v, w), true
+		}
+	}
+	return true, false
+}
+
+// diffSync returns the actions making the dst (and the src for bidirectional) up to date, parents come before children
+func diffSync(mode string, propagateDelete bool, src, dst map[string]model.Obj, prev map[string]syncState) []model.SyncAction {
+	paths := make([]string, 0, len(src)+len(dst))
+	for p := range src {
+		paths = append(paths, p)
+	}
+	for p := range dst {
+		if _, ok := src[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	var actions []model.SyncAction
+	add := func(op, p, reason string) {
+		actions = append(actions, model.SyncAction{Op: op, Path: p, Reason: reason})
+	}
+	// the children of a deleted dir are deleted with it
+	var deleted []string
+	deletedParent := func(p string) bool {
+		for _, dir := range deleted {
+			if strings.HasPrefix(p, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+	// unchanged reports whether the object and everything under it are the same as after the last run,
+	// only such objects are deleted when the other side deleted them
+	unchanged := func(objs map[string]model.Obj, isSrc bool, root string) bool {
+		for p, obj := range objs {
+			if p != root && !strings.HasPrefix(p, root+"/") {
+				continue
+			}
+			state, ok := prev[p]
+			if !ok {
+				return false
+			}
+			mod := state.DstMod
+			if isSrc {
+				mod = state.SrcMod
+			}
+			if !obj.IsDir() && (obj.GetSize() != state.Size || obj.ModTime().Unix() != mod) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, p := range paths {
+		if deletedParent(p) {
+			continue
+		}
+		s, inSrc := src[p]
+		d, inDst := dst[p]
+		switch {
+		case inSrc && !inDst:
+			if mode == model.SyncBidirectional && propagateDelete && unchanged(src, true, p) {
+				add(model.SyncOpDeleteSrc, p, "deleted from dst")
+				deleted = append(deleted, p)
+			} else if s.IsDir() {
+				add(model.SyncOpMkdir, p, "missing in dst")
+			} else {
+				add(model.SyncOpCopy, p, "missing in dst")
+			}
+		case !inSrc && inDst:
+			switch {
+			case mode == model.SyncMirror && propagateDelete:
+				add(model.SyncOpDelete, p, "missing in src")
+				deleted = append(deleted, p)
+			case mode != model.SyncBidirectional:
+			case propagateDelete && unchanged(dst, false, p):
+				add(model.SyncOpDelete, p, "deleted from src")
+				deleted = append(deleted, p)
+			case d.IsDir():
+				add(model.SyncOpMkdirBack, p, "missing in src")
+			default:
+				add(model.SyncOpCopyBack, p, "missing in src")
+			}
+		case mode == model.SyncCopyNew, s.IsDir() && d.IsDir():
+		case s.IsDir() != d.IsDir():
+			add(model.SyncOpConflict, p, "dir at one side and file at the other")
+		case mode == model.SyncMirror:
+			if same, known := sameContent(s, d); !same {
+				add(model.SyncOpCopy, p, "changed")
+			} else if !known && s.ModTime().After(d.ModTime()) {
+				// the dst gets a new modtime when it's copied, so it's only older if the src changed after that
+				add(model.SyncOpCopy, p, "newer in src")
+			}
+		default:
+			state, inState := prev[p]
+			same, known := sameContent(s, d)
+			if same && (known || !inState) {
+				continue
+			}
+			srcChanged := !inState || s.GetSize() != state.Size || s.ModTime().Unix() != state.SrcMod
+			dstChanged := !inState || d.GetSize() != state.Size || d.ModTime().Unix() != state.DstMod
+			switch {
+			case !srcChanged && !dstChanged:
+			case !dstChanged:
+				add(model.SyncOpCopy, p, "changed in src")
+			case !srcChanged:
+				add(model.SyncOpCopyBack, p, "changed in dst")
+			case s.ModTime().After(d.ModTime()):
+				add(model.SyncOpCopy, p, "newer in src")
+			case d.ModTime().After(s.ModTime()):
+				add(model.SyncOpCopyBack, p, "newer in dst")
+			default:
+				add(model.SyncOpConflict, p, "changed at both sides at the same time")
+			}
+		}
+	}
+	return actions
+}
+
+var syncCrons = struct {
+	sync.Mutex
+	m map[uint]*cron.Cron
+}{m: make(map[uint]*cron.Cron)}
+
+// RunSyncJob adds a task syncing the job, only one task of a job runs at a time
+func RunSyncJob(ctx context.Context, job *model.SyncJob, dryRun bool) (task.TaskExtensionInfo, error) {
+	running := SyncTaskManager.GetByCondition(func(t *SyncTask) bool {
+		return t.JobID == job.ID && !utils.SliceContains([]tache.State{
+			tache.StateSucceeded, tache.StateFailed, tache.StateCanceled, tache.StateErrored,
+		}, t.GetState())
+	})
+	if len(running) > 0 {
+		return nil, errors.WithStack(errs.SyncJobRunning)
+	}
+	creator, _ := ctx.Value("user").(*model.User)
+	t := &SyncTask{
+		TaskExtension: task.TaskExtension{
+			Creator: creator,
+		},
+		JobID:           job.ID,
+		SrcPath:         job.SrcPath,
+		DstPath:         job.DstPath,
+		Mode:            job.Mode,
+		PropagateDelete: job.PropagateDelete,
+		DryRun:          dryRun,
+	}
+	SyncTaskManager.Add(t)
+	return t, nil
+}
+
+// ScheduleSyncJob (re)starts the periodic runs of the job, the old schedule is stopped
+func ScheduleSyncJob(job model.SyncJob) {
+	syncCrons.Lock()
+	defer syncCrons.Unlock()
+	if c, ok := syncCrons.m[job.ID]; ok {
+		c.Stop()
+		delete(syncCrons.m, job.ID)
+	}
+	if job.Disabled || job.Interval <= 0 {
+		return
+	}
+	c := cron.NewCron(time.Duration(job.Interval) * time.Minute)
+	c.Do(func() {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			log.Errorf("failed get admin for sync job [%s]: %+v", job.Name, err)
+			return
+		}
+		if _, err = RunSyncJob(context.WithValue(context.Background(), "user", admin), &job, false); err != nil {
+			log.Warnf("skip scheduled run of sync job [%s]: %+v", job.Name, err)
+		}
+	})
+	syncCrons.m[job.ID] = c
+}
+
+func UnscheduleSyncJob(id uint) {
+	ScheduleSyncJob(model.SyncJob{ID: id, Disabled: true})
+}
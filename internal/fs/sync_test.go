@@ -0,0 +1,67 @@
+package fs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func syncObjs(objs ...model.Object) map[string]model.Obj {
+	m := make(map[string]model.Obj)
+	for i := range objs {
+		m[objs[i].Path] = &objs[i]
+	}
+	return m
+}
+
+func syncOps(actions []model.SyncAction) []string {
+	ops := make([]string, len(actions))
+	for i, a := range actions {
+		ops[i] = a.Op + " " + a.Path
+	}
+	return ops
+}
+
+func TestDiffSync(t *testing.T) {
+	old, now := time.Unix(1000, 0), time.Unix(2000, 0)
+	src := syncObjs(
+		model.Object{Path: "a", IsFolder: true},
+		model.Object{Path: "a/new", Size: 1, Modified: now},
+		model.Object{Path: "same", Size: 2, Modified: old},
+		model.Object{Path: "changed", Size: 3, Modified: now},
+	)
+	dst := syncObjs(
+		model.Object{Path: "same", Size: 2, Modified: now},
+		model.Object{Path: "changed", Size: 4, Modified: old},
+		model.Object{Path: "extra", IsFolder: true},
+		model.Object{Path: "extra/file", Size: 5, Modified: old},
+	)
+	tests := []struct {
+		mode      string
+		propagate bool
+		prev      map[string]syncState
+		want      []string
+	}{
+		{model.SyncCopyNew, true, nil, []string{"mkdir a", "copy a/new"}},
+		{model.SyncMirror, false, nil, []string{"mkdir a", "copy a/new", "copy changed"}},
+		// the children of a deleted dir are not deleted one by one
+		{model.SyncMirror, true, nil, []string{"mkdir a", "copy a/new", "copy changed", "delete extra"}},
+		// without a state nothing is taken as deleted
+		{model.SyncBidirectional, true, nil, []string{"mkdir a", "copy a/new", "copy changed", "mkdir_back extra", "copy_back extra/file"}},
+		{model.SyncBidirectional, true, map[string]syncState{
+			"a":          {},
+			"a/new":      {Size: 1, SrcMod: now.Unix()},
+			"changed":    {Size: 3, SrcMod: now.Unix(), DstMod: old.Unix()},
+			"extra":      {},
+			"extra/file": {Size: 5, DstMod: old.Unix()},
+		}, []string{"delete_src a", "copy_back changed", "delete extra"}},
+	}
+	for _, tt := range tests {
+		got := syncOps(diffSync(tt.mode, tt.propagate, src, dst, tt.prev))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s propagate=%v: got %v, want %v", tt.mode, tt.propagate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package fs
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// TrashDirName is the dir at the root of a storage keeping removed objects when its trash policy is hidden_dir
+const TrashDirName = model.TrashDirName
+
+// trashRoot returns the mount path of the trash dir the object should be moved into,
+// it returns false if the object should be removed permanently
+func trashRoot(storage driver.Driver, path, actualPath string) (string, bool) {
+	s := storage.GetStorage()
+	switch {
+	case s.TrashHiddenDir():
+		root := stdpath.Join(s.MountPath, TrashDirName)
+		if actualPath == "/" || utils.IsSubPath(root, path) {
+			return "", false
+		}
+		if !canMove(storage) {
+			log.Warnf("storage [%s] can't move objects into the trash, %s is removed permanently", s.MountPath, path)
+			return "", false
+		}
+		return root, true
+	case s.TrashStorage():
+		root := utils.FixAndCleanPath(s.TrashPath)
+		if utils.IsSubPath(root, path) || utils.IsSubPath(path, root) {
+			return "", false
+		}
+		if trashStorage, _, err := op.GetStorageAndActualPath(root); err == nil && trashStorage.GetStorage() == s && !canMove(storage) {
+			log.Warnf("storage [%s] can't move objects into the trash, %s is removed permanently", s.MountPath, path)
+			return "", false
+		}
+		return root, true
+	}
+	return "", false
+}
+
+func canMove(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Move, driver.MoveResult:
+		return true
+	}
+	return false
+}
+
+func hideTrashDir(objs []model.Obj) []model.Obj {
+	return utils.SliceFilter(objs, func(obj model.Obj) bool {
+		return !obj.IsDir() || obj.GetName() != TrashDirName
+	})
+}
+
+// moveToTrash moves the object into a new dir under root, an object going to another storage
+// is moved by a TrashTask and stays in place until the task finishes
+func moveToTrash(ctx context.Context, path, root string) error {
+	obj, err := get(ctx, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get object")
+	}
+	item := &model.TrashItem{
+		Name:       obj.GetName(),
+		OriginPath: stdpath.Dir(path),
+		// every object gets its own dir so that objects with the same name don't conflict
+		TrashPath: stdpath.Join(root, time.Now().Format("20060102150405")+"_"+random.String(8)),
+		IsDir:     obj.IsDir(),
+		Size:      obj.GetSize(),
+		Deleted:   time.Now(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		item.UserID = user.ID
+	}
+	if err = makeDir(ctx, item.TrashPath); err != nil {
+		return errors.WithMessage(err, "failed make trash dir")
+	}
+	moved, err := moveInStorage(ctx, path, item.TrashPath)
+	if err != nil {
+		_ = remove(ctx, item.TrashPath)
+		return errors.WithMessage(err, "failed move object into trash")
+	}
+	if !moved {
+		addTrashTask(ctx, item, false)
+		return nil
+	}
+	return trashed(ctx, item)
+}
+
+// trashed records the item after its object is moved into the trash
+func trashed(ctx context.Context, item *model.TrashItem) error {
+	addObjUsage(ctx, item.Obj(), -1)
+	return op.CreateTrashItem(item)
+}
+
+func restoreTrashItem(ctx context.Context, item *model.TrashItem) error {
+	if _, err := get(ctx, stdpath.Join(item.OriginPath, item.Name)); err == nil {
+		return errors.WithStack(errs.RestoreTargetExists)
+	} else if !errs.IsObjectNotFound(err) {
+		return err
+	}
+	if err := checkObjQuota(ctx, item.Obj()); err != nil {
+		return err
+	}
+	if err := makeDir(ctx, item.OriginPath); err != nil {
+		return errors.WithMessage(err, "failed make origin dir")
+	}
+	moved, err := moveInStorage(ctx, stdpath.Join(item.TrashPath, item.Name), item.OriginPath)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		addTrashTask(ctx, item, true)
+		return nil
+	}
+	return restored(ctx, item)
+}
+
+// restored forgets the item after its object is moved back to the origin dir
+func restored(ctx context.Context, item *model.TrashItem) error {
+	addObjUsage(ctx, item.Obj(), 1)
+	if err := removePermanently(ctx, item.TrashPath); err != nil {
+		log.Warnf("failed remove trash dir %s: %+v", item.TrashPath, err)
+	}
+	return op.DeleteTrashItemById(item.ID)
+}
+
+func purgeTrashItem(ctx context.Context, item *model.TrashItem) error {
+	err := removePermanently(ctx, item.TrashPath)
+	// the object is gone already, just forget it
+	if err != nil && !errs.IsObjectNotFound(err) && !errors.Is(errors.Cause(err), errs.StorageNotFound) {
+		return err
+	}
+	return op.DeleteTrashItemById(item.ID)
+}
+
+func removePermanently(ctx context.Context, path string) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.Remove(ctx, storage, actualPath)
+}
+
+// moveInStorage moves the object into the dst dir if both are in the same storage,
+// it returns false if they are not
+func moveInStorage(ctx context.Context, srcPath, dstDirPath string) (bool, error) {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed get dst storage")
+	}
+	if srcStorage.GetStorage() != dstStorage.GetStorage() {
+		return false, nil
+	}
+	return true, op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
+}
+
+// transfer moves the object into the dst dir of another storage, the src is removed after it's copied
+func transfer(ctx context.Context, srcPath, dstDirPath string) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	if err = transferBetween2Storages(ctx, srcStorage, dstStorage, srcActualPath, dstDirActualPath); err != nil {
+		return err
+	}
+	return op.Remove(ctx, srcStorage, srcActualPath)
+}
+
+func transferBetween2Storages(ctx context.Context, srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
+	srcObj, err := op.Get(ctx, srcStorage, srcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
+	}
+	if srcObj.IsDir() {
+		dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		if err = op.MakeDir(ctx, dstStorage, dstObjPath); err != nil {
+			return errors.WithMessagef(err, "failed make dst [%s] dir", dstObjPath)
+		}
+		objs, err := op.List(ctx, srcStorage, srcObjPath, model.ListArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed list src [%s] objs", srcObjPath)
+		}
+		for _, obj := range objs {
+			if err = transferBetween2Storages(ctx, srcStorage, dstStorage, stdpath.Join(srcObjPath, obj.GetName()), dstObjPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	link, _, err := op.Link(ctx, srcStorage, srcObjPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", srcObjPath)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: srcObj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
+	}
+	return op.Put(ctx, dstStorage, dstDirPath, ss, nil, false)
+}
+
+// PurgeExpiredTrash removes the objects which have been in the trash for longer than the configured days
+func PurgeExpiredTrash(ctx context.Context) {
+	days := setting.GetInt(conf.TrashAutoPurgeDays, 0)
+	if days <= 0 {
+		return
+	}
+	items, err := op.GetTrashItemsDeletedBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed get expired trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		if utils.IsCanceled(ctx) {
+			return
+		}
+		if err = PurgeTrashItem(ctx, &items[i]); err == nil {
+			log.Infof("purged expired trash item %s", stdpath.Join(items[i].OriginPath, items[i].Name))
+		}
+	}
+}
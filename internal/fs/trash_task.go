@@ -0,0 +1,65 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/xhofe/tache"
+)
+
+// TrashTask moves an object into the trash or back to its origin dir when they are in different storages,
+// the trash item is created or removed after the object is moved
+type TrashTask struct {
+	task.TaskExtension
+	Status  string          `json:"-"`
+	Item    model.TrashItem `json:"item"`
+	Restore bool            `json:"restore"`
+}
+
+func (t *TrashTask) GetName() string {
+	if t.Restore {
+		return fmt.Sprintf("restore [%s] from trash", stdpath.Join(t.Item.OriginPath, t.Item.Name))
+	}
+	return fmt.Sprintf("move [%s] to trash", stdpath.Join(t.Item.OriginPath, t.Item.Name))
+}
+
+func (t *TrashTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *TrashTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	src, dstDir := stdpath.Join(t.Item.OriginPath, t.Item.Name), t.Item.TrashPath
+	if t.Restore {
+		src, dstDir = stdpath.Join(t.Item.TrashPath, t.Item.Name), t.Item.OriginPath
+	}
+	t.Status = "transferring"
+	if err := transfer(t.Ctx(), src, dstDir); err != nil {
+		return err
+	}
+	t.Status = "done"
+	if t.Restore {
+		return restored(t.Ctx(), &t.Item)
+	}
+	return trashed(t.Ctx(), &t.Item)
+}
+
+var TrashTaskManager *tache.Manager[*TrashTask]
+
+func addTrashTask(ctx context.Context, item *model.TrashItem, restore bool) {
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	TrashTaskManager.Add(&TrashTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		Item:    *item,
+		Restore: restore,
+	})
+}
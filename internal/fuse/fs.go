@@ -1,170 +1,483 @@
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
-
+import (
+	"context"
+	"os"
+	stdpath "path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs exposes the unified alist namespace under RootFolder as a FUSE file system.
+// All operations go through the internal/fs package, so every storage driver is supported.
 type Fs struct {
 	RootFolder string
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
 
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	ctx     context.Context
+	cancel  context.CancelFunc
+	uid     uint32
+	gid     uint32
+	attrs   cache.ICache[model.Obj]
+	handles generic_sync.MapOf[uint64, *fileHandle]
+	nextFh  atomic.Uint64
+	lock    sync.Mutex
+}
+
+// NewFs serves rootFolder as user, the uploads and the other changes are made on behalf of the user
+func NewFs(rootFolder string, user *model.User) *Fs {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "user", user))
+	return &Fs{
+		RootFolder: utils.FixAndCleanPath(rootFolder),
+		ctx:        ctx,
+		cancel:     cancel,
+		uid:        uint32(os.Getuid()),
+		gid:        uint32(os.Getgid()),
+		attrs:      cache.NewMemCache(cache.WithShards[model.Obj](64)),
+	}
+}
+
+func (f *Fs) Init() {
+	utils.Log.Infof("fuse: serving %s", f.RootFolder)
+}
+
+func (f *Fs) Destroy() {
+	f.handles.Range(func(fh uint64, h *fileHandle) bool {
+		if err := h.release(f.ctx); err != nil {
+			utils.Log.Errorf("fuse: failed to release %s: %+v", h.path, err)
+		}
+		f.handles.Delete(fh)
+		return true
+	})
+	f.cancel()
+	f.attrs.Clear()
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	// storages don't report their capacity in a uniform way, so pretend there is plenty of space
+	const blocks = 1 << 40 / blockSize
+	stat.Bsize = blockSize
+	stat.Frsize = blockSize
+	stat.Blocks = blocks
+	stat.Bfree = blocks
+	stat.Bavail = blocks
+	stat.Files = 1 << 30
+	stat.Ffree = 1 << 30
+	stat.Namemax = 255
+	return 0
+}
+
+func (f *Fs) Mknod(path string, mode uint32, dev uint64) int {
+	if mode&fuse.S_IFMT != fuse.S_IFREG && mode&fuse.S_IFMT != 0 {
+		return -fuse.ENOSYS
+	}
+	h := newFileHandle(f.realPath(path), nil)
+	h.truncated = true
+	if err := h.prepareWrite(f.ctx); err != nil {
+		return errno(err)
+	}
+	defer f.invalidate(h.path)
+	return errno(h.release(f.ctx))
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	p := f.realPath(path)
+	defer f.invalidate(p)
+	return errno(f.do(func(ctx context.Context) error {
+		return fs.MakeDir(ctx, p)
+	}))
+}
+
+func (f *Fs) Unlink(path string) int {
+	p := f.realPath(path)
+	defer f.invalidate(p)
+	return errno(f.do(func(ctx context.Context) error {
+		return fs.Remove(ctx, p)
+	}))
+}
+
+func (f *Fs) Rmdir(path string) int {
+	p := f.realPath(path)
+	objs, err := fs.List(f.ctx, p, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if len(objs) > 0 {
+		return -fuse.ENOTEMPTY
+	}
+	defer f.attrs.Clear()
+	return errno(f.do(func(ctx context.Context) error {
+		return fs.Remove(ctx, p)
+	}))
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	src, dst := f.realPath(oldpath), f.realPath(newpath)
+	if src == dst {
+		return 0
+	}
+	srcObj, err := fs.Get(f.ctx, src, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	dstObj, err := fs.Get(f.ctx, dst, &fs.GetArgs{NoLog: true})
+	if err != nil && !errs.IsNotFoundError(err) {
+		return errno(err)
+	}
+	// rename(2) replaces only a file by a file, or a dir by an empty dir
+	if dstObj != nil {
+		switch {
+		case srcObj.IsDir() && !dstObj.IsDir():
+			return -fuse.ENOTDIR
+		case !srcObj.IsDir() && dstObj.IsDir():
+			return -fuse.EISDIR
+		case dstObj.IsDir():
+			objs, err := fs.List(f.ctx, dst, &fs.ListArgs{NoLog: true})
+			if err != nil {
+				return errno(err)
+			}
+			if len(objs) > 0 {
+				return -fuse.ENOTEMPTY
+			}
+		}
+	}
+	defer f.attrs.Clear()
+	return errno(f.do(func(ctx context.Context) error {
+		dstDir, dstName := stdpath.Split(dst)
+		if dstObj == nil {
+			return moveTo(ctx, src, dstDir, dstName)
+		}
+		// the destination is kept until the source is moved beside it
+		tmpName := "." + dstName + ".alist-rename-" + random.String(8)
+		if err := moveTo(ctx, src, dstDir, tmpName); err != nil {
+			return err
+		}
+		if err := fs.Remove(ctx, dst); err != nil {
+			return err
+		}
+		return fs.Rename(ctx, stdpath.Join(dstDir, tmpName), dstName)
+	}))
+}
+
+// moveTo moves src into dstDir with the name dstName
+func moveTo(ctx context.Context, src, dstDir, dstName string) error {
+	srcDir, srcName := stdpath.Split(src)
+	if srcDir == dstDir {
+		return fs.Rename(ctx, src, dstName)
+	}
+	if err := fs.Move(ctx, src, dstDir); err != nil {
+		return err
+	}
+	if srcName != dstName {
+		return fs.Rename(ctx, stdpath.Join(dstDir, srcName), dstName)
+	}
+	return nil
+}
+
+// Chmod, Chown and Utimens are accepted but ignored, storages have no place to keep them.
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	h := newFileHandle(f.realPath(path), nil)
+	h.writable = true
+	h.truncated = true
+	if err := h.prepareWrite(f.ctx); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	f.invalidate(h.path)
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	p := f.realPath(path)
+	obj, err := f.get(p)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	h := newFileHandle(p, obj)
+	h.writable = flags&fuse.O_ACCMODE != fuse.O_RDONLY
+	if h.writable && flags&fuse.O_TRUNC != 0 {
+		h.truncated = true
+		if err = h.prepareWrite(f.ctx); err != nil {
+			return errno(err), ^uint64(0)
+		}
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	p := f.realPath(path)
+	if h, ok := f.handles.Load(fh); ok {
+		if size, ok := h.pendingSize(); ok {
+			f.fillStat(stat, &model.Object{Name: stdpath.Base(p), Size: size, Modified: time.Now()})
+			return 0
+		}
+	}
+	obj, err := f.get(p)
+	if err != nil {
+		return errno(err)
+	}
+	f.fillStat(stat, obj)
+	return 0
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	if h, ok := f.handles.Load(fh); ok && h.writable {
+		return errno(h.truncate(f.ctx, size))
+	}
+	p := f.realPath(path)
+	obj, err := f.get(p)
+	if err != nil {
+		return errno(err)
+	}
+	if obj.GetSize() == size {
+		return 0
+	}
+	h := newFileHandle(p, obj)
+	h.writable = true
+	if err = h.truncate(f.ctx, size); err != nil {
+		_ = h.close()
+		return errno(err)
+	}
+	defer f.invalidate(p)
+	return errno(h.release(f.ctx))
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	n, err := h.readAt(f.ctx, buff, ofst)
+	if err != nil {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok || !h.writable {
+		return -fuse.EBADF
+	}
+	n, err := h.writeAt(f.ctx, buff, ofst)
+	if err != nil {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Flush(path string, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return 0
+	}
+	defer f.invalidate(h.path)
+	return errno(h.flush(f.ctx))
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return 0
+	}
+	f.handles.Delete(fh)
+	defer f.invalidate(h.path)
+	return errno(h.release(f.ctx))
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	return f.Flush(path, fh)
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	obj, err := f.get(f.realPath(path))
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, ^uint64(0)
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	p := f.realPath(path)
+	objs, err := fs.List(f.ctx, p, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	expiration := f.expiration(p)
+	for _, obj := range objs {
+		if expiration > 0 {
+			f.attrs.Set(stdpath.Join(p, obj.GetName()), obj, cache.WithEx[model.Obj](expiration))
+		}
+		stat := &fuse.Stat_t{}
+		f.fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+const blockSize = 4096
+
+func (f *Fs) realPath(path string) string {
+	return stdpath.Join(f.RootFolder, utils.FixAndCleanPath(path))
+}
+
+func (f *Fs) addHandle(h *fileHandle) uint64 {
+	fh := f.nextFh.Add(1)
+	f.handles.Store(fh, h)
+	return fh
+}
+
+// expiration returns how long the attributes under path may be cached,
+// it follows the CacheExpiration of the storage the path belongs to.
+func (f *Fs) expiration(path string) time.Duration {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return 0
+	}
+	return time.Minute * time.Duration(storage.GetStorage().CacheExpiration)
+}
+
+func (f *Fs) get(path string) (model.Obj, error) {
+	if obj, ok := f.attrs.Get(path); ok {
+		return obj, nil
+	}
+	if h := f.openedFile(path); h != nil {
+		if size, ok := h.pendingSize(); ok {
+			return &model.Object{Name: stdpath.Base(path), Size: size, Modified: time.Now()}, nil
+		}
+	}
+	obj, err := fs.Get(f.ctx, path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	if expiration := f.expiration(path); expiration > 0 {
+		f.attrs.Set(path, obj, cache.WithEx[model.Obj](expiration))
+	}
+	return obj, nil
+}
+
+// openedFile returns a handle of path whose content has not been uploaded yet
+func (f *Fs) openedFile(path string) *fileHandle {
+	var ret *fileHandle
+	f.handles.Range(func(_ uint64, h *fileHandle) bool {
+		if h.path == path && h.writable {
+			ret = h
+			return false
+		}
+		return true
+	})
+	return ret
+}
+
+func (f *Fs) invalidate(path string) {
+	f.attrs.Del(path, stdpath.Dir(path))
+}
+
+// do serializes operations that change the tree, so that concurrent renames
+// and removes don't observe each other half done.
+func (f *Fs) do(fn func(ctx context.Context) error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return fn(f.ctx)
+}
+
+func (f *Fs) fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+		stat.Blocks = (stat.Size + 511) / 512
+	}
+	stat.Blksize = blockSize
+	stat.Uid = f.uid
+	stat.Gid = f.gid
+	modified := fuse.NewTimespec(obj.ModTime())
+	stat.Mtim = modified
+	stat.Atim = modified
+	stat.Ctim = modified
+	if created := obj.CreateTime(); !created.IsZero() {
+		stat.Birthtim = fuse.NewTimespec(created)
+	} else {
+		stat.Birthtim = modified
+	}
+}
+
+// errno converts errors of alist to negative errno values expected by fuse
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	cause := errors.Cause(err)
+	switch {
+	case errs.IsNotFoundError(err):
+		return -fuse.ENOENT
+	case errors.Is(cause, errs.PermissionDenied):
+		return -fuse.EACCES
+	case errors.Is(cause, errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(cause, errs.NotFile):
+		return -fuse.EISDIR
+	case errors.Is(cause, errs.UploadNotSupported):
+		return -fuse.EROFS
+	case errors.Is(cause, errs.MoveBetweenTwoStorages):
+		// mv copies and deletes instead
+		return -fuse.EXDEV
+	case errs.IsNotSupportError(err), errs.IsNotImplement(err):
+		return -fuse.ENOSYS
+	case errors.Is(cause, context.Canceled):
+		return -fuse.EINTR
+	}
+	utils.Log.Errorf("fuse: %+v", err)
+	return -fuse.EIO
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,207 @@
+package fuse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// fileHandle is an opened file. Reads are served by range requests against the storage,
+// writes go to a temp file which is uploaded when the handle is flushed.
+type fileHandle struct {
+	mu        sync.Mutex
+	path      string
+	obj       model.Obj // nil if the file does not exist in the storage yet
+	writable  bool
+	truncated bool // the content starts empty instead of being copied from the storage
+	reader    stream.SStreamReadAtSeeker
+	tmp       *os.File
+	dirty     bool
+}
+
+func newFileHandle(path string, obj model.Obj) *fileHandle {
+	return &fileHandle{path: path, obj: obj}
+}
+
+// pendingSize returns the size of the content which has not been uploaded yet
+func (h *fileHandle) pendingSize() (int64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tmp == nil || !h.dirty {
+		return 0, false
+	}
+	info, err := h.tmp.Stat()
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
+func (h *fileHandle) openReader(ctx context.Context) error {
+	if h.reader != nil {
+		return nil
+	}
+	link, obj, err := fs.Link(ctx, h.path, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return err
+	}
+	reader, err := stream.NewReadAtSeeker(ss, 0, true)
+	if err != nil {
+		_ = ss.Close()
+		return err
+	}
+	h.obj = obj
+	h.reader = reader
+	return nil
+}
+
+func (h *fileHandle) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tmp != nil {
+		n, err := h.tmp.ReadAt(p, off)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		return n, err
+	}
+	if h.obj == nil || off >= h.obj.GetSize() {
+		return 0, nil
+	}
+	if err := h.openReader(ctx); err != nil {
+		return 0, err
+	}
+	if remain := h.obj.GetSize() - off; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := h.reader.ReadAt(p, off)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// prepareWrite creates the temp file holding the content to be uploaded
+func (h *fileHandle) prepareWrite(ctx context.Context) error {
+	if h.tmp != nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return err
+	}
+	if h.truncated || h.obj == nil || h.obj.GetSize() == 0 {
+		h.tmp = tmp
+		h.dirty = h.truncated
+		return nil
+	}
+	if err = h.openReader(ctx); err == nil {
+		_, err = utils.CopyWithBuffer(tmp, io.NewSectionReader(h.reader, 0, h.obj.GetSize()))
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	h.tmp = tmp
+	return nil
+}
+
+func (h *fileHandle) writeAt(ctx context.Context, p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.prepareWrite(ctx); err != nil {
+		return 0, err
+	}
+	n, err := h.tmp.WriteAt(p, off)
+	if n > 0 {
+		h.dirty = true
+	}
+	return n, err
+}
+
+func (h *fileHandle) truncate(ctx context.Context, size int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if size == 0 {
+		h.truncated = true
+	}
+	if err := h.prepareWrite(ctx); err != nil {
+		return err
+	}
+	if err := h.tmp.Truncate(size); err != nil {
+		return err
+	}
+	h.dirty = true
+	return nil
+}
+
+// flush uploads the written content, the temp file is handed over to the upload stream
+func (h *fileHandle) flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tmp == nil || !h.dirty {
+		return nil
+	}
+	size, err := h.tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err = h.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	dir, name := stdpath.Split(h.path)
+	obj := &model.Object{
+		Name:     name,
+		Size:     size,
+		Modified: time.Now(),
+	}
+	s := &stream.FileStream{
+		Obj:      obj,
+		Mimetype: utils.GetMimeType(name),
+		Ctx:      ctx,
+	}
+	s.SetTmpFile(h.tmp)
+	h.tmp = nil
+	h.dirty = false
+	h.truncated = false
+	if h.reader != nil {
+		_ = h.reader.Close()
+		h.reader = nil
+	}
+	h.obj = obj
+	return fs.PutDirectly(ctx, dir, s)
+}
+
+func (h *fileHandle) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var errList []error
+	if h.reader != nil {
+		errList = append(errList, h.reader.Close())
+		h.reader = nil
+	}
+	if h.tmp != nil {
+		errList = append(errList, h.tmp.Close(), os.Remove(h.tmp.Name()))
+		h.tmp = nil
+	}
+	return errors.Join(errList...)
+}
+
+func (h *fileHandle) release(ctx context.Context) error {
+	return errors.Join(h.flush(ctx), h.close())
+}
@@ -1,9 +1,19 @@
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/winfsp/cgofuse/fuse"
+)
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
-	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+// NewHost creates a fuse host serving the alist path mountSrc as user
+func NewHost(mountSrc string, user *model.User) *fuse.FileSystemHost {
+	host := fuse.NewFileSystemHost(NewFs(mountSrc, user))
+	host.SetCapReaddirPlus(true)
+	return host
+}
+
+// Mount serves mountSrc at mountDst and blocks until it is unmounted,
+// it returns false if the file system could not be mounted.
+func Mount(mountSrc, mountDst string, user *model.User, opts []string) bool {
+	return NewHost(mountSrc, user).Mount(mountDst, opts)
 }
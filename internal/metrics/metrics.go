@@ -0,0 +1,90 @@
+// Package metrics collects the runtime statistics of alist and exports them in the prometheus format
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "alist"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	driverRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "driver_requests_total",
+		Help:      "Number of requests sent to the storage drivers.",
+	}, []string{"driver", "op", "result"})
+	driverRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "driver_request_duration_seconds",
+		Help:      "Latency of the requests sent to the storage drivers.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"driver", "op"})
+	listCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_cache_requests_total",
+		Help:      "Number of lookups in the list cache.",
+	}, []string{"result"})
+	servedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "served_bytes_total",
+		Help:      "Bytes of file content proxied to the clients.",
+	}, []string{"route"})
+	rateLimitWait = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_wait_seconds_total",
+		Help:      "Time spent waiting for the stream rate limiters.",
+	}, []string{"limiter"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		driverRequests,
+		driverRequestDuration,
+		listCacheRequests,
+		servedBytes,
+		rateLimitWait,
+		tasks,
+	)
+}
+
+// Handler serves the collected metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveDriverRequest records a request sent to the driver, call it when the request is done
+func ObserveDriverRequest(driver, op string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	driverRequests.WithLabelValues(driver, op, result).Inc()
+	driverRequestDuration.WithLabelValues(driver, op).Observe(time.Since(start).Seconds())
+}
+
+func ListCacheHit() {
+	listCacheRequests.WithLabelValues("hit").Inc()
+}
+
+func ListCacheMiss() {
+	listCacheRequests.WithLabelValues("miss").Inc()
+}
+
+func AddServedBytes(route string, n int64) {
+	if n > 0 {
+		servedBytes.WithLabelValues(route).Add(float64(n))
+	}
+}
+
+func ObserveRateLimitWait(limiter string, d time.Duration) {
+	rateLimitWait.WithLabelValues(limiter).Add(d.Seconds())
+}
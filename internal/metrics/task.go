@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xhofe/tache"
+)
+
+var stateNames = map[tache.State]string{
+	tache.StatePending:      "pending",
+	tache.StateRunning:      "running",
+	tache.StateSucceeded:    "succeeded",
+	tache.StateCanceling:    "canceling",
+	tache.StateCanceled:     "canceled",
+	tache.StateErrored:      "errored",
+	tache.StateFailing:      "failing",
+	tache.StateFailed:       "failed",
+	tache.StateWaitingRetry: "waiting_retry",
+	tache.StateBeforeRetry:  "before_retry",
+}
+
+// taskCollector reads the states of the tasks from the managers on every scrape
+type taskCollector struct {
+	mu       sync.RWMutex
+	managers map[string]func() []tache.State
+	depth    *prometheus.Desc
+	states   *prometheus.Desc
+}
+
+var tasks = &taskCollector{
+	managers: make(map[string]func() []tache.State),
+	depth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "task", "queue_depth"),
+		"Number of tasks waiting for a worker.", []string{"manager"}, nil),
+	states: prometheus.NewDesc(prometheus.BuildFQName(namespace, "task", "states"),
+		"Number of tasks in each state.", []string{"manager", "state"}, nil),
+}
+
+// RegisterTaskManager exports the tasks of the manager with the name, a manager registered again replaces the old one
+func RegisterTaskManager[T tache.Task](name string, manager task.Manager[T]) {
+	tasks.mu.Lock()
+	defer tasks.mu.Unlock()
+	tasks.managers[name] = func() []tache.State {
+		all := manager.GetAll()
+		states := make([]tache.State, len(all))
+		for i, t := range all {
+			states[i] = t.GetState()
+		}
+		return states
+	}
+}
+
+func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	ch <- c.states
+}
+
+func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, getStates := range c.managers {
+		counts := make(map[tache.State]int, len(stateNames))
+		for _, state := range getStates() {
+			counts[state]++
+		}
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(counts[tache.StatePending]), name)
+		for state, stateName := range stateNames {
+			ch <- prometheus.MustNewConstMetric(c.states, prometheus.GaugeValue, float64(counts[state]), name, stateName)
+		}
+	}
+}
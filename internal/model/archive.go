@@ -51,3 +51,12 @@ type ArchiveMetaProvider struct {
 	DriverProviding bool
 	Expiration      *time.Duration
 }
+
+// ArchiveTestEntry is the result of reading a file in the archive to the end,
+// the checksum of the file is verified if the archive format records it
+type ArchiveTestEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
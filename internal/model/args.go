@@ -70,10 +70,20 @@ type ArchiveListArgs struct {
 	Refresh bool
 }
 
+// the policies of the decompressed file conflicting with an existing one, overwrite if not set
+const (
+	ConflictOverwrite = "overwrite"
+	ConflictSkip      = "skip"
+	ConflictRename    = "rename"
+)
+
 type ArchiveDecompressArgs struct {
 	ArchiveInnerArgs
 	CacheFull     bool
 	PutIntoNewDir bool
+	// InnerPaths are decompressed in one pass instead of InnerPath if given, the paths can be glob patterns
+	InnerPaths     []string
+	ConflictPolicy string
 }
 
 type RangeReadCloserIF interface {
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Username  string    `json:"username" gorm:"index"`
+	IP        string    `json:"ip"`
+	DeviceKey string    `json:"device_key"`
+	Action    string    `json:"action" gorm:"index"`
+	Path      string    `json:"path" gorm:"type:text"`
+	DstPath   string    `json:"dst_path" gorm:"type:text"`
+	Detail    string    `json:"detail" gorm:"type:text"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error" gorm:"type:text"`
+	Bytes     int64     `json:"bytes"`
+	Created   time.Time `json:"created" gorm:"index"`
+}
+
+type AuditLogFilter struct {
+	Username string `json:"username" form:"username"`
+	Action   string `json:"action" form:"action"`
+	// Path matches the logs whose path or dst path is under it
+	Path    string `json:"path" form:"path"`
+	IP      string `json:"ip" form:"ip"`
+	Success *bool  `json:"success" form:"success"`
+	// Start and End are unix timestamps in seconds, 0 means no bound
+	Start int64 `json:"start" form:"start"`
+	End   int64 `json:"end" form:"end"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// IndexDir is the fingerprint of an indexed dir, the incremental index doesn't index the children again if it's unchanged
+type IndexDir struct {
+	Path string `json:"path" gorm:"primaryKey"`
+	// ModTime of the dir itself in the list of its parent, the subtree of a done dir is skipped
+	// if it's not zero and the dir is unchanged
+	ModTime    time.Time `json:"mod_time"`
+	ChildCount int       `json:"child_count"`
+	// Etag is the sha1 of the names, sizes and modified times of the children
+	Etag string `json:"etag"`
+	// Generation is the incremental index run which indexed the dir
+	Generation int64 `json:"generation"`
+	// Done is whether the whole subtree is indexed
+	Done bool `json:"done"`
+}
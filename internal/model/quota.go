@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// QuotaUsage is what a user stores under the base path
+type QuotaUsage struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	UsedBytes int64     `json:"used_bytes"`
+	UsedFiles int64     `json:"used_files"`
+	Updated   time.Time `json:"updated"`
+}
+
+type Quota struct {
+	LimitBytes int64 `json:"limit_bytes"` // 0 means no limit
+	LimitFiles int64 `json:"limit_files"` // 0 means no limit
+	UsedBytes  int64 `json:"used_bytes"`
+	UsedFiles  int64 `json:"used_files"`
+}
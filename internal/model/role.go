@@ -22,6 +22,12 @@ type Role struct {
 	PermissionScopes []PermissionEntry `json:"permission_scopes" gorm:"-"`
 	// RawPermission is the JSON representation of PermissionScopes stored in DB.
 	RawPermission string `json:"-" gorm:"type:text"`
+	// QuotaBytes and QuotaFiles limit the users of the role, 0 means no limit.
+	QuotaBytes int64 `json:"quota_bytes"`
+	QuotaFiles int64 `json:"quota_files"`
+	// MaxOfflineDownloads and MaxTransferSpeed (KB/s) limit the offline downloads of the users, 0 means no limit.
+	MaxOfflineDownloads int `json:"max_offline_downloads"`
+	MaxTransferSpeed    int `json:"max_transfer_speed"`
 }
 
 // BeforeSave GORM hook serializes PermissionScopes into RawPermission.
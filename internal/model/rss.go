@@ -0,0 +1,61 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RssFeed struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Name   string `json:"name" binding:"required"`
+	URL    string `json:"url" gorm:"type:text" binding:"required"`
+	// Interval is the minutes between the polls, 0 to poll manually only
+	Interval int `json:"interval"`
+	// Include and Exclude are the regexps matching the titles of the items, empty for no limit
+	Include string `json:"include"`
+	Exclude string `json:"exclude"`
+	// DstPath is relative to the base path of the user
+	DstPath      string     `json:"dst_path" gorm:"type:text" binding:"required"`
+	Tool         string     `json:"tool" binding:"required"`
+	DeletePolicy string     `json:"delete_policy"`
+	Disabled     bool       `json:"disabled"`
+	LastCheck    *time.Time `json:"last_check"`
+	LastResult   string     `json:"last_result" gorm:"type:text"`
+}
+
+const (
+	RssItemQueued = "queued"
+	RssItemFailed = "failed"
+)
+
+// RssItem is an item of the feed matched by the filters, the failed ones are retried in the next poll
+type RssItem struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	FeedID uint   `json:"feed_id" gorm:"uniqueIndex:idx_rss_item_guid"`
+	GUID   string `json:"guid" gorm:"type:text"`
+	// GUIDHash is the unique key instead of the GUID, which may be a long magnet URI
+	GUIDHash  string     `json:"-" gorm:"uniqueIndex:idx_rss_item_guid;size:64"`
+	Title     string     `json:"title" gorm:"type:text"`
+	URL       string     `json:"url" gorm:"type:text"`
+	Published *time.Time `json:"published"`
+	Status    string     `json:"status"`
+	TaskID    string     `json:"task_id"`
+	Error     string     `json:"error" gorm:"type:text"`
+	Created   time.Time  `json:"created"`
+}
+
+// RssGUIDHash returns the hex encoded sha256 of the guid
+func RssGUIDHash(guid string) string {
+	sum := sha256.Sum256([]byte(guid))
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeSave GORM hook sets GUIDHash by the GUID.
+func (i *RssItem) BeforeSave(tx *gorm.DB) error {
+	i.GUIDHash = RssGUIDHash(i.GUID)
+	return nil
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// the types of the scheduled jobs, see internal/scheduler for the args of them
+const (
+	JobIndexBuild     = "index_build"
+	JobIndexUpdate    = "index_update"
+	JobCopy           = "copy"
+	JobMove           = "move"
+	JobSync           = "sync"
+	JobReloadStorages = "reload_storages"
+	JobCleanTasks     = "clean_tasks"
+	JobCleanTemp      = "clean_temp"
+)
+
+type ScheduledJob struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required"`
+	// Cron is a standard cron expression with 5 fields, or a descriptor like @daily and @every 1h
+	Cron        string     `json:"cron" binding:"required"`
+	Type        string     `json:"type" binding:"required"`
+	Args        string     `json:"args" gorm:"type:text"` // json object
+	Disabled    bool       `json:"disabled"`
+	LastRun     *time.Time `json:"last_run"`
+	NextRun     *time.Time `json:"next_run"`
+	LastSuccess bool       `json:"last_success"`
+	LastResult  string     `json:"last_result" gorm:"type:text"`
+}
@@ -2,7 +2,12 @@ package model
 
 import (
 	"fmt"
+	stdpath "path"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type IndexProgress struct {
@@ -10,21 +15,57 @@ type IndexProgress struct {
 	IsDone       bool       `json:"is_done"`
 	LastDoneTime *time.Time `json:"last_done_time"`
 	Error        string     `json:"error"`
+	// Generation of the incremental index, it's resumed if the index isn't done
+	Generation int64 `json:"generation,omitempty"`
 }
 
+// the ways to match the name with the keywords
+const (
+	MatchKeywords = ""
+	MatchGlob     = "glob"
+	MatchRegex    = "regex"
+)
+
 type SearchReq struct {
 	Parent   string `json:"parent"`
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// Match is how the keywords are matched: all words contained in the name by default,
+	// the whole name matched by the glob, or the name containing a match of the regex
+	Match string `json:"match"`
+	// MinSize and MaxSize in bytes, 0 for no limit
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// ModifiedAfter and ModifiedBefore in unix seconds, 0 for no limit
+	ModifiedAfter  int64 `json:"modified_after"`
+	ModifiedBefore int64 `json:"modified_before"`
+	// Exts are the extensions without the dot, e.g. mp4
+	Exts []string `json:"exts"`
+	// Hash is any hash value of the file
+	Hash string `json:"hash"`
+	// OrderBy is one of name, size and modified
+	OrderBy        string `json:"order_by"`
+	OrderDirection string `json:"order_direction"`
+	// Access is what the user can see in the index, nil for everything
+	Access *SearchAccess `json:"-"`
 	PageReq
 }
 
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Ext      string    `json:"ext" gorm:"index"`
+	Mime     string    `json:"mime"`
+	// Hash is the json of the hash info, empty if the driver doesn't give any hash
+	Hash string `json:"hash"`
+	// Content is the text of the file, it's only indexed by the searchers supporting it and never returned
+	Content string `json:"content,omitempty" gorm:"-"`
+	// Highlights are the html snippets of the content matching the keywords
+	Highlights []string `json:"highlights,omitempty" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,7 +75,104 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
-	return nil
+	if p.MinSize < 0 || p.MaxSize < 0 || (p.MaxSize > 0 && p.MinSize > p.MaxSize) {
+		return fmt.Errorf("invalid size range")
+	}
+	if p.ModifiedAfter > 0 && p.ModifiedBefore > 0 && p.ModifiedAfter > p.ModifiedBefore {
+		return fmt.Errorf("invalid modified range")
+	}
+	if !utils.SliceContains([]string{"", "name", "size", "modified"}, p.OrderBy) {
+		return fmt.Errorf("order_by must be one of name, size and modified")
+	}
+	if !utils.SliceContains([]string{"", "asc", "desc"}, p.OrderDirection) {
+		return fmt.Errorf("order_direction must be asc or desc")
+	}
+	for i := range p.Exts {
+		p.Exts[i] = strings.ToLower(strings.TrimPrefix(p.Exts[i], "."))
+	}
+	_, err := p.NameMatcher()
+	return err
+}
+
+// NameMatcher returns the func matching the name with the keywords, for the searchers can't match them by themselves
+func (p *SearchReq) NameMatcher() (func(name string) bool, error) {
+	switch p.Match {
+	case MatchKeywords:
+		keywords := strings.Fields(strings.ToLower(p.Keywords))
+		return func(name string) bool {
+			name = strings.ToLower(name)
+			for _, keyword := range keywords {
+				if !strings.Contains(name, keyword) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case MatchGlob, MatchRegex:
+		if p.Match == MatchGlob {
+			if _, err := stdpath.Match(p.Keywords, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob: %w", err)
+			}
+		}
+		re, err := regexp.Compile(p.NamePattern())
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("match must be one of glob and regex, or empty for keywords")
+	}
+}
+
+// NamePattern returns the regex of the glob or the regex match, the glob is case-insensitive and matches the whole name
+func (p *SearchReq) NamePattern() string {
+	if p.Match != MatchGlob {
+		return p.Keywords
+	}
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	inClass := false
+	for _, r := range p.Keywords {
+		switch {
+		case inClass:
+			if r == ']' {
+				inClass = false
+			}
+			b.WriteRune(r)
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteString(".")
+		case r == '[':
+			inClass = true
+			b.WriteRune(r)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return strings.Replace(b.String(), "[!", "[^", -1)
+}
+
+// MatchFilters checks the node with the parent, the access and the filters except the name
+func (p *SearchReq) MatchFilters(node *SearchNode) bool {
+	if !utils.IsSubPath(p.Parent, node.Parent) || !p.Access.Allowed(node) {
+		return false
+	}
+	if p.Scope != 0 && node.IsDir != (p.Scope == 1) {
+		return false
+	}
+	if (p.MinSize > 0 && node.Size < p.MinSize) || (p.MaxSize > 0 && node.Size > p.MaxSize) {
+		return false
+	}
+	if (p.ModifiedAfter > 0 && node.Modified.Unix() < p.ModifiedAfter) ||
+		(p.ModifiedBefore > 0 && node.Modified.Unix() > p.ModifiedBefore) {
+		return false
+	}
+	if len(p.Exts) > 0 && !utils.SliceContains(p.Exts, node.Ext) {
+		return false
+	}
+	return p.Hash == "" || strings.Contains(node.Hash, p.Hash)
 }
 
 func (s *SearchNode) Type() string {
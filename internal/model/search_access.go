@@ -0,0 +1,73 @@
+package model
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// SearchAccess is what a user can see in the index, the searchers filter the nodes with it in the query
+// so that the total and the pages don't count the nodes the user can't see
+type SearchAccess struct {
+	// Roots are the paths the user can read, nil for all
+	Roots []string
+	// Denied are the dirs protected by the passwords the user hasn't given
+	Denied []DeniedDir
+	// Hidden reports whether the node or any of its parents is hidden from the user, nil if nothing is hidden.
+	// The hide rules are regexps which can't be matched by the searchers, so the nodes are checked in memory
+	Hidden func(parent, name string) bool
+}
+
+// DeniedDir denies the nodes in the dir, and in its sub dirs if Sub, except the sub dirs having their own meta
+type DeniedDir struct {
+	Path   string
+	Sub    bool
+	Except []string
+}
+
+// Restricted reports whether any node may be denied
+func (a *SearchAccess) Restricted() bool {
+	return a != nil && (a.Roots != nil || len(a.Denied) > 0 || a.Hidden != nil)
+}
+
+// Allowed checks the node with the roots, the denied dirs and the hide rules
+func (a *SearchAccess) Allowed(node *SearchNode) bool {
+	if a == nil {
+		return true
+	}
+	if a.Roots != nil {
+		nodePath := stdpath.Join(node.Parent, node.Name)
+		inRoots := false
+		for _, root := range a.Roots {
+			if utils.IsSubPath(root, nodePath) {
+				inRoots = true
+				break
+			}
+		}
+		if !inRoots {
+			return false
+		}
+	}
+	for _, denied := range a.Denied {
+		if denied.Denies(node.Parent) {
+			return false
+		}
+	}
+	return a.Hidden == nil || !a.Hidden(node.Parent, node.Name)
+}
+
+// Denies reports whether the nodes in the parent are denied
+func (d DeniedDir) Denies(parent string) bool {
+	if !d.Sub {
+		return utils.PathEqual(d.Path, parent)
+	}
+	if !utils.IsSubPath(d.Path, parent) {
+		return false
+	}
+	for _, except := range d.Except {
+		if utils.IsSubPath(except, parent) {
+			return false
+		}
+	}
+	return true
+}
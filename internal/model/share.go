@@ -0,0 +1,51 @@
+package model
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+)
+
+// Share gives people without an account access to a file or a folder
+type Share struct {
+	ID           string     `json:"id" gorm:"primaryKey;size:16"`
+	UserID       uint       `json:"user_id" gorm:"index"`
+	Path         string     `json:"path" gorm:"type:text"` // full path, the base path of the creator is joined
+	IsDir        bool       `json:"is_dir"`
+	PwdHash      string     `json:"-"` // empty means no password
+	Salt         string     `json:"-"`
+	HasPassword  bool       `json:"has_password"`
+	Expires      *time.Time `json:"expires"`       // nil means never expire
+	MaxDownloads int64      `json:"max_downloads"` // 0 means unlimited
+	Downloads    int64      `json:"downloads"`
+	Disabled     bool       `json:"disabled"` // revoked by the creator or admin
+	Remark       string     `json:"remark"`
+	Created      time.Time  `json:"created"`
+}
+
+func (s *Share) IsExpired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s *Share) IsDownloadLimitReached() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
+
+// SetPassword keeps the hash of the password, an empty password removes it
+func (s *Share) SetPassword(pwd string) {
+	if pwd == "" {
+		s.PwdHash, s.Salt, s.HasPassword = "", "", false
+		return
+	}
+	s.Salt = random.String(16)
+	s.PwdHash = TwoHashPwd(pwd, s.Salt)
+	s.HasPassword = true
+}
+
+func (s *Share) ValidatePassword(pwd string) bool {
+	if s.PwdHash == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(s.PwdHash), []byte(TwoHashPwd(pwd, s.Salt))) == 1
+}
@@ -19,6 +19,7 @@ type Storage struct {
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Trash
 }
 
 type Sort struct {
@@ -27,6 +28,11 @@ type Sort struct {
 	ExtractFolder  string `json:"extract_folder"`
 }
 
+type Trash struct {
+	TrashPolicy string `json:"trash_policy"` // delete_permanently, hidden_dir or trash_storage
+	TrashPath   string `json:"trash_path"`   // mount path of the trash dir, only used by trash_storage
+}
+
 type Proxy struct {
 	WebProxy     bool   `json:"web_proxy"`
 	WebdavPolicy string `json:"webdav_policy"`
@@ -57,3 +63,11 @@ func (p Proxy) WebdavProxy() bool {
 func (p Proxy) WebdavNative() bool {
 	return !p.Webdav302() && !p.WebdavProxy()
 }
+
+func (t Trash) TrashHiddenDir() bool {
+	return t.TrashPolicy == "hidden_dir"
+}
+
+func (t Trash) TrashStorage() bool {
+	return t.TrashPolicy == "trash_storage" && t.TrashPath != ""
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+const (
+	// SyncMirror makes the dst the same as the src
+	SyncMirror = "mirror"
+	// SyncCopyNew copies the src files missing in the dst, nothing is overwritten or deleted
+	SyncCopyNew = "copy_new"
+	// SyncBidirectional copies the changes of each side to the other, the newer file wins a conflict
+	SyncBidirectional = "bidirectional"
+)
+
+type SyncJob struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name" binding:"required"`
+	SrcPath string `json:"src_path" gorm:"type:text" binding:"required"`
+	DstPath string `json:"dst_path" gorm:"type:text" binding:"required"`
+	Mode    string `json:"mode" binding:"required"`
+	// PropagateDelete removes the dst objects missing in the src for mirror,
+	// and the objects deleted at one side since the last run for bidirectional
+	PropagateDelete bool `json:"propagate_delete"`
+	// Interval is the minutes between the scheduled runs, 0 to run manually only
+	Interval   int        `json:"interval"`
+	Disabled   bool       `json:"disabled"`
+	LastRun    *time.Time `json:"last_run"`
+	LastResult string     `json:"last_result" gorm:"type:text"`
+	// State keeps the objects at both sides after the last bidirectional run
+	State string `json:"-" gorm:"type:text"`
+}
+
+func (j *SyncJob) ValidMode() bool {
+	return j.Mode == SyncMirror || j.Mode == SyncCopyNew || j.Mode == SyncBidirectional
+}
+
+const (
+	SyncOpCopy      = "copy"       // copy the src file to the dst
+	SyncOpCopyBack  = "copy_back"  // copy the dst file to the src
+	SyncOpMkdir     = "mkdir"      // make the dir in the dst
+	SyncOpMkdirBack = "mkdir_back" // make the dir in the src
+	SyncOpDelete    = "delete"     // delete the object from the dst
+	SyncOpDeleteSrc = "delete_src" // delete the object from the src
+	SyncOpConflict  = "conflict"   // nothing is done, the user has to resolve it
+)
+
+// SyncAction is a change a sync task makes, Path is relative to the src and dst paths of the job
+type SyncAction struct {
+	Op     string `json:"op"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Done   bool   `json:"done"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// TrashDirName is the dir at the root of a storage keeping removed objects when its trash policy is hidden_dir,
+// it is never reached by the paths of the users
+const TrashDirName = ".alist-trash"
+
+// IsTrashPath reports whether the path is in a hidden trash dir, such a path is only reached through the trash api
+func IsTrashPath(path string) bool {
+	for _, elem := range strings.Split(path, "/") {
+		if elem == TrashDirName {
+			return true
+		}
+	}
+	return false
+}
+
+// TrashItem is an object moved into the trash by fs.Remove
+type TrashItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"index"`
+	Name       string    `json:"name"`
+	OriginPath string    `json:"origin_path" gorm:"type:text"` // mount path of the dir the object was removed from
+	TrashPath  string    `json:"trash_path" gorm:"type:text"`  // mount path of the dir holding the object in the trash
+	IsDir      bool      `json:"is_dir"`
+	Size       int64     `json:"size"`
+	Deleted    time.Time `json:"deleted" gorm:"index"`
+}
+
+func (t *TrashItem) Obj() Obj {
+	return &Object{Name: t.Name, Size: t.Size, IsFolder: t.IsDir}
+}
@@ -53,6 +53,12 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	QuotaBytes int64  `json:"quota_bytes"` // max bytes under the base path, 0 to follow the roles
+	QuotaFiles int64  `json:"quota_files"` // max files under the base path, 0 to follow the roles
+	// MaxOfflineDownloads is the max offline downloads running at the same time, 0 to follow the roles
+	MaxOfflineDownloads int `json:"max_offline_downloads"`
+	// MaxTransferSpeed is the max speed in KB/s of the offline download transfers, 0 to follow the roles
+	MaxTransferSpeed int `json:"max_transfer_speed"`
 }
 
 func (u *User) IsGuest() bool {
@@ -152,6 +158,9 @@ func (u *User) JoinPath(reqPath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if IsTrashPath(path) {
+		return "", errs.PermissionDenied
+	}
 
 	if path != "/" && u.CheckPathLimit() {
 		basePaths := GetAllBasePathsFromRoles(u)
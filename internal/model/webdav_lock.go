@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// WebDAVLock is a LOCK of the webdav server kept in the database
+type WebDAVLock struct {
+	Token     string    `json:"token" gorm:"primaryKey;size:64"`
+	Root      string    `json:"root" gorm:"type:text"`
+	OwnerXML  string    `json:"owner_xml" gorm:"type:text"`
+	ZeroDepth bool      `json:"zero_depth"`
+	Duration  int64     `json:"duration"` // in nanoseconds, negative means infinite
+	Expiry    time.Time `json:"expiry"`
+}
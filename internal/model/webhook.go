@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Webhook struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Name       string `json:"name" binding:"required"`
+	URL        string `json:"url" gorm:"type:text" binding:"required"`
+	Secret     string `json:"secret"`                       // used to sign the payload, no signature if empty
+	Events     string `json:"events" gorm:"type:text"`      // comma separated events to send, all events if empty
+	PathPrefix string `json:"path_prefix" gorm:"type:text"` // only send the fs events under the path, all paths if empty, the task events aren't filtered
+	Disabled   bool   `json:"disabled"`
+}
+
+// Accept reports whether the event on the paths should be sent to the webhook,
+// the events without paths such as the task events ignore the PathPrefix
+func (w *Webhook) Accept(event string, paths ...string) bool {
+	if w.Disabled {
+		return false
+	}
+	if w.Events != "" && !utils.SliceContains(strings.Split(w.Events, ","), event) {
+		return false
+	}
+	if w.PathPrefix == "" {
+		return true
+	}
+	hasPath := false
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if utils.IsSubPath(w.PathPrefix, path) {
+			return true
+		}
+		hasPath = true
+	}
+	return !hasPath
+}
+
+// WebhookDelivery is the log of sending an event to a webhook
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload" gorm:"type:text"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code"`
+	Response   string    `json:"response" gorm:"type:text"`
+	Error      string    `json:"error" gorm:"type:text"`
+	Success    bool      `json:"success"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
@@ -3,7 +3,9 @@ package aria2
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -64,6 +66,14 @@ func (a *Aria2) AddURL(args *tool.AddUrlArgs) (string, error) {
 	options := map[string]interface{}{
 		"dir": args.TempDir,
 	}
+	// the option is applied to the torrent downloaded from the url or the magnet too
+	if len(args.SelectedFiles) > 0 {
+		indexes := make([]string, len(args.SelectedFiles))
+		for i, index := range args.SelectedFiles {
+			indexes[i] = strconv.Itoa(index)
+		}
+		options["select-file"] = strings.Join(indexes, ",")
+	}
 	gid, err := a.client.AddURI([]string{args.Url}, options)
 	if err != nil {
 		return "", err
@@ -72,6 +82,57 @@ func (a *Aria2) AddURL(args *tool.AddUrlArgs) (string, error) {
 	return gid, nil
 }
 
+// Resolve pauses the download following the metadata or the torrent file, then gets its files
+func (a *Aria2) Resolve(ctx context.Context, args *tool.AddUrlArgs) ([]tool.TorrentFile, error) {
+	gid, err := a.client.AddURI([]string{args.Url}, map[string]interface{}{
+		"dir":            args.TempDir,
+		"pause-metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+	gids := []string{gid}
+	defer func() {
+		for _, gid := range gids {
+			if _, err := a.client.ForceRemove(gid); err != nil {
+				log.Warnf("failed remove aria2 resolve download %s: %+v", gid, err)
+			}
+		}
+	}()
+	return tool.WaitMetadata(ctx, func() ([]tool.TorrentFile, error) {
+		info, err := a.client.TellStatus(gids[len(gids)-1])
+		if err != nil {
+			return nil, err
+		}
+		if info.Status == "error" {
+			return nil, errors.Errorf("failed to resolve %s, error: %s", args.Url, info.ErrorMessage)
+		}
+		if len(info.FollowedBy) != 0 {
+			gids = append(gids, info.FollowedBy[0])
+			return nil, nil
+		}
+		// the download of the torrent is followed after the metadata or the torrent file is downloaded
+		if len(gids) == 1 {
+			return nil, nil
+		}
+		files, err := a.client.GetFiles(gids[len(gids)-1])
+		if err != nil {
+			return nil, err
+		}
+		res := make([]tool.TorrentFile, 0, len(files))
+		for _, f := range files {
+			index, _ := strconv.Atoi(f.Index)
+			size, _ := strconv.ParseInt(f.Length, 10, 64)
+			rel, err := filepath.Rel(args.TempDir, f.Path)
+			if err != nil {
+				rel = f.Path
+			}
+			res = append(res, tool.TorrentFile{Index: index, Path: filepath.ToSlash(rel), Size: size})
+		}
+		return res, nil
+	})
+}
+
 func (a *Aria2) Remove(task *tool.DownloadTask) error {
 	_, err := a.client.Remove(task.GID)
 	return err
@@ -121,8 +182,25 @@ func (a *Aria2) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
-var _ tool.Tool = (*Aria2)(nil)
+var _ tool.TorrentTool = (*Aria2)(nil)
 
 func init() {
 	tool.Tools.Add(&Aria2{})
 }
+
+func (a *Aria2) SetSpeedLimit(download, upload int) error {
+	_, err := a.client.ChangeGlobalOption(rpc.Option{
+		"max-overall-download-limit": aria2Speed(download),
+		"max-overall-upload-limit":   aria2Speed(upload),
+	})
+	return errors.Wrap(err, "failed set aria2 speed limit")
+}
+
+// aria2Speed returns the speed in KB/s for aria2, where 0 means no limit
+func aria2Speed(speed int) string {
+	if speed < 0 {
+		return "0"
+	}
+	// 0 blocks the downloads of the server, the least speed is used instead
+	return strconv.Itoa(max(speed, 1)) + "K"
+}
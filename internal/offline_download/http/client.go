@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,9 +10,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
 )
 
 type SimpleHttp struct {
@@ -23,7 +27,9 @@ func (s SimpleHttp) Name() string {
 }
 
 func (s SimpleHttp) Items() []model.SettingItem {
-	return nil
+	return []model.SettingItem{
+		{Key: conf.SimpleHttpConnections, Value: "4", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
 }
 
 func (s SimpleHttp) Init() (string, error) {
@@ -46,6 +52,8 @@ func (s SimpleHttp) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	panic("should not be called")
 }
 
+// Run downloads the url to the temp dir by the range requests in parallel if the server supports them,
+// the parts downloaded are recorded in the temp dir, so that the download is resumed after retry or restart
 func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 	u := task.Url
 	// parse url
@@ -53,10 +61,13 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 	if err != nil {
 		return err
 	}
+	_ = os.MkdirAll(task.TempDir, os.ModePerm)
 	req, err := http.NewRequestWithContext(task.Ctx(), http.MethodGet, u, nil)
 	if err != nil {
 		return err
 	}
+	// the range tells whether the server supports the range requests
+	req.Header.Set("Range", "bytes=0-")
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return err
@@ -65,27 +76,57 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 	if resp.StatusCode >= 400 {
 		return fmt.Errorf("http status code %d", resp.StatusCode)
 	}
-	// If Path is empty, use Hostname; otherwise, filePath euqals TempDir which causes os.Create to fail
-	urlPath := _u.Path
-	if urlPath == "" {
-		urlPath = strings.ReplaceAll(_u.Host, ".", "_")
+	remote := newDownloadState(u, resp)
+	st, err := loadDownloadState(task.TempDir)
+	if err != nil || !st.sameAs(remote) {
+		if st != nil {
+			log.Infof("the remote file of %s is changed, download it again", u)
+		}
+		// the temp dir is only for this task
+		if err := resetTempDir(task.TempDir); err != nil {
+			return err
+		}
+		st = remote
+		// If Path is empty, use Hostname; otherwise, filePath euqals TempDir which causes os.Create to fail
+		urlPath := _u.Path
+		if urlPath == "" {
+			urlPath = strings.ReplaceAll(_u.Host, ".", "_")
+		}
+		st.Name = path.Base(urlPath)
+		if n, err := parseFilenameFromContentDisposition(resp.Header.Get("Content-Disposition")); err == nil {
+			st.Name = n
+		}
 	}
-	filename := path.Base(urlPath)
-	if n, err := parseFilenameFromContentDisposition(resp.Header.Get("Content-Disposition")); err == nil {
-		filename = n
+	filePath := filepath.Join(task.TempDir, st.Name)
+	task.SetTotalBytes(st.Size)
+	if st.ranged() {
+		resp.Body.Close()
+		connections := setting.GetInt(conf.SimpleHttpConnections, 4)
+		err = s.downloadParts(task.Ctx(), st, task.TempDir, connections, task.SetProgress)
+	} else {
+		err = s.downloadStream(task.Ctx(), resp, filePath, task.SetProgress)
 	}
-	// save to temp dir
-	_ = os.MkdirAll(task.TempDir, os.ModePerm)
-	filePath := filepath.Join(task.TempDir, filename)
+	if err != nil {
+		return err
+	}
+	if task.Checksum != "" {
+		if err := verifyChecksum(filePath, task.Checksum); err != nil {
+			// download again on retry
+			_ = resetTempDir(task.TempDir)
+			return err
+		}
+	}
+	// the state isn't transferred with the file
+	return removeDownloadState(task.TempDir)
+}
+
+func (s SimpleHttp) downloadStream(ctx context.Context, resp *http.Response, filePath string, progress func(float64)) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	fileSize := resp.ContentLength
-	task.SetTotalBytes(fileSize)
-	err = utils.CopyWithCtx(task.Ctx(), file, resp.Body, fileSize, task.SetProgress)
-	return err
+	return utils.CopyWithCtx(ctx, file, resp.Body, resp.ContentLength, progress)
 }
 
 func init() {
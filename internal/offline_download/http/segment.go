@@ -0,0 +1,271 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/net"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/pkg/errgroup"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/avast/retry-go"
+	"github.com/pkg/errors"
+)
+
+// stateFile records the parts downloaded in the temp dir, it's removed before the transfer
+const stateFile = ".alist_download.json"
+
+var errRemoteChanged = errors.New("the remote file is changed while downloading")
+
+// downloadState is the remote file and the parts downloaded of it
+type downloadState struct {
+	URL          string `json:"url"`
+	Name         string `json:"name"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+	// Ranged is whether the server supports the range requests, the download can't be resumed if not
+	Ranged   bool   `json:"ranged"`
+	PartSize int64  `json:"part_size"`
+	Done     []bool `json:"done"`
+}
+
+func newDownloadState(url string, resp *http.Response) *downloadState {
+	st := &downloadState{
+		URL:          url,
+		Size:         resp.ContentLength,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		if size, ok := parseContentRangeSize(resp.Header.Get("Content-Range")); ok {
+			st.Size, st.Ranged = size, true
+		}
+	}
+	if st.Ranged && st.Size > 0 {
+		st.PartSize = net.DefaultDownloadPartSize
+		st.Done = make([]bool, (st.Size+st.PartSize-1)/st.PartSize)
+	}
+	return st
+}
+
+// sameAs reports whether the state is of the same remote file, by the length and the validators
+func (s *downloadState) sameAs(remote *downloadState) bool {
+	return s != nil && s.URL == remote.URL && s.Size == remote.Size && s.ETag == remote.ETag &&
+		s.LastModified == remote.LastModified && s.Ranged == remote.Ranged && s.Name != ""
+}
+
+func (s *downloadState) ranged() bool {
+	return s.Ranged && s.Size > 0
+}
+
+// part returns the range of the i-th part
+func (s *downloadState) part(i int) http_range.Range {
+	start := int64(i) * s.PartSize
+	return http_range.Range{Start: start, Length: min(s.PartSize, s.Size-start)}
+}
+
+// ifRange returns the validator for If-Range, so that the parts of the changed file are never mixed
+func (s *downloadState) ifRange() string {
+	if s.ETag != "" && !strings.HasPrefix(s.ETag, "W/") {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+func loadDownloadState(dir string) (*downloadState, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	var st downloadState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func saveDownloadState(dir string, st *downloadState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// write and rename, so that a crash never leaves a broken state
+	tmp := filepath.Join(dir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, filepath.Join(dir, stateFile)))
+}
+
+func removeDownloadState(dir string) error {
+	err := os.Remove(filepath.Join(dir, stateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func resetTempDir(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.MkdirAll(dir, os.ModePerm))
+}
+
+// parseContentRangeSize returns the complete length in the Content-Range like bytes 0-99/1000
+func parseContentRangeSize(s string) (int64, bool) {
+	if _, _, err := http_range.ParseContentRange(s); err != nil {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(s[strings.Index(s, "/")+1:], 10, 64)
+	return size, err == nil && size > 0
+}
+
+// downloadParts downloads the parts not done yet by the range requests in parallel, each part is saved once it's done
+func (s SimpleHttp) downloadParts(ctx context.Context, st *downloadState, dir string, connections int, progress func(float64)) error {
+	if err := saveDownloadState(dir, st); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, st.Name), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+	if err := file.Truncate(st.Size); err != nil {
+		return errors.WithStack(err)
+	}
+	var (
+		mu         sync.Mutex
+		downloaded int64
+	)
+	addDownloaded := func(n int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		downloaded += n
+		progress(float64(downloaded) / float64(st.Size) * 100)
+	}
+	for i, done := range st.Done {
+		if done {
+			addDownloaded(st.part(i).Length)
+		}
+	}
+	g, ctx := errgroup.NewGroupWithContext(ctx, max(connections, 1),
+		retry.Attempts(3),
+		retry.LastErrorOnly(true),
+		retry.Delay(time.Second),
+		retry.DelayType(retry.BackOffDelay))
+	for i, done := range st.Done {
+		if done {
+			continue
+		}
+		if utils.IsCanceled(ctx) {
+			break
+		}
+		i := i
+		g.Go(func(ctx context.Context) error {
+			var written int64
+			err := s.downloadPart(ctx, st, st.part(i), io.NewOffsetWriter(file, st.part(i).Start), func(n int64) {
+				written += n
+				addDownloaded(n)
+			})
+			if err != nil {
+				// the part is downloaded again on retry
+				addDownloaded(-written)
+				if errors.Is(err, errRemoteChanged) {
+					return retry.Unrecoverable(err)
+				}
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			st.Done[i] = true
+			return saveDownloadState(dir, st)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, errRemoteChanged) {
+			// start over on the next retry
+			_ = removeDownloadState(dir)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s SimpleHttp) downloadPart(ctx context.Context, st *downloadState, r http_range.Range, w io.Writer, written func(int64)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, st.URL, nil)
+	if err != nil {
+		return err
+	}
+	http_range.ApplyRangeToHttpHeader(r, req.Header)
+	if v := st.ifRange(); v != "" {
+		req.Header.Set("If-Range", v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		// the whole file is returned if the If-Range doesn't match
+		return errRemoteChanged
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("http status code %d", resp.StatusCode)
+	}
+	start, end, err := http_range.ParseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil || start != r.Start || end != r.Start+r.Length-1 {
+		return fmt.Errorf("unexpected content range %s", resp.Header.Get("Content-Range"))
+	}
+	if size, ok := parseContentRangeSize(resp.Header.Get("Content-Range")); !ok || size != st.Size {
+		return errRemoteChanged
+	}
+	n, err := utils.CopyWithBuffer(w, readerFunc(func(p []byte) (int, error) {
+		n, err := resp.Body.Read(p)
+		written(int64(n))
+		return n, err
+	}))
+	if err != nil {
+		return err
+	}
+	if n != r.Length {
+		return fmt.Errorf("part %d-%d is short, %d bytes read", r.Start, r.Start+r.Length-1, n)
+	}
+	return nil
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (rf readerFunc) Read(p []byte) (int, error) { return rf(p) }
+
+// verifyChecksum checks the file by the checksum like sha256:<hex>
+func verifyChecksum(filePath, checksum string) error {
+	ht, sum, err := tool.ParseChecksum(checksum)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+	got, err := utils.HashFile(ht, file)
+	if err != nil {
+		return err
+	}
+	if got != sum {
+		return errors.Errorf("%s checksum mismatch, expected %s, got %s", ht.Name, sum, got)
+	}
+	return nil
+}
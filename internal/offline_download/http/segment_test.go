@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/net"
+	"github.com/pkg/errors"
+)
+
+func TestDownloadParts(t *testing.T) {
+	content := make([]byte, net.DefaultDownloadPartSize*2+1024)
+	rand.New(rand.NewSource(1)).Read(content)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	s := SimpleHttp{}
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// the server doesn't support the range without the range header
+	if newDownloadState(server.URL, resp).ranged() {
+		t.Fatalf("the state of the 200 response shouldn't be ranged")
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Range", "bytes=0-")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	st := newDownloadState(server.URL, resp)
+	if !st.ranged() || st.Size != int64(len(content)) || len(st.Done) != 3 {
+		t.Fatalf("unexpected state %+v", st)
+	}
+	st.Name = "file.bin"
+
+	// the first part is downloaded before, only the others are requested
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, st.Name), content[:st.PartSize], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st.Done[0] = true
+	requests.Store(0)
+	var progress float64
+	if err := s.downloadParts(context.Background(), st, dir, 2, func(p float64) { progress = p }); err != nil {
+		t.Fatalf("failed download parts: %+v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d parts are requested, want 2", n)
+	}
+	if progress != 100 {
+		t.Errorf("progress is %f, want 100", progress)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, st.Name))
+	if !bytes.Equal(got, content) {
+		t.Errorf("the file downloaded is different from the content")
+	}
+	saved, err := loadDownloadState(dir)
+	if err != nil || !saved.sameAs(st) || !saved.Done[1] || !saved.Done[2] {
+		t.Errorf("unexpected state saved %+v: %v", saved, err)
+	}
+
+	// the parts of the changed file are never mixed
+	st.ETag = `"v0"`
+	st.Done = make([]bool, len(st.Done))
+	err = s.downloadParts(context.Background(), st, dir, 2, func(float64) {})
+	if !errors.Is(err, errRemoteChanged) {
+		t.Errorf("the error is %v, want %v", err, errRemoteChanged)
+	}
+}
@@ -1,17 +1,24 @@
 package qbit
 
 import (
+	"context"
+	"sync"
+
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/qbittorrent"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type QBittorrent struct {
 	client qbittorrent.Client
+	// selected are the gids whose files are selected, the files are known after the metadata is downloaded
+	selected sync.Map
 }
 
 func (a *QBittorrent) Run(task *tool.DownloadTask) error {
@@ -53,7 +60,62 @@ func (a *QBittorrent) AddURL(args *tool.AddUrlArgs) (string, error) {
 	return args.UID, nil
 }
 
+func (a *QBittorrent) Resolve(ctx context.Context, args *tool.AddUrlArgs) ([]tool.TorrentFile, error) {
+	if err := a.client.AddFromLink(args.Url, args.TempDir, args.UID); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := a.client.Delete(args.UID, true); err != nil {
+			log.Warnf("failed remove qBittorrent resolve task %s: %+v", args.UID, err)
+		}
+	}()
+	return tool.WaitMetadata(ctx, func() ([]tool.TorrentFile, error) {
+		files, err := a.client.GetFiles(args.UID)
+		// the task may not be listed right after it's added
+		if errors.As(err, new(qbittorrent.InfoNotFoundError)) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res := make([]tool.TorrentFile, len(files))
+		for i, f := range files {
+			res[i] = tool.TorrentFile{Index: f.Index, Path: f.Name, Size: f.Size}
+		}
+		return res, nil
+	})
+}
+
+// selectFiles stops downloading the files not selected once the files are known
+func (a *QBittorrent) selectFiles(task *tool.DownloadTask) error {
+	selected := task.SelectedFileIndexes()
+	if len(selected) == 0 {
+		return nil
+	}
+	if _, ok := a.selected.Load(task.GID); ok {
+		return nil
+	}
+	files, err := a.client.GetFiles(task.GID)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	var unselected []int
+	for _, f := range files {
+		if !utils.SliceContains(selected, f.Index) {
+			unselected = append(unselected, f.Index)
+		}
+	}
+	if len(unselected) > 0 {
+		if err = a.client.SetFilePriority(task.GID, unselected, 0); err != nil {
+			return err
+		}
+	}
+	a.selected.Store(task.GID, struct{}{})
+	return nil
+}
+
 func (a *QBittorrent) Remove(task *tool.DownloadTask) error {
+	a.selected.Delete(task.GID)
 	err := a.client.Delete(task.GID, false)
 	return err
 }
@@ -63,6 +125,11 @@ func (a *QBittorrent) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	if err != nil {
 		return nil, err
 	}
+	if info.State != qbittorrent.METADL {
+		if err = a.selectFiles(task); err != nil {
+			return nil, errors.WithMessage(err, "failed select files")
+		}
+	}
 	s := &tool.Status{}
 	s.TotalBytes = info.Size
 	s.Progress = float64(info.Completed) / float64(info.Size) * 100
@@ -79,8 +146,21 @@ func (a *QBittorrent) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
-var _ tool.Tool = (*QBittorrent)(nil)
+var _ tool.TorrentTool = (*QBittorrent)(nil)
 
 func init() {
 	tool.Tools.Add(&QBittorrent{})
 }
+
+func (a *QBittorrent) SetSpeedLimit(download, upload int) error {
+	return a.client.SetSpeedLimit(qbitSpeed(download), qbitSpeed(upload))
+}
+
+// qbitSpeed returns the speed in bytes/s for qBittorrent, where 0 means no limit
+func qbitSpeed(speed int) int64 {
+	if speed < 0 {
+		return 0
+	}
+	// 0 blocks the downloads of the server, the least speed is used instead
+	return int64(max(speed, 1)) * 1024
+}
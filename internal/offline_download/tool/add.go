@@ -5,6 +5,7 @@ import (
 	"net/url"
 	stdpath "path"
 	"path/filepath"
+	"strings"
 
 	_115 "github.com/alist-org/alist/v3/drivers/115"
 	"github.com/alist-org/alist/v3/drivers/pikpak"
@@ -16,6 +17,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -34,6 +36,28 @@ type AddURLArgs struct {
 	DstDirPath   string
 	Tool         string
 	DeletePolicy DeletePolicy
+	// SelectedFiles are the files resolved by ResolveURL to download, all files if empty
+	SelectedFiles []TorrentFile
+	// Checksum is the hash of the file like sha256:<hex> checked before the transfer, only for SimpleHttp
+	Checksum string
+}
+
+// ParseChecksum parses the checksum like sha256:<hex>, the hash types are the ones supported by utils
+func ParseChecksum(checksum string) (*utils.HashType, string, error) {
+	name, sum, ok := strings.Cut(checksum, ":")
+	if !ok {
+		return nil, "", errors.Errorf("invalid checksum %s, should be like sha256:<hex>", checksum)
+	}
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	for _, ht := range utils.Supported {
+		if strings.EqualFold(ht.Name, name) {
+			if len(sum) != ht.Width {
+				return nil, "", errors.Errorf("invalid %s checksum length %d", ht.Name, len(sum))
+			}
+			return ht, sum, nil
+		}
+	}
+	return nil, "", errors.Wrapf(utils.ErrUnsupported, "checksum %s", name)
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, error) {
@@ -58,8 +82,16 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 			return nil, errors.WithStack(errs.NotFolder)
 		}
 	}
-	// try putting url
-	if args.Tool == "SimpleHttp" {
+	if args.Checksum != "" {
+		if args.Tool != "SimpleHttp" {
+			return nil, errors.Errorf("tool %s can't check the checksum", args.Tool)
+		}
+		if _, _, err := ParseChecksum(args.Checksum); err != nil {
+			return nil, err
+		}
+	}
+	// try putting url, the url put by the storage can't be checked
+	if args.Tool == "SimpleHttp" && args.Checksum == "" {
 		err = tryPutUrl(ctx, args.DstDirPath, args.URL)
 		if err == nil || !errors.Is(err, errs.NotImplement) {
 			return nil, err
@@ -71,6 +103,9 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed get tool")
 	}
+	if _, ok := tool.(TorrentTool); !ok && len(args.SelectedFiles) > 0 {
+		return nil, errors.Errorf("tool %s can't select the torrent files", args.Tool)
+	}
 	// check tool is ready
 	if !tool.IsReady() {
 		// try to init tool
@@ -110,14 +145,16 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 		TaskExtension: task.TaskExtension{
 			Creator: taskCreator,
 		},
-		Url:          args.URL,
-		DstDirPath:   args.DstDirPath,
-		TempDir:      tempDir,
-		DeletePolicy: deletePolicy,
-		Toolname:     args.Tool,
-		tool:         tool,
+		Url:           args.URL,
+		DstDirPath:    args.DstDirPath,
+		TempDir:       tempDir,
+		DeletePolicy:  deletePolicy,
+		Toolname:      args.Tool,
+		SelectedFiles: args.SelectedFiles,
+		Checksum:      args.Checksum,
+		tool:          tool,
 	}
-	DownloadTaskManager.Add(t)
+	addDownloadTask(t)
 	return t, nil
 }
 
@@ -9,6 +9,8 @@ type AddUrlArgs struct {
 	UID     string
 	TempDir string
 	Signal  chan int
+	// SelectedFiles are the indexes of the torrent files to download, all files if empty
+	SelectedFiles []int
 }
 
 type Status struct {
@@ -36,3 +38,10 @@ type Tool interface {
 	// Run for simple http download
 	Run(task *DownloadTask) error
 }
+
+// SpeedLimitTool is the tool whose speeds follow the server speeds, which may change by the bandwidth schedule
+type SpeedLimitTool interface {
+	Tool
+	// SetSpeedLimit sets the global speeds in KB/s of the tool, -1 means no limit
+	SetSpeedLimit(download, upload int) error
+}
@@ -2,6 +2,8 @@ package tool
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
@@ -15,14 +17,20 @@ import (
 
 type DownloadTask struct {
 	task.TaskExtension
-	Url               string       `json:"url"`
-	DstDirPath        string       `json:"dst_dir_path"`
-	TempDir           string       `json:"temp_dir"`
-	DeletePolicy      DeletePolicy `json:"delete_policy"`
-	Toolname          string       `json:"toolname"`
-	Status            string       `json:"-"`
-	Signal            chan int     `json:"-"`
-	GID               string       `json:"-"`
+	Url          string       `json:"url"`
+	DstDirPath   string       `json:"dst_dir_path"`
+	TempDir      string       `json:"temp_dir"`
+	DeletePolicy DeletePolicy `json:"delete_policy"`
+	Toolname     string       `json:"toolname"`
+	// SelectedFiles are the torrent files to download and transfer, all files if empty
+	SelectedFiles []TorrentFile `json:"selected_files,omitempty"`
+	// Checksum is checked by the tool before the transfer, see ParseChecksum
+	Checksum string `json:"checksum,omitempty"`
+	// QueuedAt is when the task is queued for the limit of the offline downloads of the user, zero if it isn't queued
+	QueuedAt          time.Time `json:"queued_at,omitempty"`
+	Status            string    `json:"-"`
+	Signal            chan int  `json:"-"`
+	GID               string    `json:"-"`
 	tool              Tool
 	callStatusRetried int
 }
@@ -30,6 +38,8 @@ type DownloadTask struct {
 func (t *DownloadTask) Run() error {
 	t.ReinitCtx()
 	t.ClearEndTime()
+	// a queued task retried by hand isn't queued anymore
+	t.QueuedAt = time.Time{}
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
 	if t.tool == nil {
@@ -50,10 +60,11 @@ func (t *DownloadTask) Run() error {
 		t.Signal = nil
 	}()
 	gid, err := t.tool.AddURL(&AddUrlArgs{
-		Url:     t.Url,
-		UID:     t.ID,
-		TempDir: t.TempDir,
-		Signal:  t.Signal,
+		Url:           t.Url,
+		UID:           t.ID,
+		TempDir:       t.TempDir,
+		Signal:        t.Signal,
+		SelectedFiles: t.SelectedFileIndexes(),
 	})
 	if err != nil {
 		return err
@@ -166,7 +177,32 @@ func (t *DownloadTask) Transfer() error {
 		}
 		return nil
 	}
-	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy)
+	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy, t.selectedPaths())
+}
+
+// SelectedFileIndexes returns the indexes of the selected files for the tool, nil for all files
+func (t *DownloadTask) SelectedFileIndexes() []int {
+	if len(t.SelectedFiles) == 0 {
+		return nil
+	}
+	indexes := make([]int, len(t.SelectedFiles))
+	for i, f := range t.SelectedFiles {
+		indexes[i] = f.Index
+	}
+	return indexes
+}
+
+// selectedPaths returns the paths of the selected files in the temp dir, nil for all files,
+// the tools may leave the pieces of the other files there
+func (t *DownloadTask) selectedPaths() []string {
+	if len(t.SelectedFiles) == 0 {
+		return nil
+	}
+	paths := make([]string, len(t.SelectedFiles))
+	for i, f := range t.SelectedFiles {
+		paths[i] = strings.Trim(filepath.ToSlash(f.Path), "/")
+	}
+	return paths
 }
 
 func (t *DownloadTask) GetName() string {
@@ -0,0 +1,160 @@
+package tool
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// errQueued is the error of the queued downloads, they are kept errored so that the task manager doesn't run them
+var errQueued = errors.New("queued until the other offline downloads of the user finish")
+
+// downloadQueueMu makes the count of the running downloads of the user and the start of the queued ones atomic
+var downloadQueueMu sync.Mutex
+
+// addDownloadTask adds the task, which is queued if its creator already runs as many offline downloads as the limit.
+// The queued tasks don't hold the workers, they are started by startQueuedDownloads once the others finish.
+func addDownloadTask(t *DownloadTask) {
+	downloadQueueMu.Lock()
+	defer downloadQueueMu.Unlock()
+	if u := t.GetCreator(); u != nil {
+		if limit, _ := op.GetOfflineDownloadLimit(u); limit > 0 && len(runningDownloads(u.ID)) >= limit {
+			queue(t)
+		}
+	}
+	DownloadTaskManager.Add(t)
+}
+
+// queue sets the state without the state hooks, the task isn't errored for the webhooks
+func queue(t *DownloadTask) {
+	if t.QueuedAt.IsZero() {
+		t.QueuedAt = time.Now()
+	}
+	t.Base.SetState(tache.StateErrored)
+	t.SetErr(errQueued)
+	t.Status = errQueued.Error()
+}
+
+func runningDownloads(userID uint) []*DownloadTask {
+	return DownloadTaskManager.GetByCondition(func(d *DownloadTask) bool {
+		c := d.GetCreator()
+		return c != nil && c.ID == userID && d.QueuedAt.IsZero() && !utils.SliceContains([]tache.State{
+			tache.StateSucceeded, tache.StateCanceled, tache.StateFailed,
+		}, d.GetState())
+	})
+}
+
+// startQueuedDownloads starts the queued downloads of the user in the order they are added, as many as the limit allows
+func startQueuedDownloads(u *model.User) {
+	downloadQueueMu.Lock()
+	defer downloadQueueMu.Unlock()
+	queued := DownloadTaskManager.GetByCondition(func(d *DownloadTask) bool {
+		c := d.GetCreator()
+		return c != nil && c.ID == u.ID && !d.QueuedAt.IsZero()
+	})
+	if len(queued) == 0 {
+		return
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].QueuedAt.Before(queued[j].QueuedAt) })
+	limit, _ := op.GetOfflineDownloadLimit(u)
+	running := len(runningDownloads(u.ID))
+	for _, t := range queued {
+		if limit > 0 && running >= limit {
+			// the error isn't persisted, it's lost after a restart
+			queue(t)
+			continue
+		}
+		t.QueuedAt = time.Time{}
+		t.Status = ""
+		DownloadTaskManager.Retry(t.GetID())
+		running++
+	}
+}
+
+// StartQueuedDownloads starts the queued downloads of all users, it's called after the tasks are recovered
+func StartQueuedDownloads() {
+	users := make(map[uint]*model.User)
+	for _, t := range DownloadTaskManager.GetByCondition(func(d *DownloadTask) bool { return !d.QueuedAt.IsZero() }) {
+		if c := t.GetCreator(); c != nil {
+			users[c.ID] = c
+		}
+	}
+	for _, u := range users {
+		startQueuedDownloads(u)
+	}
+}
+
+// onDownloadState starts the queued downloads of the user once a download of the user finishes
+func onDownloadState(t *task.TaskExtension, state tache.State) {
+	if DownloadTaskManager == nil || t.Creator == nil ||
+		!utils.SliceContains([]tache.State{tache.StateSucceeded, tache.StateCanceled, tache.StateFailed}, state) {
+		return
+	}
+	if _, ok := DownloadTaskManager.GetByID(t.GetID()); !ok {
+		return
+	}
+	// the hook is called by the task manager, don't block it
+	go startQueuedDownloads(t.Creator)
+}
+
+func init() {
+	task.RegisterStateHook(onDownloadState)
+}
+
+type userLimiter struct {
+	speed int
+	stream.Limiter
+}
+
+// userLimiters are shared by the transfers of the same user, so that the speed is the total of them
+var userLimiters = struct {
+	sync.Mutex
+	m map[uint]*userLimiter
+}{m: make(map[uint]*userLimiter)}
+
+// transferLimiter returns the limiter of the transfers of the user, nil if the speed isn't limited
+func transferLimiter(u *model.User) stream.Limiter {
+	if u == nil {
+		return nil
+	}
+	_, speed := op.GetOfflineDownloadLimit(u)
+	userLimiters.Lock()
+	defer userLimiters.Unlock()
+	if speed <= 0 {
+		delete(userLimiters.m, u.ID)
+		return nil
+	}
+	l, ok := userLimiters.m[u.ID]
+	if !ok || l.speed != speed {
+		l = &userLimiter{speed: speed, Limiter: stream.NewLimiter(speed)}
+		userLimiters.m[u.ID] = l
+	}
+	return l
+}
+
+// limitLink returns a copy of the link whose reads are limited by l, the link may be cached so it isn't modified
+func limitLink(ctx context.Context, link *model.Link, size int64, l stream.Limiter) (*model.Link, error) {
+	limited := *link
+	switch {
+	case link.MFile != nil:
+		limited.MFile = &stream.RateLimitFile{File: link.MFile, Limiter: l, Ctx: ctx}
+	case link.RangeReadCloser != nil:
+		limited.RangeReadCloser = &stream.RateLimitRangeReadCloser{RangeReadCloserIF: link.RangeReadCloser, Limiter: l}
+	case len(link.URL) > 0:
+		rrc, err := stream.GetRangeReadCloserFromLink(size, link)
+		if err != nil {
+			return nil, err
+		}
+		limited.RangeReadCloser = &stream.RateLimitRangeReadCloser{RangeReadCloserIF: rrc, Limiter: l}
+	}
+	return &limited, nil
+}
@@ -0,0 +1,108 @@
+package tool
+
+import (
+	"context"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResolveTimeout is how long to wait for the metadata of the magnets, which are fetched from the peers
+const ResolveTimeout = 2 * time.Minute
+
+// TorrentFile is a file in the torrent, the index is given by the tool and passed back to select the file
+type TorrentFile struct {
+	Index int    `json:"index"`
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+}
+
+// TorrentTool is the tool which can resolve the files of the magnets and the torrents before downloading them
+type TorrentTool interface {
+	Tool
+	// Resolve returns the files of the torrent, the download added for the metadata is removed with its files
+	Resolve(ctx context.Context, args *AddUrlArgs) ([]TorrentFile, error)
+}
+
+// ResolveURL returns the files of the magnet or the torrent url by the tool
+func ResolveURL(ctx context.Context, toolName, url string) ([]TorrentFile, error) {
+	t, err := Tools.Get(toolName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get tool")
+	}
+	if !IsTorrentURL(url) {
+		return nil, errors.New("only the magnets and the torrent urls can be resolved")
+	}
+	resolver, ok := t.(TorrentTool)
+	if !ok {
+		return nil, errors.Errorf("tool %s can't resolve the torrent files", toolName)
+	}
+	if !t.IsReady() {
+		if _, err := t.Init(); err != nil {
+			return nil, errors.Wrapf(err, "failed init tool %s", toolName)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, ResolveTimeout)
+	defer cancel()
+	uid := uuid.NewString()
+	tempDir := filepath.Join(conf.Conf.TempDir, toolName, "resolve", uid)
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			log.Warnf("failed remove resolve temp dir %s: %+v", tempDir, err)
+		}
+	}()
+	files, err := resolver.Resolve(ctx, &AddUrlArgs{Url: url, UID: uid, TempDir: tempDir})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, errors.New("timeout waiting for the torrent metadata")
+	}
+	return files, err
+}
+
+// IsTorrentURL reports whether the url is a magnet or a url of the torrent file
+func IsTorrentURL(url string) bool {
+	if strings.HasPrefix(url, "magnet:") {
+		return true
+	}
+	u, err := neturl.Parse(url)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && strings.HasSuffix(strings.ToLower(u.Path), ".torrent")
+}
+
+// WaitMetadata calls get every second until it returns the files, an error or the ctx is done
+func WaitMetadata(ctx context.Context, get func() ([]TorrentFile, error)) ([]TorrentFile, error) {
+	for {
+		files, err := get()
+		if err != nil || len(files) > 0 {
+			return files, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// selectedIn returns the selected paths under the name, and whether anything under it is selected,
+// nil selected means all, and the paths returned are relative to the name
+func selectedIn(selected []string, name string) ([]string, bool) {
+	if selected == nil {
+		return nil, true
+	}
+	var sub []string
+	for _, p := range selected {
+		if p == name {
+			return nil, true
+		}
+		if rest, ok := strings.CutPrefix(p, name+"/"); ok {
+			sub = append(sub, rest)
+		}
+	}
+	return sub, len(sub) > 0
+}
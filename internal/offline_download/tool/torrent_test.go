@@ -0,0 +1,29 @@
+package tool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectedIn(t *testing.T) {
+	selected := []string{"Show/S01/e01.mkv", "Show/S01/e02.mkv", "Show/extra.nfo", "single.mkv"}
+	datas := []struct {
+		name string
+		sub  []string
+		ok   bool
+	}{
+		{"Show", []string{"S01/e01.mkv", "S01/e02.mkv", "extra.nfo"}, true},
+		{"single.mkv", nil, true},
+		{"Other", nil, false},
+		{"Sho", nil, false},
+	}
+	for _, data := range datas {
+		sub, ok := selectedIn(selected, data.name)
+		if ok != data.ok || !reflect.DeepEqual(sub, data.sub) {
+			t.Errorf("selectedIn(%s) = %v, %v, want %v, %v", data.name, sub, ok, data.sub, data.ok)
+		}
+	}
+	if sub, ok := selectedIn(nil, "any"); sub != nil || !ok {
+		t.Errorf("nil selected should select all")
+	}
+}
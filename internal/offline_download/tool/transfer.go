@@ -29,6 +29,8 @@ type TransferTask struct {
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
 	DeletePolicy DeletePolicy  `json:"delete_policy"`
+	// Selected are the paths relative to the src obj to transfer, all if nil
+	Selected []string `json:"selected,omitempty"`
 }
 
 func (t *TransferTask) Run() error {
@@ -75,7 +77,7 @@ var (
 	TransferTaskManager *tache.Manager[*TransferTask]
 )
 
-func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
+func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy, selected []string) error {
 	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
@@ -86,6 +88,10 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 	}
 	taskCreator, _ := ctx.Value("user").(*model.User)
 	for _, entry := range entries {
+		sub, ok := selectedIn(selected, entry.Name())
+		if !ok {
+			continue
+		}
 		t := &TransferTask{
 			TaskExtension: task.TaskExtension{
 				Creator: taskCreator,
@@ -95,6 +101,7 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 			DstStorage:   dstStorage,
 			DstStorageMp: dstStorage.GetStorage().MountPath,
 			DeletePolicy: deletePolicy,
+			Selected:     sub,
 		}
 		TransferTaskManager.Add(t)
 	}
@@ -114,6 +121,10 @@ func transferStdPath(t *TransferTask) error {
 			return err
 		}
 		for _, entry := range entries {
+			sub, ok := selectedIn(t.Selected, entry.Name())
+			if !ok {
+				continue
+			}
 			srcRawPath := stdpath.Join(t.SrcObjPath, entry.Name())
 			dstObjPath := stdpath.Join(t.DstDirPath, info.Name())
 			t := &TransferTask{
@@ -126,6 +137,7 @@ func transferStdPath(t *TransferTask) error {
 				SrcStorageMp: t.SrcStorageMp,
 				DstStorageMp: t.DstStorageMp,
 				DeletePolicy: t.DeletePolicy,
+				Selected:     sub,
 			}
 			TransferTaskManager.Add(t)
 		}
@@ -157,8 +169,12 @@ func transferStdFile(t *TransferTask) error {
 		Mimetype: mimetype,
 		Closers:  utils.NewClosers(rc),
 	}
+	if l := transferLimiter(t.GetCreator()); l != nil {
+		// still a file, so that it's never cached to another temp file
+		s.Reader = &stream.RateLimitFile{File: rc, Limiter: l, Ctx: t.Ctx()}
+	}
 	t.SetTotalBytes(info.Size())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
+	return op.PutWithQuota(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
 }
 
 func removeStdTemp(t *TransferTask) {
@@ -251,6 +267,11 @@ func transferObjFile(t *TransferTask) error {
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] link", t.SrcObjPath)
 	}
+	if l := transferLimiter(t.GetCreator()); l != nil {
+		if link, err = limitLink(t.Ctx(), link, srcFile.GetSize(), l); err != nil {
+			return errors.WithMessagef(err, "failed get [%s] link", t.SrcObjPath)
+		}
+	}
 	fs := stream.FileStream{
 		Obj: srcFile,
 		Ctx: t.Ctx(),
@@ -261,7 +282,7 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcObjPath)
 	}
 	t.SetTotalBytes(srcFile.GetSize())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress)
+	return op.PutWithQuota(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress)
 }
 
 func removeObjTemp(t *TransferTask) {
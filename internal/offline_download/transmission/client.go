@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -22,6 +23,8 @@ import (
 
 type Transmission struct {
 	client *transmissionrpc.Client
+	// selected are the gids whose files are selected, the files are known after the metadata is downloaded
+	selected sync.Map
 }
 
 func (t *Transmission) Run(task *tool.DownloadTask) error {
@@ -74,48 +77,122 @@ func (t *Transmission) IsReady() bool {
 }
 
 func (t *Transmission) AddURL(args *tool.AddUrlArgs) (string, error) {
-	endpoint, err := url.Parse(args.Url)
+	id, err := t.add(args.Url, args.TempDir)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse transmission uri")
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+func (t *Transmission) add(uri, downloadDir string) (int64, error) {
+	endpoint, err := url.Parse(uri)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse transmission uri")
 	}
 
 	rpcPayload := transmissionrpc.TorrentAddPayload{
-		DownloadDir: &args.TempDir,
+		DownloadDir: &downloadDir,
 	}
 	// http url for .torrent file
 	if endpoint.Scheme == "http" || endpoint.Scheme == "https" {
-		resp, err := http.Get(args.Url)
+		resp, err := http.Get(uri)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to get .torrent file")
+			return 0, errors.Wrap(err, "failed to get .torrent file")
 		}
 		defer resp.Body.Close()
 		buffer := new(bytes.Buffer)
 		encoder := base64.NewEncoder(base64.StdEncoding, buffer)
 		// Stream file to the encoder
 		if _, err = utils.CopyWithBuffer(encoder, resp.Body); err != nil {
-			return "", errors.Wrap(err, "can't copy file content into the base64 encoder")
+			return 0, errors.Wrap(err, "can't copy file content into the base64 encoder")
 		}
 		// Flush last bytes
 		if err = encoder.Close(); err != nil {
-			return "", errors.Wrap(err, "can't flush last bytes of the base64 encoder")
+			return 0, errors.Wrap(err, "can't flush last bytes of the base64 encoder")
 		}
 		// Get the string form
 		b64 := buffer.String()
 		rpcPayload.MetaInfo = &b64
 	} else { // magnet uri
-		rpcPayload.Filename = &args.Url
+		rpcPayload.Filename = &uri
 	}
 
 	torrent, err := t.client.TorrentAdd(context.TODO(), rpcPayload)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	if torrent.ID == nil {
-		return "", fmt.Errorf("failed get torrent ID")
+		return 0, fmt.Errorf("failed get torrent ID")
+	}
+	return *torrent.ID, nil
+}
+
+func (t *Transmission) Resolve(ctx context.Context, args *tool.AddUrlArgs) ([]tool.TorrentFile, error) {
+	id, err := t.add(args.Url, args.TempDir)
+	if err != nil {
+		return nil, err
 	}
-	gid := strconv.FormatInt(*torrent.ID, 10)
-	return gid, nil
+	defer func() {
+		err := t.client.TorrentRemove(context.Background(), transmissionrpc.TorrentRemovePayload{
+			IDs:             []int64{id},
+			DeleteLocalData: true,
+		})
+		if err != nil {
+			log.Warnf("failed remove transmission resolve torrent %d: %+v", id, err)
+		}
+	}()
+	return tool.WaitMetadata(ctx, func() ([]tool.TorrentFile, error) {
+		files, err := t.files(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]tool.TorrentFile, len(files))
+		for i, f := range files {
+			res[i] = tool.TorrentFile{Index: i, Path: f.Name, Size: f.Length}
+		}
+		return res, nil
+	})
+}
+
+// files returns the files of the torrent, empty before the metadata is downloaded
+func (t *Transmission) files(ctx context.Context, id int64) ([]transmissionrpc.TorrentFile, error) {
+	infos, err := t.client.TorrentGet(ctx, []string{"files"}, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) < 1 {
+		return nil, fmt.Errorf("torrent %d not found", id)
+	}
+	return infos[0].Files, nil
+}
+
+// selectFiles stops downloading the files not selected once the files are known
+func (t *Transmission) selectFiles(task *tool.DownloadTask, id int64, files []transmissionrpc.TorrentFile) error {
+	selected := task.SelectedFileIndexes()
+	if len(selected) == 0 || len(files) == 0 {
+		return nil
+	}
+	if _, ok := t.selected.Load(task.GID); ok {
+		return nil
+	}
+	var unwanted []int64
+	for i := range files {
+		if !utils.SliceContains(selected, i) {
+			unwanted = append(unwanted, int64(i))
+		}
+	}
+	if len(unwanted) > 0 {
+		err := t.client.TorrentSet(context.TODO(), transmissionrpc.TorrentSetPayload{
+			IDs:           []int64{id},
+			FilesUnwanted: unwanted,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	t.selected.Store(task.GID, struct{}{})
+	return nil
 }
 
 func (t *Transmission) Remove(task *tool.DownloadTask) error {
@@ -123,6 +200,7 @@ func (t *Transmission) Remove(task *tool.DownloadTask) error {
 	if err != nil {
 		return err
 	}
+	t.selected.Delete(task.GID)
 	err = t.client.TorrentRemove(context.TODO(), transmissionrpc.TorrentRemovePayload{
 		IDs:             []int64{gid},
 		DeleteLocalData: false,
@@ -144,6 +222,9 @@ func (t *Transmission) Status(task *tool.DownloadTask) (*tool.Status, error) {
 		return nil, fmt.Errorf("failed get status, wrong gid: %s", task.GID)
 	}
 	info := infos[0]
+	if err = t.selectFiles(task, gid, info.Files); err != nil {
+		return nil, errors.WithMessage(err, "failed select files")
+	}
 
 	s := &tool.Status{
 		Completed: *info.IsFinished,
@@ -170,7 +251,7 @@ func (t *Transmission) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
-var _ tool.Tool = (*Transmission)(nil)
+var _ tool.TorrentTool = (*Transmission)(nil)
 
 func init() {
 	tool.Tools.Add(&Transmission{})
@@ -0,0 +1,109 @@
+package ytdlp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	log "github.com/sirupsen/logrus"
+)
+
+// progressPrefix marks the progress lines printed by the progress template
+const progressPrefix = "[alist-progress] "
+
+// download is the state of a running yt-dlp, read from its output
+type download struct {
+	sync.Mutex
+	cancel context.CancelFunc
+	state  string
+	// files is the number of the files started, the formats merged and the playlist items are downloaded one by one
+	files      int
+	downloaded int64
+	total      int64
+	done       bool
+	err        error
+}
+
+func (d *download) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if downloaded, total, ok := parseProgress(line); ok {
+			d.Lock()
+			d.downloaded, d.total = downloaded, total
+			d.Unlock()
+			continue
+		}
+		log.Debugf("[yt-dlp] %s", line)
+		d.Lock()
+		switch {
+		case strings.HasPrefix(line, "[download] Destination:"):
+			d.files++
+			d.downloaded, d.total = 0, 0
+			d.state = fmt.Sprintf("downloading file %d", d.files)
+		case strings.HasPrefix(line, "[Merger]"), strings.HasPrefix(line, "[ExtractAudio]"),
+			strings.HasPrefix(line, "[VideoConvertor]"), strings.HasPrefix(line, "[VideoRemuxer]"):
+			d.state = "post processing"
+		}
+		d.Unlock()
+	}
+}
+
+func (d *download) finish(err error) {
+	d.Lock()
+	defer d.Unlock()
+	d.done, d.err = true, err
+	if err == nil {
+		d.state = "completed"
+		d.downloaded = d.total
+	} else {
+		d.state = "failed"
+	}
+}
+
+func (d *download) status() *tool.Status {
+	d.Lock()
+	defer d.Unlock()
+	s := &tool.Status{
+		TotalBytes: d.total,
+		Completed:  d.done && d.err == nil,
+		Status:     d.state,
+		Err:        d.err,
+	}
+	if d.total > 0 {
+		s.Progress = float64(d.downloaded) / float64(d.total) * 100
+	}
+	if s.Completed {
+		s.Progress = 100
+	}
+	return s
+}
+
+// parseProgress parses the line of the progress template, the total is estimated if it's unknown,
+// yt-dlp prints NA for the missing fields
+func parseProgress(line string) (downloaded, total int64, ok bool) {
+	rest, ok := strings.CutPrefix(line, progressPrefix)
+	if !ok {
+		return 0, 0, false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 3 {
+		return 0, 0, false
+	}
+	values := make([]int64, 3)
+	for i, f := range fields {
+		if v, err := strconv.ParseFloat(f, 64); err == nil {
+			values[i] = int64(v)
+		}
+	}
+	total = values[1]
+	if total == 0 {
+		total = values[2]
+	}
+	return values[0], total, true
+}
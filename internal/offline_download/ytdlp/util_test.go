@@ -0,0 +1,25 @@
+package ytdlp
+
+import "testing"
+
+func TestParseProgress(t *testing.T) {
+	datas := []struct {
+		line       string
+		downloaded int64
+		total      int64
+		ok         bool
+	}{
+		{progressPrefix + "1024 4096 NA", 1024, 4096, true},
+		{progressPrefix + "1024 NA 8192.5", 1024, 8192, true},
+		{progressPrefix + "NA NA NA", 0, 0, true},
+		{"[download] Destination: a.mp4", 0, 0, false},
+		{progressPrefix + "1024", 0, 0, false},
+	}
+	for _, data := range datas {
+		downloaded, total, ok := parseProgress(data.line)
+		if downloaded != data.downloaded || total != data.total || ok != data.ok {
+			t.Errorf("parseProgress(%q) = %d, %d, %v, want %d, %d, %v",
+				data.line, downloaded, total, ok, data.downloaded, data.total, data.ok)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+// YtDlp extracts the media of the pages by the yt-dlp binary, the files are saved to the temp dir and transferred
+type YtDlp struct {
+	version   string
+	downloads sync.Map
+}
+
+func (y *YtDlp) Run(task *tool.DownloadTask) error {
+	return errs.NotSupport
+}
+
+func (y *YtDlp) Name() string {
+	return "yt-dlp"
+}
+
+func (y *YtDlp) Items() []model.SettingItem {
+	return []model.SettingItem{
+		{Key: conf.YtDlpPath, Value: "yt-dlp", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtDlpFfmpegPath, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtDlpFormat, Value: "", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		// one argument per line, so that the arguments with spaces need no quoting
+		{Key: conf.YtDlpArgs, Value: "", Type: conf.TypeText, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
+}
+
+func (y *YtDlp) Init() (string, error) {
+	y.version = ""
+	out, err := exec.Command(setting.GetStr(conf.YtDlpPath, "yt-dlp"), "--version").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to run yt-dlp")
+	}
+	y.version = strings.TrimSpace(string(out))
+	return fmt.Sprintf("yt-dlp version: %s", y.version), nil
+}
+
+func (y *YtDlp) IsReady() bool {
+	return y.version != ""
+}
+
+func (y *YtDlp) AddURL(args *tool.AddUrlArgs) (string, error) {
+	if err := os.MkdirAll(args.TempDir, os.ModePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, setting.GetStr(conf.YtDlpPath, "yt-dlp"), buildArgs(args)...)
+	cmd.Dir = args.TempDir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return "", errors.WithStack(err)
+	}
+	stderr := &tailWriter{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return "", errors.Wrap(err, "failed to start yt-dlp")
+	}
+	d := &download{cancel: cancel, state: "extracting"}
+	y.downloads.Store(args.UID, d)
+	go func() {
+		d.read(stdout)
+		err := cmd.Wait()
+		if err != nil && stderr.String() != "" {
+			err = errors.Errorf("%s: %s", err, stderr.String())
+		}
+		d.finish(err)
+		if args.Signal != nil {
+			select {
+			case args.Signal <- 1:
+			default:
+			}
+		}
+	}()
+	return args.UID, nil
+}
+
+func (y *YtDlp) Remove(task *tool.DownloadTask) error {
+	if d, ok := y.downloads.LoadAndDelete(task.GID); ok {
+		d.(*download).cancel()
+	}
+	return nil
+}
+
+func (y *YtDlp) Status(task *tool.DownloadTask) (*tool.Status, error) {
+	v, ok := y.downloads.Load(task.GID)
+	if !ok {
+		return nil, errors.Errorf("yt-dlp download %s not found", task.GID)
+	}
+	d := v.(*download)
+	s := d.status()
+	if s.Completed || s.Err != nil {
+		y.downloads.Delete(task.GID)
+	}
+	return s, nil
+}
+
+// buildArgs returns the arguments of yt-dlp, the progress is printed as a line which parseProgress reads
+func buildArgs(args *tool.AddUrlArgs) []string {
+	a := []string{
+		"--newline", "--no-colors", "--no-mtime",
+		"--progress-template", "download:" + progressPrefix + "%(progress.downloaded_bytes)s %(progress.total_bytes)s %(progress.total_bytes_estimate)s",
+		// relative to the temp dir, which is the working dir of yt-dlp
+		"-o", "%(title).200B [%(id)s].%(ext)s",
+	}
+	if ffmpeg := setting.GetStr(conf.YtDlpFfmpegPath); ffmpeg != "" {
+		a = append(a, "--ffmpeg-location", ffmpeg)
+	}
+	if format := setting.GetStr(conf.YtDlpFormat); format != "" {
+		a = append(a, "-f", format)
+	}
+	for _, arg := range strings.Split(setting.GetStr(conf.YtDlpArgs), "\n") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			a = append(a, arg)
+		}
+	}
+	// the url is after "--", so that it's never read as an option
+	return append(a, "--", args.Url)
+}
+
+// tailWriter keeps the last lines written, which are the error of yt-dlp
+type tailWriter struct {
+	sync.Mutex
+	lines []string
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.lines = append(w.lines, line)
+		}
+	}
+	if len(w.lines) > 5 {
+		w.lines = w.lines[len(w.lines)-5:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.Lock()
+	defer w.Unlock()
+	return strings.Join(w.lines, "; ")
+}
+
+func init() {
+	tool.Tools.Add(&YtDlp{})
+}
@@ -55,34 +55,39 @@ func GetArchiveMeta(ctx context.Context, storage driver.Driver, path string, arg
 	return meta, err
 }
 
+// GetArchiveTool returns the archive tool by the name, the extension after the first dot like .tar.gz
+// is tried before the last one
+func GetArchiveTool(name string) (*tool.MultipartExtension, tool.Tool, error) {
+	_, ext, found := strings.Cut(name, ".")
+	if !found {
+		return nil, nil, errors.Errorf("failed get archive tool: the obj does not have an extension.")
+	}
+	partExt, t, err := tool.GetArchiveTool("." + ext)
+	if err != nil {
+		var e error
+		partExt, t, e = tool.GetArchiveTool(stdpath.Ext(name))
+		if e != nil {
+			return nil, nil, errors.WithMessagef(stderrors.Join(err, e), "failed get archive tool: %s", ext)
+		}
+	}
+	return partExt, t, nil
+}
+
 func GetArchiveToolAndStream(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (model.Obj, tool.Tool, []*stream.SeekableStream, error) {
 	l, obj, err := Link(ctx, storage, path, args)
 	if err != nil {
 		return nil, nil, nil, errors.WithMessagef(err, "failed get [%s] link", path)
 	}
-	baseName, ext, found := strings.Cut(obj.GetName(), ".")
-	if !found {
+	baseName, _, _ := strings.Cut(obj.GetName(), ".")
+	partExt, t, err := GetArchiveTool(obj.GetName())
+	if err != nil {
 		if l.MFile != nil {
 			_ = l.MFile.Close()
 		}
 		if l.RangeReadCloser != nil {
 			_ = l.RangeReadCloser.Close()
 		}
-		return nil, nil, nil, errors.Errorf("failed get archive tool: the obj does not have an extension.")
-	}
-	partExt, t, err := tool.GetArchiveTool("." + ext)
-	if err != nil {
-		var e error
-		partExt, t, e = tool.GetArchiveTool(stdpath.Ext(obj.GetName()))
-		if e != nil {
-			if l.MFile != nil {
-				_ = l.MFile.Close()
-			}
-			if l.RangeReadCloser != nil {
-				_ = l.RangeReadCloser.Close()
-			}
-			return nil, nil, nil, errors.WithMessagef(stderrors.Join(err, e), "failed get archive tool: %s", ext)
-		}
+		return nil, nil, nil, err
 	}
 	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, l)
 	if err != nil {
@@ -0,0 +1,24 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func CreateAuditLogs(ls []model.AuditLog) error {
+	return db.CreateAuditLogs(ls)
+}
+
+func GetAuditLogs(f model.AuditLogFilter, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	return db.GetAuditLogs(f, pageIndex, pageSize)
+}
+
+func WalkAuditLogs(f model.AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error {
+	return db.WalkAuditLogs(f, batchSize, fn)
+}
+
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	return db.DeleteAuditLogsBefore(t)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
@@ -118,8 +119,10 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	if !args.Refresh {
 		if files, ok := listCache.Get(key); ok {
 			log.Debugf("use cache when list %s", path)
+			metrics.ListCacheHit()
 			return files, nil
 		}
+		metrics.ListCacheMiss()
 	}
 	dir, err := GetUnwrap(ctx, storage, path)
 	if err != nil {
@@ -130,7 +133,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		start := time.Now()
 		files, err := storage.List(ctx, dir, args)
+		metrics.ObserveDriverRequest(storage.Config().Name, "list", start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -263,7 +268,9 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		start := time.Now()
 		link, err := storage.Link(ctx, file, args)
+		metrics.ObserveDriverRequest(storage.Config().Name, "link", start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
@@ -345,6 +352,9 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 				default:
 					return nil, errs.NotImplement
 				}
+				if err == nil {
+					handleDirsChangedHook(storage, parentPath)
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -399,6 +409,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		handleDirsChangedHook(storage, srcDirPath, dstDirPath)
+	}
 	return errors.WithStack(err)
 }
 
@@ -433,6 +446,9 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		handleDirsChangedHook(storage, srcDirPath)
+	}
 	return errors.WithStack(err)
 }
 
@@ -471,6 +487,9 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		handleDirsChangedHook(storage, dstDirPath)
+	}
 	return errors.WithStack(err)
 }
 
@@ -506,6 +525,9 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		handleDirsChangedHook(storage, dirPath)
+	}
 	return errors.WithStack(err)
 }
 
@@ -560,6 +582,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		up = func(p float64) {}
 	}
 
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.PutResult:
 		var newObj model.Obj
@@ -579,7 +602,11 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	default:
 		return errs.NotImplement
 	}
+	metrics.ObserveDriverRequest(storage.Config().Name, "put", start, err)
 	log.Debugf("put file [%s] done", file.GetName())
+	if err == nil {
+		handleDirsChangedHook(storage, dstDirPath)
+	}
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
 			// upload failed, recover old obj
@@ -638,5 +665,8 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 		return errs.NotImplement
 	}
 	log.Debugf("put url [%s](%s) done", dstName, url)
+	if err == nil {
+		handleDirsChangedHook(storage, dstDirPath)
+	}
 	return errors.WithStack(err)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -30,6 +31,28 @@ func HandleObjsUpdateHook(parent string, objs []model.Obj) {
 	}
 }
 
+// DirsChangedHook is called with the paths of the dirs whose children are changed by the write operations
+type DirsChangedHook = func(dirs ...string)
+
+var dirsChangedHooks = make([]DirsChangedHook, 0)
+
+func RegisterDirsChangedHook(hook DirsChangedHook) {
+	dirsChangedHooks = append(dirsChangedHooks, hook)
+}
+
+func handleDirsChangedHook(storage driver.Driver, dirs ...string) {
+	if len(dirsChangedHooks) == 0 {
+		return
+	}
+	fullPaths := make([]string, len(dirs))
+	for i, dir := range dirs {
+		fullPaths[i] = utils.GetFullPath(storage.GetStorage().MountPath, dir)
+	}
+	for _, hook := range dirsChangedHooks {
+		hook(fullPaths...)
+	}
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
@@ -83,6 +106,10 @@ var settingItemHooks = map[string]SettingItemHook{
 		conf.SlicesMap[conf.IgnoreDirectLinkParams] = strings.Split(item.Value, ",")
 		return nil
 	},
+	conf.BandwidthSchedule: func(item *model.SettingItem) error {
+		_, err := stream.ParseBandwidthSchedule(item.Value)
+		return err
+	},
 	conf.DefaultRole: func(item *model.SettingItem) error {
 		v := strings.TrimSpace(item.Value)
 		if v == "" {
@@ -0,0 +1,30 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func GetIndexDir(path string) (*model.IndexDir, error) {
+	return db.GetIndexDir(path)
+}
+
+func SaveIndexDir(dir *model.IndexDir) error {
+	return db.SaveIndexDir(dir)
+}
+
+func SetIndexDirDone(path string, done bool) error {
+	return db.SetIndexDirDone(path, done)
+}
+
+func UndoneIndexDirs(paths []string) error {
+	return db.UndoneIndexDirs(paths)
+}
+
+func DeleteIndexDirs(path string) error {
+	return db.DeleteIndexDirs(path)
+}
+
+func ClearIndexDirs() error {
+	return db.ClearIndexDirs()
+}
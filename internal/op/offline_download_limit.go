@@ -0,0 +1,37 @@
+package op
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+// GetOfflineDownloadLimit returns the max offline downloads running at the same time and the max transfer speed
+// in KB/s of the user, like GetQuotaLimit the limits set on the user take precedence over the roles,
+// otherwise the largest limit of the roles is used. 0 means no limit.
+func GetOfflineDownloadLimit(u *model.User) (downloads, speed int) {
+	downloads, speed = u.MaxOfflineDownloads, u.MaxTransferSpeed
+	if downloads > 0 && speed > 0 {
+		return
+	}
+	roles := u.RolesDetail
+	if len(roles) == 0 {
+		roles, _ = GetRolesByUserID(u.ID)
+	}
+	var roleDownloads, roleSpeed int
+	// a role without a limit lifts the limit of the user
+	unlimitedDownloads, unlimitedSpeed := len(roles) == 0, len(roles) == 0
+	for _, role := range roles {
+		if role.MaxOfflineDownloads <= 0 {
+			unlimitedDownloads = true
+		}
+		if role.MaxTransferSpeed <= 0 {
+			unlimitedSpeed = true
+		}
+		roleDownloads = max(roleDownloads, role.MaxOfflineDownloads)
+		roleSpeed = max(roleSpeed, role.MaxTransferSpeed)
+	}
+	if downloads <= 0 && !unlimitedDownloads {
+		downloads = roleDownloads
+	}
+	if speed <= 0 && !unlimitedSpeed {
+		speed = roleSpeed
+	}
+	return
+}
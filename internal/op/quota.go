@@ -0,0 +1,185 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetQuotaLimit returns the limits of the user, the limits set on the user take precedence
+// over the roles, otherwise the largest limit of the roles is used. 0 means no limit.
+func GetQuotaLimit(u *model.User) (bytes, files int64) {
+	bytes, files = u.QuotaBytes, u.QuotaFiles
+	if bytes > 0 && files > 0 {
+		return
+	}
+	roles := u.RolesDetail
+	if len(roles) == 0 {
+		roles, _ = GetRolesByUserID(u.ID)
+	}
+	var roleBytes, roleFiles int64
+	// a role without a limit lifts the limit of the user
+	unlimitedBytes, unlimitedFiles := len(roles) == 0, len(roles) == 0
+	for _, role := range roles {
+		if role.QuotaBytes <= 0 {
+			unlimitedBytes = true
+		}
+		if role.QuotaFiles <= 0 {
+			unlimitedFiles = true
+		}
+		roleBytes = max(roleBytes, role.QuotaBytes)
+		roleFiles = max(roleFiles, role.QuotaFiles)
+	}
+	if bytes <= 0 && !unlimitedBytes {
+		bytes = roleBytes
+	}
+	if files <= 0 && !unlimitedFiles {
+		files = roleFiles
+	}
+	return
+}
+
+// quotaOwners caches the users having a quota, it's reset when a user or a role changes
+var quotaOwners struct {
+	sync.Mutex
+	users  []model.User
+	loaded bool
+}
+
+func resetQuotaOwners() {
+	quotaOwners.Lock()
+	defer quotaOwners.Unlock()
+	quotaOwners.users, quotaOwners.loaded = nil, false
+}
+
+func getQuotaOwners() ([]model.User, error) {
+	quotaOwners.Lock()
+	defer quotaOwners.Unlock()
+	if quotaOwners.loaded {
+		return quotaOwners.users, nil
+	}
+	users, err := GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	var owners []model.User
+	for _, u := range users {
+		if bytes, files := GetQuotaLimit(&u); bytes > 0 || files > 0 {
+			owners = append(owners, u)
+		}
+	}
+	quotaOwners.users, quotaOwners.loaded = owners, true
+	return owners, nil
+}
+
+// GetQuotaUser returns the user in the ctx if the user has a quota, the objects stored and removed
+// by the user count towards the quota. It returns nil if nothing is counted, e.g. for internal tasks.
+func GetQuotaUser(ctx context.Context) *model.User {
+	u, _ := ctx.Value("user").(*model.User)
+	if u == nil {
+		return nil
+	}
+	if bytes, files := GetQuotaLimit(u); bytes <= 0 && files <= 0 {
+		return nil
+	}
+	return u
+}
+
+// GetNestedQuotaPaths returns the base paths of the other users having a quota under the base path of the user,
+// the recalculation of the usage of the user skips them since they are counted for those users
+func GetNestedQuotaPaths(u *model.User) []string {
+	users, err := getQuotaOwners()
+	if err != nil {
+		log.Errorf("failed get the users having a quota: %+v", err)
+		return nil
+	}
+	var paths []string
+	for _, other := range users {
+		if other.ID != u.ID && !utils.PathEqual(other.BasePath, u.BasePath) && utils.IsSubPath(u.BasePath, other.BasePath) {
+			paths = append(paths, utils.FixAndCleanPath(other.BasePath))
+		}
+	}
+	return paths
+}
+
+func GetQuota(u *model.User) (*model.Quota, error) {
+	usage, err := db.GetQuotaUsage(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	limitBytes, limitFiles := GetQuotaLimit(u)
+	return &model.Quota{
+		LimitBytes: limitBytes,
+		LimitFiles: limitFiles,
+		UsedBytes:  usage.UsedBytes,
+		UsedFiles:  usage.UsedFiles,
+	}, nil
+}
+
+// CheckQuota returns errs.QuotaExceeded if the user can't store more bytes and files,
+// nothing is checked without a user, e.g. for internal tasks
+func CheckQuota(u *model.User, bytes, files int64) error {
+	if u == nil {
+		return nil
+	}
+	limitBytes, limitFiles := GetQuotaLimit(u)
+	if limitBytes <= 0 && limitFiles <= 0 {
+		return nil
+	}
+	usage, err := db.GetQuotaUsage(u.ID)
+	if err != nil {
+		return err
+	}
+	if limitBytes > 0 && usage.UsedBytes+bytes > limitBytes {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	if limitFiles > 0 && usage.UsedFiles+files > limitFiles {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	return nil
+}
+
+// AddQuotaUsage records the bytes and files stored by the user, they are negative for removed ones
+func AddQuotaUsage(u *model.User, bytes, files int64) error {
+	if u == nil || (bytes == 0 && files == 0) {
+		return nil
+	}
+	return db.IncreaseQuotaUsage(u.ID, bytes, files)
+}
+
+// PutWithQuota puts the file like Put and records it in the usage of the user in the ctx,
+// it fails with errs.QuotaExceeded before uploading if the user can't store the file.
+// A file replacing an existing one only counts the difference of the sizes.
+func PutWithQuota(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	u := GetQuotaUser(ctx)
+	if u == nil {
+		return Put(ctx, storage, dstDirPath, file, up, lazyCache...)
+	}
+	bytes, files := file.GetSize(), int64(1)
+	if old, err := Get(ctx, storage, stdpath.Join(dstDirPath, file.GetName())); err == nil && !old.IsDir() {
+		bytes, files = bytes-old.GetSize(), 0
+	}
+	if err := CheckQuota(u, bytes, files); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := Put(ctx, storage, dstDirPath, file, up, lazyCache...); err != nil {
+		return err
+	}
+	if err := AddQuotaUsage(u, bytes, files); err != nil {
+		log.Errorf("failed add quota usage of user [%s]: %+v", u.Username, err)
+	}
+	return nil
+}
+
+func SaveQuotaUsage(usage *model.QuotaUsage) error {
+	return db.SaveQuotaUsage(usage)
+}
@@ -0,0 +1,40 @@
+package op_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestQuotaUsers(t *testing.T) {
+	users := []*model.User{
+		{Username: "team", BasePath: "/team", QuotaBytes: 100},
+		{Username: "alice", BasePath: "/team/alice", QuotaFiles: 10},
+		{Username: "carol", BasePath: "/team", QuotaFiles: 10},
+		{Username: "bob", BasePath: "/team/bob"}, // no quota
+	}
+	for _, u := range users {
+		if err := op.CreateUser(u); err != nil {
+			t.Fatalf("failed create user %s: %+v", u.Username, err)
+		}
+	}
+	if u := op.GetQuotaUser(context.WithValue(context.Background(), "user", users[1])); u == nil || u.Username != "alice" {
+		t.Errorf("quota user is %v, want alice", u)
+	}
+	if u := op.GetQuotaUser(context.WithValue(context.Background(), "user", users[3])); u != nil {
+		t.Errorf("quota user is %s, want none", u.Username)
+	}
+	if u := op.GetQuotaUser(context.Background()); u != nil {
+		t.Errorf("quota user is %s, want none", u.Username)
+	}
+	// users sharing a base path both count it
+	if paths := op.GetNestedQuotaPaths(users[0]); !reflect.DeepEqual(paths, []string{"/team/alice"}) {
+		t.Errorf("nested quota paths of team are %v, want [/team/alice]", paths)
+	}
+	if paths := op.GetNestedQuotaPaths(users[1]); len(paths) != 0 {
+		t.Errorf("nested quota paths of alice are %v, want none", paths)
+	}
+}
@@ -164,6 +164,7 @@ func UpdateRole(r *model.Role) error {
 	//}
 	roleCache.Del(fmt.Sprint(r.ID))
 	roleCache.Del(r.Name)
+	defer resetQuotaOwners()
 	if err := db.UpdateRole(r); err != nil {
 		return err
 	}
@@ -191,5 +192,6 @@ func DeleteRole(id uint) error {
 	}
 	roleCache.Del(fmt.Sprint(id))
 	roleCache.Del(old.Name)
+	defer resetQuotaOwners()
 	return db.DeleteRole(id)
 }
@@ -0,0 +1,51 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func GetRssFeedById(id uint) (*model.RssFeed, error) {
+	return db.GetRssFeedById(id)
+}
+
+func GetRssFeeds(userID uint, pageIndex, pageSize int) ([]model.RssFeed, int64, error) {
+	return db.GetRssFeeds(userID, pageIndex, pageSize)
+}
+
+func GetEnabledRssFeeds() ([]model.RssFeed, error) {
+	return db.GetEnabledRssFeeds()
+}
+
+func CreateRssFeed(f *model.RssFeed) error {
+	f.DstPath = utils.FixAndCleanPath(f.DstPath)
+	return db.CreateRssFeed(f)
+}
+
+func UpdateRssFeed(f *model.RssFeed) error {
+	f.DstPath = utils.FixAndCleanPath(f.DstPath)
+	return db.UpdateRssFeed(f)
+}
+
+func UpdateRssFeedResult(id uint, lastCheck time.Time, result string) error {
+	return db.UpdateRssFeedResult(id, lastCheck, result)
+}
+
+func DeleteRssFeedById(id uint) error {
+	return db.DeleteRssFeedById(id)
+}
+
+func GetRssItemByGUID(feedID uint, guid string) (*model.RssItem, error) {
+	return db.GetRssItemByGUID(feedID, guid)
+}
+
+func SaveRssItem(item *model.RssItem) error {
+	return db.SaveRssItem(item)
+}
+
+func GetRssItems(feedID uint, pageIndex, pageSize int) ([]model.RssItem, int64, error) {
+	return db.GetRssItems(feedID, pageIndex, pageSize)
+}
@@ -0,0 +1,40 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func GetScheduledJobById(id uint) (*model.ScheduledJob, error) {
+	return db.GetScheduledJobById(id)
+}
+
+func GetScheduledJobs(pageIndex, pageSize int) ([]model.ScheduledJob, int64, error) {
+	return db.GetScheduledJobs(pageIndex, pageSize)
+}
+
+func GetEnabledScheduledJobs() ([]model.ScheduledJob, error) {
+	return db.GetEnabledScheduledJobs()
+}
+
+func CreateScheduledJob(j *model.ScheduledJob) error {
+	return db.CreateScheduledJob(j)
+}
+
+func UpdateScheduledJob(j *model.ScheduledJob) error {
+	return db.UpdateScheduledJob(j)
+}
+
+func UpdateScheduledJobNextRun(id uint, nextRun *time.Time) error {
+	return db.UpdateScheduledJobNextRun(id, nextRun)
+}
+
+func UpdateScheduledJobResult(id uint, lastRun time.Time, success bool, result string) error {
+	return db.UpdateScheduledJobResult(id, lastRun, success, result)
+}
+
+func DeleteScheduledJobById(id uint) error {
+	return db.DeleteScheduledJobById(id)
+}
@@ -0,0 +1,76 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+func CreateShare(s *model.Share) error {
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.Created = time.Now()
+	s.Downloads = 0
+	for i := 0; i < 5; i++ {
+		s.ID = random.String(10)
+		if _, err := db.GetShareById(s.ID); err != nil {
+			return db.CreateShare(s)
+		}
+	}
+	return errors.New("failed to generate share id")
+}
+
+func GetShareById(id string) (*model.Share, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.ShareNotFound)
+	}
+	return s, nil
+}
+
+func GetShares(pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetShares(pageIndex, pageSize)
+}
+
+func GetSharesByUserId(userId uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetSharesByUserId(userId, pageIndex, pageSize)
+}
+
+func UpdateShare(s *model.Share) error {
+	return db.UpdateShare(s)
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
+
+// CheckShare checks whether the share can be visited with the password
+func CheckShare(s *model.Share, password string) error {
+	if s.Disabled {
+		return errors.WithStack(errs.ShareDisabled)
+	}
+	if s.IsExpired() {
+		return errors.WithStack(errs.ShareExpired)
+	}
+	if !s.ValidatePassword(password) {
+		return errors.WithStack(errs.WrongSharePassword)
+	}
+	return nil
+}
+
+// CountShareDownload counts a download of the share, it fails if the download limit is reached
+func CountShareDownload(s *model.Share) error {
+	ok, err := db.IncreaseShareDownloads(s.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.WithStack(errs.ShareDownloadLimitReached)
+	}
+	s.Downloads++
+	return nil
+}
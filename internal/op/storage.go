@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -433,3 +434,32 @@ func GetBalancedStorage(path string) driver.Driver {
 		return storages[i]
 	}
 }
+
+// ReloadStorages drops the storages and loads them again, it returns the number of storages failed to reload
+func ReloadStorages(ctx context.Context, storages []model.Storage) int {
+	conf.StoragesLoaded = false
+	defer func() { conf.StoragesLoaded = true }()
+	failed := 0
+	for _, storage := range storages {
+		storageDriver, err := GetStorageByMountPath(storage.MountPath)
+		if err != nil {
+			log.Errorf("failed get storage driver: %+v", err)
+			failed++
+			continue
+		}
+		// drop the storage in the driver
+		if err := storageDriver.Drop(ctx); err != nil {
+			log.Errorf("failed drop storage: %+v", err)
+			failed++
+			continue
+		}
+		if err := LoadStorage(ctx, storage); err != nil {
+			log.Errorf("failed get enabled storages: %+v", err)
+			failed++
+			continue
+		}
+		log.Infof("success load storage: [%s], driver: [%s]",
+			storage.MountPath, storage.Driver)
+	}
+	return failed
+}
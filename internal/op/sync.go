@@ -0,0 +1,52 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	return db.GetSyncJobById(id)
+}
+
+func GetSyncJobs(pageIndex, pageSize int) ([]model.SyncJob, int64, error) {
+	return db.GetSyncJobs(pageIndex, pageSize)
+}
+
+func GetEnabledSyncJobs() ([]model.SyncJob, error) {
+	return db.GetEnabledSyncJobs()
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	j.SrcPath = utils.FixAndCleanPath(j.SrcPath)
+	j.DstPath = utils.FixAndCleanPath(j.DstPath)
+	return db.CreateSyncJob(j)
+}
+
+func UpdateSyncJob(j *model.SyncJob) error {
+	old, err := db.GetSyncJobById(j.ID)
+	if err != nil {
+		return err
+	}
+	j.SrcPath = utils.FixAndCleanPath(j.SrcPath)
+	j.DstPath = utils.FixAndCleanPath(j.DstPath)
+	if err = db.UpdateSyncJob(j); err != nil {
+		return err
+	}
+	// the state describes other paths, it would propagate wrong deletions
+	if old.SrcPath != j.SrcPath || old.DstPath != j.DstPath || old.Mode != j.Mode {
+		return db.ResetSyncJobState(j.ID)
+	}
+	return nil
+}
+
+func UpdateSyncJobResult(id uint, lastRun time.Time, result string, state *string) error {
+	return db.UpdateSyncJobResult(id, lastRun, result, state)
+}
+
+func DeleteSyncJobById(id uint) error {
+	return db.DeleteSyncJobById(id)
+}
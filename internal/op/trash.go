@@ -0,0 +1,38 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateTrashItem(item *model.TrashItem) error {
+	return db.CreateTrashItem(item)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.TrashItemNotFound)
+	}
+	return item, nil
+}
+
+func GetTrashItems(pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItems(pageIndex, pageSize)
+}
+
+func GetTrashItemsByUserId(userId uint, pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItemsByUserId(userId, pageIndex, pageSize)
+}
+
+func GetTrashItemsDeletedBefore(t time.Time) ([]model.TrashItem, error) {
+	return db.GetTrashItemsDeletedBefore(t)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return db.DeleteTrashItemById(id)
+}
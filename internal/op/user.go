@@ -80,6 +80,10 @@ func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err err
 	return db.GetUsers(pageIndex, pageSize)
 }
 
+func GetAllUsers() ([]model.User, error) {
+	return db.GetAllUsers()
+}
+
 func CreateUser(u *model.User) error {
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
 
@@ -99,6 +103,7 @@ func CreateUser(u *model.User) error {
 		_ = db.UpdateUser(u)
 		userCache.Del(u.Username)
 	}
+	resetQuotaOwners()
 
 	return nil
 }
@@ -112,6 +117,10 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	defer resetQuotaOwners()
+	if err = db.DeleteQuotaUsage(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -127,6 +136,7 @@ func UpdateUser(u *model.User) error {
 		guestUser = nil
 	}
 	userCache.Del(old.Username)
+	defer resetQuotaOwners()
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
 	//if len(u.Role) > 0 {
 	//	roles, err := GetRolesByUserID(u.ID)
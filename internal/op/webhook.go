@@ -0,0 +1,70 @@
+package op
+
+import (
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+)
+
+const enabledWebhooksKey = "enabled"
+
+var webhooksCache = cache.NewMemCache(cache.WithShards[[]model.Webhook](1))
+var webhooksG singleflight.Group[[]model.Webhook]
+
+func GetEnabledWebhooks() ([]model.Webhook, error) {
+	if webhooks, ok := webhooksCache.Get(enabledWebhooksKey); ok {
+		return webhooks, nil
+	}
+	webhooks, err, _ := webhooksG.Do(enabledWebhooksKey, func() ([]model.Webhook, error) {
+		webhooks, err := db.GetEnabledWebhooks()
+		if err != nil {
+			return nil, err
+		}
+		webhooksCache.Set(enabledWebhooksKey, webhooks)
+		return webhooks, nil
+	})
+	return webhooks, err
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func GetWebhooks(pageIndex, pageSize int) ([]model.Webhook, int64, error) {
+	return db.GetWebhooks(pageIndex, pageSize)
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	err := db.CreateWebhook(w)
+	webhooksCache.Del(enabledWebhooksKey)
+	return err
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	err := db.UpdateWebhook(w)
+	webhooksCache.Del(enabledWebhooksKey)
+	return err
+}
+
+func DeleteWebhookById(id uint) error {
+	err := db.DeleteWebhookById(id)
+	webhooksCache.Del(enabledWebhooksKey)
+	return err
+}
+
+func GetWebhookDeliveries(webhookId uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookId, pageIndex, pageSize)
+}
+
+func DeleteWebhookDeliveriesBeyond(keep int) (int64, error) {
+	return db.DeleteWebhookDeliveriesBeyond(keep)
+}
+
+func CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return db.CreateWebhookDelivery(d)
+}
+
+func UpdateWebhookDelivery(d *model.WebhookDelivery) error {
+	return db.UpdateWebhookDelivery(d)
+}
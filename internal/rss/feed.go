@@ -0,0 +1,145 @@
+package rss
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+)
+
+// Item is an item of the rss, rdf or atom feed
+type Item struct {
+	GUID  string
+	Title string
+	// URL is the url to download, the torrent enclosure is preferred to the link
+	URL       string
+	Published *time.Time
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// rssItem has the elements of the rss item and the atom entry
+type rssItem struct {
+	GUID      string        `xml:"guid"`
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Links     []rssLink     `xml:"link"`
+	Enclosure *rssEnclosure `xml:"enclosure"`
+	PubDate   string        `xml:"pubDate"`
+	Date      string        `xml:"date"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+}
+
+type rssDocument struct {
+	XMLName xml.Name
+	// rss 2.0
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// rss 1.0
+	Items []rssItem `xml:"item"`
+	// atom
+	Entries []rssItem `xml:"entry"`
+}
+
+var timeLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// Parse parses the rss 2.0, rss 1.0 or atom feed
+func Parse(r io.Reader) ([]Item, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	d.Strict = false
+	var doc rssDocument
+	if err := d.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "failed parse feed")
+	}
+	var raws []rssItem
+	switch doc.XMLName.Local {
+	case "rss":
+		raws = doc.Channel.Items
+	case "RDF":
+		raws = doc.Items
+	case "feed":
+		raws = doc.Entries
+	default:
+		return nil, errors.Errorf("unknown feed type: %s", doc.XMLName.Local)
+	}
+	items := make([]Item, 0, len(raws))
+	for _, raw := range raws {
+		item := Item{
+			GUID:  strings.TrimSpace(raw.GUID),
+			Title: strings.TrimSpace(raw.Title),
+			URL:   raw.downloadURL(),
+		}
+		if item.GUID == "" {
+			item.GUID = strings.TrimSpace(raw.ID)
+		}
+		if item.GUID == "" {
+			item.GUID = item.URL
+		}
+		if item.GUID == "" || item.URL == "" {
+			continue
+		}
+		for _, s := range []string{raw.PubDate, raw.Published, raw.Date, raw.Updated} {
+			if t, ok := parseTime(s); ok {
+				item.Published = &t
+				break
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// downloadURL prefers the enclosure, then the link to the torrent or the magnet, then the first link
+func (i *rssItem) downloadURL() string {
+	if i.Enclosure != nil && i.Enclosure.URL != "" {
+		return strings.TrimSpace(i.Enclosure.URL)
+	}
+	var first string
+	for _, link := range i.Links {
+		href := strings.TrimSpace(link.Href)
+		if href == "" {
+			href = strings.TrimSpace(link.Text)
+		}
+		if href == "" {
+			continue
+		}
+		if link.Rel == "enclosure" || link.Type == "application/x-bittorrent" ||
+			strings.HasPrefix(href, "magnet:") || strings.HasSuffix(strings.ToLower(href), ".torrent") {
+			return href
+		}
+		if first == "" && (link.Rel == "" || link.Rel == "alternate") {
+			first = href
+		}
+	}
+	return first
+}
+
+func parseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,49 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	datas := []struct {
+		feed  string
+		items []Item
+	}{
+		{
+			feed: `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>t</title>
+<item><title>Show S01E01</title><link>https://example.com/1</link><guid>g1</guid>
+<enclosure url="https://example.com/1.torrent" type="application/x-bittorrent" length="1"/></item>
+<item><title>Show S01E02</title><link>magnet:?xt=urn:btih:abc</link></item>
+</channel></rss>`,
+			items: []Item{
+				{GUID: "g1", Title: "Show S01E01", URL: "https://example.com/1.torrent"},
+				{GUID: "magnet:?xt=urn:btih:abc", Title: "Show S01E02", URL: "magnet:?xt=urn:btih:abc"},
+			},
+		},
+		{
+			feed: `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>tag:1</id><title>Movie</title>
+<link rel="alternate" href="https://example.com/page"/><link rel="enclosure" href="https://example.com/movie.torrent"/>
+<updated>2024-01-02T03:04:05Z</updated></entry></feed>`,
+			items: []Item{{GUID: "tag:1", Title: "Movie", URL: "https://example.com/movie.torrent"}},
+		},
+	}
+	for i, data := range datas {
+		items, err := Parse(strings.NewReader(data.feed))
+		if err != nil {
+			t.Fatalf("parse feed %d: %+v", i, err)
+		}
+		if len(items) != len(data.items) {
+			t.Fatalf("feed %d has %d items, want %d", i, len(items), len(data.items))
+		}
+		for j, item := range items {
+			want := data.items[j]
+			if item.GUID != want.GUID || item.Title != want.Title || item.URL != want.URL {
+				t.Errorf("item %d of feed %d is %+v, want %+v", j, i, item, want)
+			}
+		}
+	}
+	if items, _ := Parse(strings.NewReader(datas[1].feed)); items[0].Published == nil {
+		t.Errorf("the updated time of the atom entry isn't parsed")
+	}
+}
@@ -0,0 +1,180 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+var feedCrons = struct {
+	sync.Mutex
+	m map[uint]*cron.Cron
+	// polling are the feeds being polled, a feed is polled once at a time so that no item is queued twice
+	polling map[uint]struct{}
+}{m: make(map[uint]*cron.Cron), polling: make(map[uint]struct{})}
+
+// Filters compiles the include and exclude regexps of the feed
+func Filters(feed *model.RssFeed) (include, exclude *regexp.Regexp, err error) {
+	if feed.Include != "" {
+		if include, err = regexp.Compile(feed.Include); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid include regexp")
+		}
+	}
+	if feed.Exclude != "" {
+		if exclude, err = regexp.Compile(feed.Exclude); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid exclude regexp")
+		}
+	}
+	return include, exclude, nil
+}
+
+// CanSubscribe checks whether the user can add the offline downloads to the dst path
+func CanSubscribe(user *model.User, dstPath string) bool {
+	return common.CheckPathLimitWithRoles(user, dstPath) &&
+		common.HasPermission(common.MergeRolePermissions(user, dstPath), common.PermAddOfflineDownload)
+}
+
+// Poll fetches the feed and adds the offline downloads of the new items matched, under the user of the feed
+func Poll(ctx context.Context, feed *model.RssFeed) (int, error) {
+	feedCrons.Lock()
+	if _, ok := feedCrons.polling[feed.ID]; ok {
+		feedCrons.Unlock()
+		return 0, errors.New("the feed is being polled")
+	}
+	feedCrons.polling[feed.ID] = struct{}{}
+	feedCrons.Unlock()
+	defer func() {
+		feedCrons.Lock()
+		delete(feedCrons.polling, feed.ID)
+		feedCrons.Unlock()
+	}()
+	queued, err := poll(ctx, feed)
+	result := fmt.Sprintf("%d items queued", queued)
+	if err != nil {
+		result = err.Error()
+	}
+	if err := op.UpdateRssFeedResult(feed.ID, time.Now(), result); err != nil {
+		log.Errorf("failed save the result of rss feed [%s]: %+v", feed.Name, err)
+	}
+	return queued, err
+}
+
+func poll(ctx context.Context, feed *model.RssFeed) (int, error) {
+	user, err := op.GetUserById(feed.UserID)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed get the user of the feed")
+	}
+	dstPath, err := user.JoinPath(feed.DstPath)
+	if err != nil {
+		return 0, err
+	}
+	if user.Disabled || !CanSubscribe(user, dstPath) {
+		return 0, errors.New("the user can't add offline downloads to the dst path")
+	}
+	include, exclude, err := Filters(feed)
+	if err != nil {
+		return 0, err
+	}
+	items, err := fetch(ctx, feed.URL)
+	if err != nil {
+		return 0, err
+	}
+	ctx = context.WithValue(ctx, "user", user)
+	queued := 0
+	for _, item := range items {
+		if (include != nil && !include.MatchString(item.Title)) || (exclude != nil && exclude.MatchString(item.Title)) {
+			continue
+		}
+		old, err := op.GetRssItemByGUID(feed.ID, item.GUID)
+		if err != nil {
+			return queued, err
+		}
+		if old != nil && old.Status == model.RssItemQueued {
+			continue
+		}
+		record := &model.RssItem{
+			FeedID:    feed.ID,
+			GUID:      item.GUID,
+			Title:     item.Title,
+			URL:       item.URL,
+			Published: item.Published,
+			Created:   time.Now(),
+		}
+		if old != nil {
+			record.ID = old.ID
+		}
+		t, err := tool.AddURL(ctx, &tool.AddURLArgs{
+			URL:          item.URL,
+			DstDirPath:   dstPath,
+			Tool:         feed.Tool,
+			DeletePolicy: tool.DeletePolicy(feed.DeletePolicy),
+		})
+		if err != nil {
+			log.Warnf("failed add offline download of rss item [%s]: %+v", item.Title, err)
+			record.Status, record.Error = model.RssItemFailed, err.Error()
+		} else {
+			record.Status = model.RssItemQueued
+			if t != nil {
+				record.TaskID = t.GetID()
+			}
+			queued++
+		}
+		if err = op.SaveRssItem(record); err != nil {
+			return queued, err
+		}
+	}
+	return queued, nil
+}
+
+func fetch(ctx context.Context, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed fetch feed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed fetch feed: %s", resp.Status)
+	}
+	return Parse(resp.Body)
+}
+
+// ScheduleFeed (re)starts the periodic polls of the feed, the old schedule is stopped
+func ScheduleFeed(feed model.RssFeed) {
+	feedCrons.Lock()
+	defer feedCrons.Unlock()
+	if c, ok := feedCrons.m[feed.ID]; ok {
+		c.Stop()
+		delete(feedCrons.m, feed.ID)
+	}
+	if feed.Disabled || feed.Interval <= 0 {
+		return
+	}
+	c := cron.NewCron(time.Duration(feed.Interval) * time.Minute)
+	c.Do(func() {
+		if _, err := Poll(context.Background(), &feed); err != nil {
+			log.Warnf("failed poll rss feed [%s]: %+v", feed.Name, err)
+		}
+	})
+	feedCrons.m[feed.ID] = c
+}
+
+func UnscheduleFeed(id uint) {
+	ScheduleFeed(model.RssFeed{ID: id, Disabled: true})
+}
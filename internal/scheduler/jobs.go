@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type runner struct {
+	run      func(ctx context.Context, args string) (string, error)
+	validate func(args string) error
+}
+
+var runners = map[string]runner{
+	model.JobIndexBuild:     {run: runIndexBuild, validate: validateArgs[IndexBuildArgs]},
+	model.JobIndexUpdate:    {run: runIndexUpdate, validate: validateArgs[IndexUpdateArgs]},
+	model.JobCopy:           {run: runTransfer(false), validate: validateArgs[TransferArgs]},
+	model.JobMove:           {run: runTransfer(true), validate: validateArgs[TransferArgs]},
+	model.JobSync:           {run: runSync, validate: validateArgs[SyncArgs]},
+	model.JobReloadStorages: {run: runReloadStorages},
+	model.JobCleanTasks:     {run: runCleanTasks, validate: validateArgs[CleanTasksArgs]},
+	model.JobCleanTemp:      {run: runCleanTemp, validate: validateArgs[CleanTempArgs]},
+}
+
+type IndexBuildArgs struct {
+	// Incremental only indexes the changed dirs instead of rebuilding the whole index
+	Incremental bool `json:"incremental"`
+}
+
+type IndexUpdateArgs struct {
+	Paths    []string `json:"paths"`
+	MaxDepth int      `json:"max_depth"`
+}
+
+// TransferArgs copies or moves the objects named in the src dir into the dst dir, all objects if no name is given
+type TransferArgs struct {
+	SrcDir string   `json:"src_dir"`
+	DstDir string   `json:"dst_dir"`
+	Names  []string `json:"names"`
+}
+
+type SyncArgs struct {
+	JobID  uint `json:"job_id"`
+	DryRun bool `json:"dry_run"`
+}
+
+type CleanTasksArgs struct {
+	// IncludeFailed removes the failed tasks too, they are kept by default so that they can be retried
+	IncludeFailed bool `json:"include_failed"`
+}
+
+type CleanTempArgs struct {
+	// OlderThanHours keeps the newer temp files which may be used by the running tasks, 24 if not set
+	OlderThanHours int `json:"older_than_hours"`
+}
+
+func decodeArgs[T any](args string) (*T, error) {
+	v := new(T)
+	if args == "" {
+		return v, nil
+	}
+	if err := utils.Json.UnmarshalFromString(args, v); err != nil {
+		return nil, errors.WithMessage(err, "invalid args")
+	}
+	return v, nil
+}
+
+func validateArgs[T any](args string) error {
+	_, err := decodeArgs[T](args)
+	return err
+}
+
+func runIndexBuild(ctx context.Context, args string) (string, error) {
+	a, err := decodeArgs[IndexBuildArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if search.Running() {
+		return "", errors.New("index is running")
+	}
+	if a.Incremental {
+		err = search.BuildIndexIncremental(ctx, []string{"/"}, setting.GetInt(conf.MaxIndexDepth, 20))
+		if err != nil {
+			return "", err
+		}
+		return "index updated incrementally", nil
+	}
+	if err = search.Rebuild(ctx); err != nil {
+		return "", err
+	}
+	return "index built", nil
+}
+
+func runIndexUpdate(ctx context.Context, args string) (string, error) {
+	a, err := decodeArgs[IndexUpdateArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if search.Running() {
+		return "", errors.New("index is running")
+	}
+	if !search.Config(ctx).AutoUpdate {
+		return "", errors.New("update is not supported for current index")
+	}
+	if a.MaxDepth == 0 {
+		a.MaxDepth = setting.GetInt(conf.MaxIndexDepth, 20)
+	}
+	if err = search.UpdatePaths(ctx, a.Paths, a.MaxDepth); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("index of %d paths updated", len(a.Paths)), nil
+}
+
+func runTransfer(move bool) func(ctx context.Context, args string) (string, error) {
+	return func(ctx context.Context, args string) (string, error) {
+		a, err := decodeArgs[TransferArgs](args)
+		if err != nil {
+			return "", err
+		}
+		names := a.Names
+		if len(names) == 0 {
+			objs, err := fs.List(ctx, a.SrcDir, &fs.ListArgs{Refresh: true})
+			if err != nil {
+				return "", err
+			}
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+			}
+		}
+		for i, name := range names {
+			srcPath := stdpath.Join(a.SrcDir, name)
+			if move {
+				err = fs.Move(ctx, srcPath, a.DstDir, len(names) > i+1)
+			} else {
+				_, err = fs.Copy(ctx, srcPath, a.DstDir, len(names) > i+1)
+			}
+			if err != nil {
+				return "", errors.WithMessagef(err, "failed at %s", name)
+			}
+		}
+		if move {
+			return fmt.Sprintf("%d objects moved", len(names)), nil
+		}
+		return fmt.Sprintf("%d objects copied or added to copy tasks", len(names)), nil
+	}
+}
+
+func runSync(ctx context.Context, args string) (string, error) {
+	a, err := decodeArgs[SyncArgs](args)
+	if err != nil {
+		return "", err
+	}
+	job, err := op.GetSyncJobById(a.JobID)
+	if err != nil {
+		return "", err
+	}
+	t, err := fs.RunSyncJob(ctx, job, a.DryRun)
+	if err != nil {
+		return "", err
+	}
+	return "sync task added: " + t.GetID(), nil
+}
+
+func runReloadStorages(ctx context.Context, _ string) (string, error) {
+	storages, err := db.GetEnabledStorages()
+	if err != nil {
+		return "", err
+	}
+	if failed := op.ReloadStorages(ctx, storages); failed > 0 {
+		return "", errors.Errorf("%d of %d storages failed to reload", failed, len(storages))
+	}
+	return fmt.Sprintf("%d storages reloaded", len(storages)), nil
+}
+
+func runCleanTasks(_ context.Context, args string) (string, error) {
+	a, err := decodeArgs[CleanTasksArgs](args)
+	if err != nil {
+		return "", err
+	}
+	states := []tache.State{tache.StateSucceeded, tache.StateCanceled}
+	if a.IncludeFailed {
+		states = append(states, tache.StateFailed, tache.StateErrored)
+	}
+	task.RemoveByState(states...)
+	return "finished tasks removed", nil
+}
+
+// tempFilePatterns match the temp files created for the uploads and the fuse writes, which are left behind
+// if they fail halfway. The dirs such as the ones of the offline download tools may be used by the
+// running tasks for long, they are only cleaned at start.
+var tempFilePatterns = []string{"file-*", "fuse-*"}
+
+func runCleanTemp(_ context.Context, args string) (string, error) {
+	a, err := decodeArgs[CleanTempArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if a.OlderThanHours <= 0 {
+		a.OlderThanHours = 24
+	}
+	before := time.Now().Add(-time.Duration(a.OlderThanHours) * time.Hour)
+	entries, err := os.ReadDir(conf.Conf.TempDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !isTempFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(before) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(conf.Conf.TempDir, e.Name())); err != nil {
+			return "", errors.WithStack(err)
+		}
+		removed++
+	}
+	return fmt.Sprintf("%d temp files removed", removed), nil
+}
+
+func isTempFile(name string) bool {
+	for _, p := range tempFilePatterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+// Package scheduler runs the scheduled jobs defined by the admin at the times given by their cron expressions
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// idleWait is how long the loop sleeps when no job is scheduled
+const idleWait = time.Hour
+
+type entry struct {
+	job      model.ScheduledJob
+	schedule cron.Schedule
+	next     time.Time
+}
+
+var (
+	mu      sync.Mutex
+	entries = make(map[uint]*entry)
+	running = make(map[uint]bool)
+	wake    = make(chan struct{}, 1)
+)
+
+// Validate checks the cron expression, the type and the args of the job
+func Validate(job *model.ScheduledJob) error {
+	if _, err := cron.ParseStandard(job.Cron); err != nil {
+		return errors.WithMessage(err, "invalid cron expression")
+	}
+	r, ok := runners[job.Type]
+	if !ok {
+		return errors.Errorf("unknown job type: %s", job.Type)
+	}
+	if r.validate != nil {
+		return r.validate(job.Args)
+	}
+	return nil
+}
+
+// Start loads the enabled jobs and runs them at their times
+func Start() {
+	jobs, err := op.GetEnabledScheduledJobs()
+	if err != nil {
+		log.Errorf("failed get scheduled jobs: %+v", err)
+	}
+	mu.Lock()
+	for _, job := range jobs {
+		put(job)
+	}
+	mu.Unlock()
+	go loop()
+}
+
+// Put schedules the job again after it's created or updated, disabled jobs are removed from the schedule
+func Put(job model.ScheduledJob) {
+	mu.Lock()
+	put(job)
+	mu.Unlock()
+	notify()
+}
+
+func Remove(id uint) {
+	mu.Lock()
+	delete(entries, id)
+	mu.Unlock()
+	notify()
+}
+
+// RunNow runs the job at once without changing its next run
+func RunNow(job model.ScheduledJob) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if running[job.ID] {
+		return errors.New("the job is running")
+	}
+	running[job.ID] = true
+	go run(job)
+	return nil
+}
+
+func put(job model.ScheduledJob) {
+	delete(entries, job.ID)
+	var next *time.Time
+	defer func() {
+		if err := op.UpdateScheduledJobNextRun(job.ID, next); err != nil {
+			log.Errorf("failed save next run of scheduled job [%s]: %+v", job.Name, err)
+		}
+	}()
+	if job.Disabled {
+		return
+	}
+	schedule, err := cron.ParseStandard(job.Cron)
+	if err != nil {
+		log.Errorf("invalid cron expression of scheduled job [%s]: %+v", job.Name, err)
+		return
+	}
+	e := &entry{job: job, schedule: schedule, next: schedule.Next(time.Now())}
+	entries[job.ID] = e
+	next = &e.next
+}
+
+func notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func loop() {
+	for {
+		mu.Lock()
+		wait := idleWait
+		now := time.Now()
+		for _, e := range entries {
+			if d := e.next.Sub(now); d < wait {
+				wait = d
+			}
+		}
+		mu.Unlock()
+		timer := time.NewTimer(max(wait, 0))
+		select {
+		case <-timer.C:
+			runDue()
+		case <-wake:
+			timer.Stop()
+		}
+	}
+}
+
+func runDue() {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	for _, e := range entries {
+		if e.next.After(now) {
+			continue
+		}
+		e.next = e.schedule.Next(now)
+		if err := op.UpdateScheduledJobNextRun(e.job.ID, &e.next); err != nil {
+			log.Errorf("failed save next run of scheduled job [%s]: %+v", e.job.Name, err)
+		}
+		if running[e.job.ID] {
+			log.Warnf("skip scheduled job [%s], the last run is not finished", e.job.Name)
+			continue
+		}
+		running[e.job.ID] = true
+		go run(e.job)
+	}
+}
+
+func run(job model.ScheduledJob) {
+	defer func() {
+		mu.Lock()
+		delete(running, job.ID)
+		mu.Unlock()
+	}()
+	start := time.Now()
+	result, err := runJob(job)
+	if err != nil {
+		log.Errorf("failed run scheduled job [%s]: %+v", job.Name, err)
+		result = err.Error()
+	} else {
+		log.Infof("scheduled job [%s] done: %s", job.Name, result)
+	}
+	if err := op.UpdateScheduledJobResult(job.ID, start, err == nil, result); err != nil {
+		log.Errorf("failed save result of scheduled job [%s]: %+v", job.Name, err)
+	}
+}
+
+func runJob(job model.ScheduledJob) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	r, ok := runners[job.Type]
+	if !ok {
+		return "", errors.Errorf("unknown job type: %s", job.Type)
+	}
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get admin")
+	}
+	// the jobs act as the admin, e.g. the copy tasks are created by the admin
+	ctx := context.WithValue(context.Background(), "user", admin)
+	return r.run(ctx, job.Args)
+}
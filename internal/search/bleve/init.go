@@ -8,7 +8,8 @@ import (
 )
 
 var config = searcher.Config{
-	Name: "bleve",
+	Name:    "bleve",
+	Content: true,
 }
 
 func Init(indexPath *string) (bleve.Index, error) {
@@ -25,6 +26,13 @@ func Init(indexPath *string) (bleve.Index, error) {
 		// TODO: appoint analyzer
 		nameFieldMapping := bleve.NewKeywordFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
+		searchNodeMapping.AddFieldMappingsAt("size", bleve.NewNumericFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("modified", bleve.NewDateTimeFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("mime", bleve.NewKeywordFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("hash", bleve.NewKeywordFieldMapping())
+		// stored with the term vectors for the highlights
+		searchNodeMapping.AddFieldMappingsAt("content", bleve.NewTextFieldMapping())
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
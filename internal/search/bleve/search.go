@@ -3,6 +3,8 @@ package bleve
 import (
 	"context"
 	"os"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/blevesearch/bleve/v2"
 	search2 "github.com/blevesearch/bleve/v2/search"
@@ -17,6 +20,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// inMemoryBatch is how many hits are fetched at once when the nodes are checked in memory
+const inMemoryBatch = 1000
+
 type Bleve struct {
 	BIndex bleve.Index
 }
@@ -27,36 +33,180 @@ func (b *Bleve) Config() searcher.Config {
 
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	searchContent := setting.GetBool(conf.SearchContent)
+	switch req.Match {
+	case model.MatchGlob:
+		query := bleve.NewWildcardQuery(req.Keywords)
+		query.SetField("name")
+		queries = append(queries, query)
+	case model.MatchRegex:
+		query := bleve.NewRegexpQuery(termRegexp(req.Keywords))
+		query.SetField("name")
+		queries = append(queries, query)
+	default:
+		if req.Keywords != "" {
+			query := bleve.NewMatchQuery(req.Keywords)
+			query.SetField("name")
+			if searchContent {
+				contentQuery := bleve.NewMatchQuery(req.Keywords)
+				contentQuery.SetField("content")
+				queries = append(queries, bleve.NewDisjunctionQuery(query, contentQuery))
+			} else {
+				queries = append(queries, query)
+			}
+		}
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
+		isDirQuery.SetField("is_dir")
 		queries = append(queries, isDirQuery)
 	}
-	reqQuery := bleve.NewConjunctionQuery(queries...)
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var min, max *float64
+		if req.MinSize > 0 {
+			v := float64(req.MinSize)
+			min = &v
+		}
+		if req.MaxSize > 0 {
+			v := float64(req.MaxSize)
+			max = &v
+		}
+		inclusive := true
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if req.ModifiedAfter > 0 || req.ModifiedBefore > 0 {
+		var start, end time.Time
+		if req.ModifiedAfter > 0 {
+			start = time.Unix(req.ModifiedAfter, 0)
+		}
+		if req.ModifiedBefore > 0 {
+			end = time.Unix(req.ModifiedBefore, 0)
+		}
+		inclusive := true
+		modifiedQuery := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
+	if len(req.Exts) > 0 {
+		extQueries := make([]query2.Query, len(req.Exts))
+		for i, ext := range req.Exts {
+			extQuery := bleve.NewTermQuery(ext)
+			extQuery.SetField("ext")
+			extQueries[i] = extQuery
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
+	if req.Hash != "" {
+		hashQuery := bleve.NewWildcardQuery("*" + req.Hash + "*")
+		hashQuery.SetField("hash")
+		queries = append(queries, hashQuery)
+	}
+	var reqQuery query2.Query = bleve.NewMatchAllQuery()
+	if len(queries) > 0 {
+		reqQuery = bleve.NewConjunctionQuery(queries...)
+	}
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	orderBy := "name"
+	if req.OrderBy != "" {
+		orderBy = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		orderBy = "-" + orderBy
+	}
+	search.SortBy([]string{orderBy})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	// the content is only used for the highlights
+	search.Fields = []string{"parent", "name", "is_dir", "size", "modified", "ext", "mime", "hash"}
+	if searchContent && req.Match == model.MatchKeywords && req.Keywords != "" {
+		search.Highlight = bleve.NewHighlightWithStyle("html")
+		search.Highlight.AddField("content")
+	}
+	// the parent is analyzed as the text and the access can't be queried, they are checked in memory
+	if req.Parent != "/" || req.Access.Restricted() {
+		return b.searchInMemory(ctx, req, search)
+	}
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
-			Parent: src.Fields["parent"].(string),
-			Name:   src.Fields["name"].(string),
-			IsDir:  src.Fields["is_dir"].(bool),
-			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		return toSearchNode(src), nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+func (b *Bleve) searchInMemory(ctx context.Context, req model.SearchReq, search *bleve.SearchRequest) ([]model.SearchNode, int64, error) {
+	search.From, search.Size = 0, inMemoryBatch
+	var (
+		count int64
+		nodes []model.SearchNode
+		from  = int64((req.Page - 1) * req.PerPage)
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		searchResults, err := b.BIndex.Search(search)
+		if err != nil {
+			log.Errorf("search error: %+v", err)
+			return nil, 0, err
+		}
+		for _, hit := range searchResults.Hits {
+			node := toSearchNode(hit)
+			if !req.MatchFilters(&node) {
+				continue
+			}
+			if count >= from && len(nodes) < req.PerPage {
+				nodes = append(nodes, node)
+			}
+			count++
+		}
+		if len(searchResults.Hits) < inMemoryBatch {
+			return nodes, count, nil
+		}
+		search.From += inMemoryBatch
+	}
+}
+
+func toSearchNode(src *search2.DocumentMatch) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src.Fields["parent"].(string),
+		Name:   src.Fields["name"].(string),
+		IsDir:  src.Fields["is_dir"].(bool),
+		Size:   int64(src.Fields["size"].(float64)),
+	}
+	// the fields are missing in the index built by the old versions
+	node.Ext, _ = src.Fields["ext"].(string)
+	node.Mime, _ = src.Fields["mime"].(string)
+	node.Hash, _ = src.Fields["hash"].(string)
+	if modified, ok := src.Fields["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	node.Highlights = src.Fragments["content"]
+	return node
+}
+
+// termRegexp makes the regexp matching the whole term behave like a search in the name,
+// the anchors aren't supported by the index so they only decide whether to pad the regexp
+func termRegexp(re string) string {
+	if strings.HasPrefix(re, "^") {
+		re = re[1:]
+	} else {
+		re = ".*" + re
+	}
+	if strings.HasSuffix(re, "$") && !strings.HasSuffix(re, `\$`) {
+		re = re[:len(re)-1]
+	} else {
+		re = re + ".*"
+	}
+	return re
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
 	return b.BIndex.Index(uuid.NewString(), node)
 }
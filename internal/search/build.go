@@ -18,7 +18,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/mq"
 	"github.com/alist-org/alist/v3/pkg/utils"
-	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -187,11 +187,35 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 	return nil
 }
 
+// Rebuild clears the index and builds it for all paths
+func Rebuild(ctx context.Context) error {
+	if err := Clear(ctx); err != nil {
+		return errors.WithMessage(err, "failed clear index")
+	}
+	return BuildIndex(ctx, []string{"/"}, conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), true)
+}
+
+// UpdatePaths builds the index of the paths again
+func UpdatePaths(ctx context.Context, paths []string, maxDepth int) error {
+	for _, path := range paths {
+		if err := Del(ctx, path); err != nil {
+			return errors.WithMessagef(err, "failed delete index on %s", path)
+		}
+		if err := op.DeleteIndexDirs(utils.FixAndCleanPath(path)); err != nil {
+			return err
+		}
+	}
+	return BuildIndex(ctx, paths, conf.SlicesMap[conf.IgnorePaths], maxDepth, false)
+}
+
 func Del(ctx context.Context, prefix string) error {
 	return instance.Del(ctx, prefix)
 }
 
 func Clear(ctx context.Context) error {
+	if err := op.ClearIndexDirs(); err != nil {
+		return err
+	}
 	return instance.Clear(ctx)
 }
 
@@ -216,53 +240,36 @@ func Update(parent string, objs []model.Obj) {
 	if !progress.IsDone {
 		return
 	}
-	nodes, err := instance.Get(ctx, parent)
+	old, err := op.GetIndexDir(parent)
 	if err != nil {
-		log.Errorf("update search index error while get nodes: %+v", err)
+		log.Errorf("update search index error while get index dir: %+v", err)
 		return
 	}
-	now := mapset.NewSet[string]()
-	for i := range objs {
-		now.Add(objs[i].GetName())
+	fp := fingerprint(parent, objs)
+	if old != nil {
+		if old.Etag == fp.Etag {
+			return
+		}
+		fp.ModTime, fp.Generation, fp.Done = old.ModTime, old.Generation, old.Done
 	}
-	old := mapset.NewSet[string]()
-	for i := range nodes {
-		old.Add(nodes[i].Name)
+	newDirs, err := indexChildren(ctx, parent, objs)
+	if err != nil {
+		log.Errorf("update search index error: %+v", err)
+		return
 	}
-	// delete data that no longer exists
-	toDelete := old.Difference(now)
-	toAdd := now.Difference(old)
-	for i := range nodes {
-		if toDelete.Contains(nodes[i].Name) && !op.HasStorage(path.Join(parent, nodes[i].Name)) {
-			log.Debugf("delete index: %s", path.Join(parent, nodes[i].Name))
-			err = instance.Del(ctx, path.Join(parent, nodes[i].Name))
-			if err != nil {
-				log.Errorf("update search index error while del old node: %+v", err)
-				return
-			}
-		}
+	if err = op.SaveIndexDir(fp); err != nil {
+		log.Errorf("update search index error while save index dir: %+v", err)
 	}
-	for i := range objs {
-		if toAdd.Contains(objs[i].GetName()) {
-			if !objs[i].IsDir() {
-				log.Debugf("add index: %s", path.Join(parent, objs[i].GetName()))
-				err = Index(ctx, parent, objs[i])
-				if err != nil {
-					log.Errorf("update search index error while index new node: %+v", err)
-					return
-				}
-			} else {
-				// build index if it's a folder
-				dir := path.Join(parent, objs[i].GetName())
-				err = BuildIndex(ctx,
-					[]string{dir},
-					conf.SlicesMap[conf.IgnorePaths],
-					setting.GetInt(conf.MaxIndexDepth, 20)-strings.Count(dir, "/"), false)
-				if err != nil {
-					log.Errorf("update search index error while build index: %+v", err)
-					return
-				}
-			}
+	// build index of the new folders
+	for _, name := range newDirs {
+		dir := path.Join(parent, name)
+		err = BuildIndex(ctx,
+			[]string{dir},
+			conf.SlicesMap[conf.IgnorePaths],
+			setting.GetInt(conf.MaxIndexDepth, 20)-strings.Count(dir, "/"), false)
+		if err != nil {
+			log.Errorf("update search index error while build index: %+v", err)
+			return
 		}
 	}
 }
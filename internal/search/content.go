@@ -0,0 +1,187 @@
+package search
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/ledongthuc/pdf"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// officeParts are the xml files holding the text in the office files, a part ending with / is a prefix
+var officeParts = map[string]string{
+	"docx": "word/document.xml",
+	"pptx": "ppt/slides/",
+	"xlsx": "xl/sharedStrings.xml",
+}
+
+func contentEnabled() bool {
+	return instance != nil && instance.Config().Content && setting.GetBool(conf.SearchContent)
+}
+
+// withContent sets the content of the node if the content index is enabled, the files failed to extract are indexed without the content
+func withContent(ctx context.Context, node *model.SearchNode, obj model.Obj) {
+	if node.IsDir || !contentEnabled() {
+		return
+	}
+	content, err := extractContent(ctx, path.Join(node.Parent, node.Name), obj)
+	if err != nil {
+		log.Warnf("failed extract content of %s: %+v", path.Join(node.Parent, node.Name), err)
+		return
+	}
+	node.Content = content
+}
+
+// extractContent returns the text of the text, pdf and office files, cut to search_content_max_size
+func extractContent(ctx context.Context, filePath string, obj model.Obj) (string, error) {
+	ext := utils.Ext(obj.GetName())
+	isText := utils.SliceContains(conf.SlicesMap[conf.TextTypes], ext)
+	_, isOffice := officeParts[ext]
+	if obj.GetSize() == 0 || !(isText || isOffice || ext == "pdf") {
+		return "", nil
+	}
+	maxSize := int64(setting.GetInt(conf.SearchContentMaxSize, 1024)) * 1024
+	// the pdf and office files are read fully, so the big ones are skipped
+	if !isText && obj.GetSize() > int64(setting.GetInt(conf.SearchContentMaxFileSize, 50))*1024*1024 {
+		return "", nil
+	}
+	link, _, err := fs.Link(ctx, filePath, model.LinkArgs{})
+	if err != nil {
+		return "", err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		return "", err
+	}
+	defer ss.Close()
+	var text string
+	if isText {
+		r, err := ss.RangeRead(http_range.Range{Length: min(obj.GetSize(), maxSize)})
+		if err != nil {
+			return "", err
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		text = string(b)
+	} else {
+		f, err := ss.CacheFullInTempFile()
+		if err != nil {
+			return "", err
+		}
+		if ext == "pdf" {
+			text, err = pdfText(f, obj.GetSize())
+		} else {
+			text, err = officeText(f, obj.GetSize(), officeParts[ext])
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return cutText(text, int(maxSize)), nil
+}
+
+func pdfText(f io.ReaderAt, size int64) (text string, err error) {
+	// the parser panics on some broken files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed parse pdf: %v", r)
+		}
+	}()
+	r, err := pdf.NewReader(f, size)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	plain, err := r.GetPlainText()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	b, err := io.ReadAll(plain)
+	return string(b), errors.WithStack(err)
+}
+
+// officeText returns the text in the elements named t of the parts, which are the runs of docx, pptx and xlsx
+func officeText(f io.ReaderAt, size int64, part string) (string, error) {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var files []*zip.File
+	for _, file := range zr.File {
+		if file.Name == part || (strings.HasSuffix(part, "/") && strings.HasPrefix(file.Name, part) &&
+			path.Dir(file.Name)+"/" == part && strings.HasSuffix(file.Name, ".xml")) {
+			files = append(files, file)
+		}
+	}
+	// slide10 after slide9
+	sort.Slice(files, func(i, j int) bool {
+		if len(files[i].Name) != len(files[j].Name) {
+			return len(files[i].Name) < len(files[j].Name)
+		}
+		return files[i].Name < files[j].Name
+	})
+	var b strings.Builder
+	for _, file := range files {
+		if err = xmlText(file, &b); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func xmlText(file *zip.File, b *strings.Builder) error {
+	rc, err := file.Open()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rc.Close()
+	d := xml.NewDecoder(rc)
+	inText := false
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			// the paragraphs of docx and pptx, the strings of xlsx
+			if t.Name.Local == "p" || t.Name.Local == "si" {
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+// cutText cuts the text to max bytes
+func cutText(text string, max int) string {
+	if len(text) > max {
+		text = text[:max]
+	}
+	// drops the rune broken by the cut, and the invalid bytes of the text in other encodings
+	return strings.ToValidUTF8(text, "")
+}
@@ -0,0 +1,36 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestOfficeText(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"ppt/slides/slide10.xml":           `<p:sld><a:p><a:r><a:t>ten</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/slide9.xml":            `<p:sld><a:p><a:r><a:t>nine</a:t></a:r><a:r><a:t> &amp; more</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/_rels/slide9.xml.rels": `<Relationships><t>ignored</t></Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml":  `<p:notes><a:t>ignored</a:t></p:notes>`,
+		"docProps/app.xml":                 `<Properties><t>ignored</t></Properties>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	text, err := officeText(bytes.NewReader(buf.Bytes()), int64(buf.Len()), officeParts["pptx"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "nine & more\nten\n"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+}
@@ -0,0 +1,20 @@
+package db_fts
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+)
+
+var config = searcher.Config{
+	Name:       "database_fts",
+	AutoUpdate: true,
+}
+
+func init() {
+	searcher.RegisterSearcher(config, func() (searcher.Searcher, error) {
+		if err := db.InitSearchNodeFts(); err != nil {
+			return nil, err
+		}
+		return &DB{}, nil
+	})
+}
@@ -0,0 +1,57 @@
+package db_fts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+)
+
+// DB searches the tokens of the names in the sqlite fts5 table or the postgres tsvector,
+// the nodes are stored in the search nodes table like the database searcher
+type DB struct{}
+
+func (D DB) Config() searcher.Config {
+	return config
+}
+
+func (D DB) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	tokens := tokenize(req.Keywords, false)
+	// the glob and the regex aren't tokenized
+	if req.Match != model.MatchKeywords || len(tokens) == 0 {
+		return db.SearchNode(req, false)
+	}
+	return db.SearchNodeFts(req, tokens)
+}
+
+func (D DB) Index(ctx context.Context, node model.SearchNode) error {
+	return D.BatchIndex(ctx, []model.SearchNode{node})
+}
+
+func (D DB) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
+	tokens := make([]string, len(nodes))
+	for i := range nodes {
+		tokens[i] = strings.Join(tokenize(nodes[i].Name, true), " ")
+	}
+	return db.BatchCreateSearchNodesFts(nodes, tokens)
+}
+
+func (D DB) Get(ctx context.Context, parent string) ([]model.SearchNode, error) {
+	return db.GetSearchNodesByParent(parent)
+}
+
+func (D DB) Del(ctx context.Context, path string) error {
+	return db.DeleteSearchNodesFtsByParent(path)
+}
+
+func (D DB) Release(ctx context.Context) error {
+	return nil
+}
+
+func (D DB) Clear(ctx context.Context) error {
+	return db.ClearSearchNodesFts()
+}
+
+var _ searcher.Searcher = (*DB)(nil)
@@ -0,0 +1,57 @@
+package db_fts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenize splits the text into the lower case words, the runs of CJK characters which have no spaces between
+// the words are split into the bigrams, and also the single characters for the index so that one character can be searched
+func tokenize(text string, index bool) []string {
+	var (
+		tokens []string
+		word   []rune
+		cjk    []rune
+	)
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			if index {
+				tokens = append(tokens, string(cjk[i]))
+			}
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		if index && len(cjk) > 1 {
+			tokens = append(tokens, string(cjk[len(cjk)-1]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,24 @@
+package db_fts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text  string
+		index bool
+		want  []string
+	}{
+		{"Movie.S01E02.mkv", true, []string{"movie", "s01e02", "mkv"}},
+		{"中文字幕.srt", true, []string{"中", "中文", "文", "文字", "字", "字幕", "幕", "srt"}},
+		{"中文字幕", false, []string{"中文", "文字", "字幕"}},
+		{"字", false, []string{"字"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text, tt.index); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q, %v) = %q, want %q", tt.text, tt.index, got, tt.want)
+		}
+	}
+}
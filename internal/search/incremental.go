@@ -0,0 +1,316 @@
+package search
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var errIndexStopped = errors.New("index is stopped")
+
+// the changed dirs are indexed again after no change is made in them for a while
+const changedDirsDelay = 5 * time.Second
+
+type incrementalIndexer struct {
+	ctx          context.Context
+	generation   int64
+	quit         chan struct{}
+	ignorePaths  []string
+	objCount     uint64
+	failed       int
+	lastProgress time.Time
+}
+
+// BuildIndexIncremental indexes the paths again but only the dirs whose children are changed since the last run.
+// The children are indexed again only if the fingerprint of the dir is changed, and the subtree below is skipped
+// if the dir is unchanged and still done, since the changes made by alist mark the dirs above not done.
+// The subtrees finished by the run resumed are skipped as well.
+// It's unavailable for bleve, which can't get the indexed children of a dir to diff with.
+func BuildIndexIncremental(ctx context.Context, indexPaths []string, maxDepth int) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	if !instance.Config().AutoUpdate {
+		return errors.New("incremental index is not supported for current index")
+	}
+	quit := make(chan struct{}, 1)
+	if !Quit.CompareAndSwap(nil, &quit) {
+		return errs.BuildIndexIsRunning
+	}
+	defer Quit.Store(nil)
+	progress, err := Progress()
+	if err != nil {
+		return err
+	}
+	generation := time.Now().UnixNano()
+	if progress.Generation != 0 && !progress.IsDone {
+		generation = progress.Generation
+		log.Infof("resume incremental index of generation %d", generation)
+	}
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	ix := &incrementalIndexer{
+		ctx:         context.WithValue(ctx, "user", admin),
+		generation:  generation,
+		quit:        quit,
+		ignorePaths: conf.SlicesMap[conf.IgnorePaths],
+	}
+	WriteProgress(&model.IndexProgress{Generation: generation})
+	log.Infof("build incremental index for: %+v", indexPaths)
+	for _, indexPath := range indexPaths {
+		indexPath = utils.FixAndCleanPath(indexPath)
+		var dir model.Obj
+		dir, err = fs.Get(ix.ctx, indexPath, &fs.GetArgs{})
+		if err != nil {
+			break
+		}
+		if err = ix.visit(indexPath, dir, maxDepth); err != nil {
+			break
+		}
+	}
+	if err == nil && ix.failed > 0 {
+		err = errors.Errorf("failed list %d dirs, they will be indexed in the next run", ix.failed)
+	}
+	p := &model.IndexProgress{
+		ObjCount:   ix.objCount,
+		IsDone:     err == nil || ix.failed > 0,
+		Generation: generation,
+	}
+	if p.IsDone {
+		now := time.Now()
+		p.LastDoneTime = &now
+	}
+	if err != nil {
+		log.Errorf("build incremental index error: %+v", err)
+		p.Error = err.Error()
+	} else {
+		log.Infof("success build incremental index, count: %d", ix.objCount)
+	}
+	WriteProgress(p)
+	return err
+}
+
+func (ix *incrementalIndexer) visit(dirPath string, dir model.Obj, depth int) error {
+	select {
+	case <-ix.quit:
+		return errIndexStopped
+	default:
+	}
+	for _, ignorePath := range ix.ignorePaths {
+		if strings.HasPrefix(dirPath, ignorePath) {
+			return nil
+		}
+	}
+	if storage, _, err := op.GetStorageAndActualPath(dirPath); err == nil && storage.GetStorage().DisableIndex {
+		return nil
+	}
+	old, err := op.GetIndexDir(dirPath)
+	if err != nil {
+		return err
+	}
+	if old != nil && old.Done && old.Generation == ix.generation {
+		return nil
+	}
+	objs, err := fs.List(ix.ctx, dirPath, &fs.ListArgs{Refresh: true})
+	if err != nil {
+		log.Warnf("failed list %s while building incremental index: %+v", dirPath, err)
+		ix.failed++
+		return nil
+	}
+	fp := fingerprint(dirPath, objs)
+	fp.ModTime = dir.ModTime()
+	fp.Generation = ix.generation
+	// no change is made by alist in the subtree since it's done, and the dir is the same as it's listed by
+	// the parent and by itself, the changes made outside deeper in the subtree are found by the full index
+	fp.Done = old != nil && old.Done && unchanged(old, fp)
+	if old == nil || old.Etag != fp.Etag {
+		if _, err = indexChildren(ix.ctx, dirPath, objs); err != nil {
+			return err
+		}
+	}
+	if err = op.SaveIndexDir(fp); err != nil {
+		return err
+	}
+	ix.objCount += uint64(len(objs))
+	if time.Since(ix.lastProgress) > 5*time.Second {
+		ix.lastProgress = time.Now()
+		WriteProgress(&model.IndexProgress{ObjCount: ix.objCount, Generation: ix.generation})
+	}
+	if fp.Done {
+		return nil
+	}
+	if depth != 1 {
+		for _, obj := range objs {
+			if !obj.IsDir() {
+				continue
+			}
+			if err = ix.visit(path.Join(dirPath, obj.GetName()), obj, depth-1); err != nil {
+				return err
+			}
+		}
+	}
+	return op.SetIndexDirDone(dirPath, true)
+}
+
+// unchanged reports whether the dir has the same mod time, which is unknown if zero, and the same children
+func unchanged(old, fp *model.IndexDir) bool {
+	return !fp.ModTime.IsZero() && old.ModTime.Equal(fp.ModTime) && old.ChildCount == fp.ChildCount && old.Etag == fp.Etag
+}
+
+func fingerprint(dirPath string, objs []model.Obj) *model.IndexDir {
+	fp := &model.IndexDir{Path: dirPath, ChildCount: len(objs)}
+	lines := make([]string, len(objs))
+	for i, obj := range objs {
+		lines[i] = fmt.Sprintf("%s|%v|%d|%d", obj.GetName(), obj.IsDir(), obj.GetSize(), obj.ModTime().Unix())
+	}
+	sort.Strings(lines)
+	h := sha1.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	fp.Etag = hex.EncodeToString(h.Sum(nil))
+	return fp
+}
+
+// indexChildren updates the index of the children of the parent by the diff with the nodes indexed, it returns the new dirs
+func indexChildren(ctx context.Context, parent string, objs []model.Obj) ([]string, error) {
+	nodes, err := instance.Get(ctx, parent)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get nodes")
+	}
+	now := make(map[string]model.Obj, len(objs))
+	for _, obj := range objs {
+		now[obj.GetName()] = obj
+	}
+	old := make(map[string]model.SearchNode, len(nodes))
+	for _, node := range nodes {
+		old[node.Name] = node
+		obj, ok := now[node.Name]
+		if ok && !nodeChanged(node, obj) {
+			continue
+		}
+		nodePath := path.Join(parent, node.Name)
+		// the mount points are listed by their parents
+		if !ok && op.HasStorage(nodePath) {
+			continue
+		}
+		log.Debugf("delete index: %s", nodePath)
+		if err = instance.Del(ctx, nodePath); err != nil {
+			return nil, errors.WithMessagef(err, "failed delete index of %s", nodePath)
+		}
+		if node.IsDir {
+			if err = op.DeleteIndexDirs(nodePath); err != nil {
+				return nil, err
+			}
+		}
+		delete(old, node.Name)
+	}
+	var (
+		toIndex []model.SearchNode
+		newDirs []string
+	)
+	for _, obj := range objs {
+		if _, ok := old[obj.GetName()]; ok {
+			continue
+		}
+		node := toSearchNode(parent, obj)
+		withContent(ctx, &node, obj)
+		toIndex = append(toIndex, node)
+		if obj.IsDir() {
+			newDirs = append(newDirs, obj.GetName())
+		}
+	}
+	if len(toIndex) > 0 {
+		if err = instance.BatchIndex(ctx, toIndex); err != nil {
+			return nil, errors.WithMessage(err, "failed index nodes")
+		}
+	}
+	return newDirs, nil
+}
+
+// nodeChanged checks the type of the node, and the size and the modified time of the file,
+// the dirs are compared by their fingerprints so that the subtree isn't deleted
+func nodeChanged(node model.SearchNode, obj model.Obj) bool {
+	if node.IsDir != obj.IsDir() {
+		return true
+	}
+	return !node.IsDir && (node.Size != obj.GetSize() || node.Modified.Unix() != obj.ModTime().Unix())
+}
+
+var (
+	changedDirsMu sync.Mutex
+	changedDirs   = make(map[string]struct{})
+	indexChanged  = utils.NewDebounce2(changedDirsDelay, indexChangedDirs)
+)
+
+// onDirsChanged marks the dirs and their parents not done so that the next incremental index doesn't skip them,
+// and lists the dirs again later to update the index by the objs update hook
+func onDirsChanged(dirs ...string) {
+	if instance == nil || !instance.Config().AutoUpdate {
+		return
+	}
+	paths := mapset.NewSet[string]()
+	for _, dir := range dirs {
+		for p := dir; ; p = path.Dir(p) {
+			paths.Add(p)
+			if p == "/" {
+				break
+			}
+		}
+	}
+	if err := op.UndoneIndexDirs(paths.ToSlice()); err != nil {
+		log.Errorf("failed mark the changed dirs of index: %+v", err)
+	}
+	if !setting.GetBool(conf.AutoUpdateIndex) {
+		return
+	}
+	changedDirsMu.Lock()
+	for _, dir := range dirs {
+		changedDirs[dir] = struct{}{}
+	}
+	changedDirsMu.Unlock()
+	indexChanged()
+}
+
+func indexChangedDirs() {
+	changedDirsMu.Lock()
+	dirs := changedDirs
+	changedDirs = make(map[string]struct{})
+	changedDirsMu.Unlock()
+	admin, err := op.GetAdmin()
+	if err != nil {
+		log.Errorf("failed get admin: %+v", err)
+		return
+	}
+	ctx := context.WithValue(context.Background(), "user", admin)
+	for dir := range dirs {
+		// the objs update hook updates the index
+		if _, err = fs.List(ctx, dir, &fs.ListArgs{Refresh: true}); err != nil {
+			log.Warnf("failed list changed dir %s: %+v", dir, err)
+		}
+	}
+}
+
+func init() {
+	op.RegisterDirsChangedHook(onDirsChanged)
+}
@@ -13,6 +13,7 @@ import (
 var config = searcher.Config{
 	Name:       "meilisearch",
 	AutoUpdate: true,
+	Content:    true,
 }
 
 func init() {
@@ -23,8 +24,9 @@ func init() {
 				APIKey: conf.Conf.Meilisearch.APIKey,
 			}),
 			IndexUid:             conf.Conf.Meilisearch.IndexPrefix + "alist",
-			FilterableAttributes: []string{"parent", "is_dir", "name"},
-			SearchableAttributes: []string{"name"},
+			FilterableAttributes: []string{"parent", "is_dir", "name", "size", "modified_unix", "ext"},
+			SearchableAttributes: []string{"name", "content"},
+			SortableAttributes:   []string{"name", "size", "modified_unix"},
 		}
 
 		_, err := m.Client.GetIndex(m.IndexUid)
@@ -72,6 +74,17 @@ func init() {
 			}
 		}
 
+		attributes, err = m.Client.Index(m.IndexUid).GetSortableAttributes()
+		if err != nil {
+			return nil, err
+		}
+		if attributes == nil || !utils.SliceAllContains(*attributes, m.SortableAttributes...) {
+			_, err = m.Client.Index(m.IndexUid).UpdateSortableAttributes(&m.SortableAttributes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		pagination, err := m.Client.Index(m.IndexUid).GetPagination()
 		if err != nil {
 			return nil, err
@@ -3,11 +3,14 @@ package meilisearch
 import (
 	"context"
 	"fmt"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
+	"html"
 	"path"
 	"strings"
 	"time"
@@ -16,13 +19,27 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// ModifiedUnix is for the filter and the sort, which don't support the time
+	ModifiedUnix int64 `json:"modified_unix"`
 }
 
+const (
+	// inMemoryBatch is how many documents are fetched at once when the names are matched in memory
+	inMemoryBatch = 1000
+	// the tags are replaced by the mark after the snippet is escaped
+	highlightPreTag  = "\x00"
+	highlightPostTag = "\x01"
+)
+
+// retrievedAttributes are all the attributes except the content
+var retrievedAttributes = []string{"parent", "name", "is_dir", "size", "modified", "ext", "mime", "hash"}
+
 type Meilisearch struct {
 	Client               *meilisearch.Client
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -31,25 +48,43 @@ func (m *Meilisearch) Config() searcher.Config {
 
 func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	mReq := &meilisearch.SearchRequest{
-		AttributesToSearchOn: m.SearchableAttributes,
+		AttributesToSearchOn: []string{"name"},
+		AttributesToRetrieve: retrievedAttributes,
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
+		Filter:               searchFilter(req),
 	}
-	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+	if setting.GetBool(conf.SearchContent) {
+		mReq.AttributesToSearchOn = m.SearchableAttributes
+		mReq.AttributesToCrop = []string{"content"}
+		mReq.CropLength = 30
+		mReq.HighlightPreTag = highlightPreTag
+		mReq.HighlightPostTag = highlightPostTag
+	}
+	orderBy := "name"
+	switch req.OrderBy {
+	case "size":
+		orderBy = "size"
+	case "modified":
+		orderBy = "modified_unix"
+	}
+	if req.OrderBy != "" || req.OrderDirection != "" {
+		direction := "asc"
+		if req.OrderDirection != "" {
+			direction = req.OrderDirection
+		}
+		mReq.Sort = []string{orderBy + ":" + direction}
+	}
+	// the glob, the regex, the hash, the parent and the access can't be matched by meilisearch
+	if req.Match != model.MatchKeywords || req.Hash != "" || req.Parent != "/" || req.Access.Restricted() {
+		return m.searchInMemory(ctx, req, mReq)
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		return hitToSearchNode(src.(map[string]any)), nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -57,6 +92,110 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 	return nodes, search.TotalHits, nil
 }
 
+func (m *Meilisearch) searchInMemory(ctx context.Context, req model.SearchReq, mReq *meilisearch.SearchRequest) ([]model.SearchNode, int64, error) {
+	match, err := req.NameMatcher()
+	if err != nil {
+		return nil, 0, err
+	}
+	// the keywords are still searched by meilisearch, which matches the content too
+	query := ""
+	if req.Match == model.MatchKeywords {
+		query = req.Keywords
+		match = func(string) bool { return true }
+	}
+	mReq.Page, mReq.HitsPerPage = 0, 0
+	mReq.Limit = inMemoryBatch
+	var (
+		count int64
+		nodes []model.SearchNode
+		from  = int64((req.Page - 1) * req.PerPage)
+	)
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		search, err := m.Client.Index(m.IndexUid).Search(query, mReq)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, hit := range search.Hits {
+			node := hitToSearchNode(hit.(map[string]any))
+			if !match(node.Name) || !req.MatchFilters(&node) {
+				continue
+			}
+			if count >= from && len(nodes) < req.PerPage {
+				nodes = append(nodes, node)
+			}
+			count++
+		}
+		if len(search.Hits) < inMemoryBatch {
+			return nodes, count, nil
+		}
+		mReq.Offset += inMemoryBatch
+	}
+}
+
+// hitToSearchNode converts the hit with the highlight of the content
+func hitToSearchNode(hit map[string]any) model.SearchNode {
+	node := toSearchNode(hit)
+	if formatted, ok := hit["_formatted"].(map[string]any); ok {
+		if snippet, ok := formatted["content"].(string); ok && strings.Contains(snippet, highlightPreTag) {
+			node.Highlights = []string{highlightSnippet(snippet)}
+		}
+	}
+	return node
+}
+
+// highlightSnippet escapes the snippet and marks the matched words like the html highlights of bleve
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	return strings.NewReplacer(highlightPreTag, "<mark>", highlightPostTag, "</mark>").Replace(snippet)
+}
+
+func searchFilter(req model.SearchReq) []string {
+	var filters []string
+	if req.Scope != 0 {
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedAfter > 0 {
+		filters = append(filters, fmt.Sprintf("modified_unix >= %d", req.ModifiedAfter))
+	}
+	if req.ModifiedBefore > 0 {
+		filters = append(filters, fmt.Sprintf("modified_unix <= %d", req.ModifiedBefore))
+	}
+	if len(req.Exts) > 0 {
+		exts := make([]string, len(req.Exts))
+		for i, ext := range req.Exts {
+			exts[i] = "'" + strings.ReplaceAll(ext, "'", "\\'") + "'"
+		}
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ",")))
+	}
+	return filters
+}
+
+func toSearchNode(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	// the fields are missing in the documents indexed by the old versions
+	node.Ext, _ = src["ext"].(string)
+	node.Mime, _ = src["mime"].(string)
+	node.Hash, _ = src["hash"].(string)
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	return node
+}
+
 func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 	return m.BatchIndex(ctx, []model.SearchNode{node})
 }
@@ -65,8 +204,9 @@ func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode)
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
 
 		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
+			ID:           uuid.NewString(),
+			SearchNode:   src,
+			ModifiedUnix: src.Modified.Unix(),
 		}, nil
 	})
 
@@ -100,13 +240,8 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: toSearchNode(src),
 		}, nil
 	})
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,6 +27,10 @@ func Init(mode string) error {
 			log.Errorf("release instance err: %+v", err)
 		}
 		instance = nil
+		// the fingerprints are for the old index
+		if err = op.ClearIndexDirs(); err != nil {
+			log.Errorf("clear index dirs err: %+v", err)
+		}
 	}
 	if Running() {
 		return fmt.Errorf("index is running")
@@ -55,12 +60,27 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	node := toSearchNode(parent, obj)
+	withContent(ctx, &node, obj)
+	return instance.Index(ctx, node)
+}
+
+func toSearchNode(parent string, obj model.Obj) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+	}
+	if !node.IsDir {
+		node.Ext = utils.Ext(node.Name)
+		node.Mime = utils.GetMimeType(node.Name)
+		if len(obj.GetHash().Export()) > 0 {
+			node.Hash = obj.GetHash().String()
+		}
+	}
+	return node
 }
 
 type ObjWithParent struct {
@@ -77,12 +97,9 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		node := toSearchNode(objs[i].Parent, objs[i].Obj)
+		withContent(ctx, &node, objs[i].Obj)
+		searchNodes = append(searchNodes, node)
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
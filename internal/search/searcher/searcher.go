@@ -9,6 +9,8 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// Content is whether the searcher indexes the content of the files
+	Content bool
 }
 
 type Searcher interface {
@@ -0,0 +1,41 @@
+package sign
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/sign"
+)
+
+var onceZip sync.Once
+var instanceZip sign.Sign
+
+func SignZip(data string) string {
+	expire := setting.GetInt(conf.LinkExpiration, 0)
+	if expire == 0 {
+		return NotExpiredZip(data)
+	} else {
+		return WithDurationZip(data, time.Duration(expire)*time.Hour)
+	}
+}
+
+func WithDurationZip(data string, d time.Duration) string {
+	onceZip.Do(InstanceZip)
+	return instanceZip.Sign(data, time.Now().Add(d).Unix())
+}
+
+func NotExpiredZip(data string) string {
+	onceZip.Do(InstanceZip)
+	return instanceZip.Sign(data, 0)
+}
+
+func VerifyZip(data string, sign string) error {
+	onceZip.Do(InstanceZip)
+	return instanceZip.Verify(data, sign)
+}
+
+func InstanceZip() {
+	instanceZip = sign.NewHMACSign([]byte(setting.GetStr(conf.Token) + "-zip"))
+}
@@ -2,6 +2,7 @@ package stream
 
 import (
 	"context"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -34,6 +35,40 @@ var (
 	ServerUploadLimit   Limiter
 )
 
+// NewLimiter returns the limiter of the speed in KB/s, 0 or negative for no limit
+func NewLimiter(speed int) Limiter {
+	if speed <= 0 {
+		return BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)}
+	}
+	return BlockBurstLimiter{Limiter: rate.NewLimiter(rate.Limit(speed)*1024.0, speed*1024)}
+}
+
+// BlockBurstLimiter splits the waits for more bytes than the burst, so that the reads of any size can be limited.
+// The time waited is observed by the metrics of the Name if it's set.
+type BlockBurstLimiter struct {
+	*rate.Limiter
+	Name string
+}
+
+func (l BlockBurstLimiter) WaitN(ctx context.Context, total int) error {
+	if l.Name != "" {
+		start := time.Now()
+		defer func() { metrics.ObserveRateLimitWait(l.Name, time.Since(start)) }()
+	}
+	for total > 0 {
+		n := l.Burst()
+		if l.Limiter.Limit() == rate.Inf || n > total {
+			n = total
+		}
+		err := l.Limiter.WaitN(ctx, n)
+		if err != nil {
+			return err
+		}
+		total -= n
+	}
+	return nil
+}
+
 type RateLimitReader struct {
 	io.Reader
 	Limiter Limiter
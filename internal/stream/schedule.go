@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BandwidthRule overrides the server speeds in the time of the day, the speeds are in KB/s and -1 means no limit,
+// a nil speed keeps the setting
+type BandwidthRule struct {
+	// Start and End are the minutes of the day, the rule crosses midnight if End isn't after Start
+	Start    int
+	End      int
+	Download *int
+	Upload   *int
+}
+
+// ParseBandwidthSchedule parses the rules one per line like "22:00-07:00 download=-1 upload=512",
+// the empty lines and the lines starting with # are ignored
+func ParseBandwidthSchedule(s string) ([]BandwidthRule, error) {
+	var rules []BandwidthRule
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		start, end, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			return nil, errors.Errorf("invalid time range %s", fields[0])
+		}
+		var rule BandwidthRule
+		var err error
+		if rule.Start, err = parseClock(start); err != nil {
+			return nil, err
+		}
+		if rule.End, err = parseClock(end); err != nil {
+			return nil, err
+		}
+		for _, f := range fields[1:] {
+			k, v, _ := strings.Cut(f, "=")
+			speed, err := strconv.Atoi(v)
+			if err != nil || speed < -1 {
+				return nil, errors.Errorf("invalid speed %s", f)
+			}
+			switch k {
+			case "download":
+				rule.Download = &speed
+			case "upload":
+				rule.Upload = &speed
+			default:
+				return nil, errors.Errorf("unknown speed %s, should be download or upload", k)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("invalid time %s, should be like 07:30", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Match reports whether the time is in the rule, the start is included and the end isn't
+func (r BandwidthRule) Match(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if r.Start < r.End {
+		return m >= r.Start && m < r.End
+	}
+	return m >= r.Start || m < r.End
+}
+
+// ScheduledSpeed returns the speeds at the time by the first rule matched, the speeds given are kept if no rule matches
+func ScheduledSpeed(rules []BandwidthRule, t time.Time, download, upload int) (int, int) {
+	for _, rule := range rules {
+		if !rule.Match(t) {
+			continue
+		}
+		if rule.Download != nil {
+			download = *rule.Download
+		}
+		if rule.Upload != nil {
+			upload = *rule.Upload
+		}
+		break
+	}
+	return download, upload
+}
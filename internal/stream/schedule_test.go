@@ -0,0 +1,36 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduledSpeed(t *testing.T) {
+	rules, err := ParseBandwidthSchedule("# full speed at night\n22:00-07:00 download=-1 upload=-1\n\n09:00-18:00 download=512\n")
+	if err != nil {
+		t.Fatalf("failed parse schedule: %+v", err)
+	}
+	datas := []struct {
+		clock    string
+		download int
+		upload   int
+	}{
+		{"23:30", -1, -1},
+		{"06:59", -1, -1},
+		{"07:00", 100, 50},
+		{"12:00", 512, 50},
+		{"18:00", 100, 50},
+	}
+	for _, data := range datas {
+		now, _ := time.Parse("15:04", data.clock)
+		download, upload := ScheduledSpeed(rules, now, 100, 50)
+		if download != data.download || upload != data.upload {
+			t.Errorf("speeds at %s are %d, %d, want %d, %d", data.clock, download, upload, data.download, data.upload)
+		}
+	}
+	for _, s := range []string{"22:00 download=1", "22:00-25:00", "22:00-07:00 download=fast", "22:00-07:00 speed=1"} {
+		if _, err := ParseBandwidthSchedule(s); err == nil {
+			t.Errorf("schedule %q should be invalid", s)
+		}
+	}
+}
@@ -19,6 +19,25 @@ type TaskExtension struct {
 	totalBytes   int64
 }
 
+// StateHook is called after the state of a task is changed
+type StateHook func(t *TaskExtension, state tache.State)
+
+var stateHooks []StateHook
+
+func RegisterStateHook(hook StateHook) {
+	stateHooks = append(stateHooks, hook)
+}
+
+func (t *TaskExtension) SetState(state tache.State) {
+	changed := t.GetState() != state
+	t.Base.SetState(state)
+	if changed {
+		for _, hook := range stateHooks {
+			hook(t, state)
+		}
+	}
+}
+
 func (t *TaskExtension) SetCreator(creator *model.User) {
 	t.Creator = creator
 	t.Persist()
@@ -88,3 +107,10 @@ type TaskExtensionInfo interface {
 	GetEndTime() *time.Time
 	GetTotalBytes() int64
 }
+
+// TaskWithReport is the task with the details of the result besides the status, like the files failed.
+// The report is a summary listed with the tasks, the detail may be large and is got by the tid
+type TaskWithReport interface {
+	GetReport() any
+	GetReportDetail() any
+}
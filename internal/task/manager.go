@@ -1,6 +1,10 @@
 package task
 
-import "github.com/xhofe/tache"
+import (
+	"sync"
+
+	"github.com/xhofe/tache"
+)
 
 type Manager[T tache.Task] interface {
 	Add(task T)
@@ -18,3 +22,46 @@ type Manager[T tache.Task] interface {
 	Retry(id string)
 	RetryAllFailed()
 }
+
+type registeredManager struct {
+	find          func(id string) (TaskExtensionInfo, bool)
+	removeByState func(state ...tache.State)
+}
+
+var (
+	managers   = make(map[string]registeredManager)
+	managersMu sync.RWMutex
+)
+
+// RegisterManager makes the tasks of the manager visible to FindTask and RemoveByState under the name
+func RegisterManager[T TaskExtensionInfo](name string, manager Manager[T]) {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	managers[name] = registeredManager{
+		find: func(id string) (TaskExtensionInfo, bool) {
+			return manager.GetByID(id)
+		},
+		removeByState: manager.RemoveByState,
+	}
+}
+
+// FindTask looks up the task in all registered managers, it returns the name of the manager holding the task
+func FindTask(id string) (string, TaskExtensionInfo, bool) {
+	managersMu.RLock()
+	defer managersMu.RUnlock()
+	for name, m := range managers {
+		if t, ok := m.find(id); ok {
+			return name, t, true
+		}
+	}
+	return "", nil, false
+}
+
+// RemoveByState removes the tasks in the states from all registered managers
+func RemoveByState(state ...tache.State) {
+	managersMu.RLock()
+	defer managersMu.RUnlock()
+	for _, m := range managers {
+		m.removeByState(state...)
+	}
+}
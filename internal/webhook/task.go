@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/xhofe/tache"
+)
+
+// only the states telling something to the outside are sent
+var taskStates = map[tache.State]string{
+	tache.StateRunning:   "running",
+	tache.StateSucceeded: "succeeded",
+	tache.StateErrored:   "errored",
+	tache.StateFailed:    "failed",
+	tache.StateCanceled:  "canceled",
+}
+
+type TaskPayload struct {
+	ID         string  `json:"id"`
+	Manager    string  `json:"manager"`
+	Name       string  `json:"name"`
+	State      string  `json:"state"`
+	Error      string  `json:"error,omitempty"`
+	Progress   float64 `json:"progress"`
+	TotalBytes int64   `json:"total_bytes"`
+}
+
+func init() {
+	task.RegisterStateHook(onTaskState)
+}
+
+func onTaskState(t *task.TaskExtension, state tache.State) {
+	stateName, ok := taskStates[state]
+	if !ok {
+		return
+	}
+	e := Event{
+		Event: EventTask,
+		Task: &TaskPayload{
+			ID:         t.GetID(),
+			State:      stateName,
+			Progress:   t.GetProgress(),
+			TotalBytes: t.GetTotalBytes(),
+		},
+	}
+	if err := t.GetErr(); err != nil {
+		e.Task.Error = err.Error()
+	}
+	if t.Creator != nil {
+		e.User = t.Creator.Username
+	}
+	// the hook is called by the task manager, don't block it
+	go func() {
+		if manager, info, ok := task.FindTask(e.Task.ID); ok {
+			e.Task.Manager = manager
+			e.Task.Name = info.GetName()
+		}
+		Fire(e)
+	}()
+}
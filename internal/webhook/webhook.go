@@ -0,0 +1,168 @@
+// Package webhook sends the filesystem and task events to the webhooks configured by the admin
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EventMkdir  = "fs.mkdir"
+	EventPut    = "fs.put"
+	EventMove   = "fs.move"
+	EventRename = "fs.rename"
+	EventRemove = "fs.remove"
+	EventCopy   = "fs.copy"
+	EventTask   = "task.state"
+)
+
+const (
+	maxAttempts     = 5
+	firstRetryDelay = 2 * time.Second
+	timeout         = 30 * time.Second
+	// maxResponseLen limits how much of the response is kept in the delivery log
+	maxResponseLen = 1024
+	// maxDeliveries is the number of the latest deliveries kept for each webhook
+	maxDeliveries = 1000
+)
+
+type Event struct {
+	ID      string       `json:"id"`
+	Event   string       `json:"event"`
+	Time    time.Time    `json:"time"`
+	User    string       `json:"user,omitempty"`
+	Path    string       `json:"path,omitempty"`
+	DstPath string       `json:"dst_path,omitempty"`
+	Task    *TaskPayload `json:"task,omitempty"`
+}
+
+// Fire sends the event to every webhook accepting it, it returns immediately
+func Fire(e Event) {
+	webhooks, err := op.GetEnabledWebhooks()
+	if err != nil {
+		log.Errorf("failed get webhooks: %+v", err)
+		return
+	}
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	var payload []byte
+	for i := range webhooks {
+		w := webhooks[i]
+		if !w.Accept(e.Event, e.Path, e.DstPath) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = utils.Json.Marshal(e); err != nil {
+				log.Errorf("failed marshal webhook event: %+v", err)
+				return
+			}
+		}
+		go deliver(&w, e, payload)
+	}
+}
+
+// FireFs sends an event of the filesystem, the user is taken from the ctx
+func FireFs(ctx context.Context, event, path string, dstPath ...string) {
+	e := Event{Event: event, Path: path}
+	if len(dstPath) > 0 {
+		e.DstPath = dstPath[0]
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		e.User = user.Username
+	}
+	Fire(e)
+}
+
+func deliver(w *model.Webhook, e Event, payload []byte) {
+	d := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     e.Event,
+		Payload:   string(payload),
+		Created:   time.Now(),
+	}
+	if err := op.CreateWebhookDelivery(d); err != nil {
+		log.Errorf("failed create webhook delivery: %+v", err)
+	}
+	delay := firstRetryDelay
+	for d.Attempts < maxAttempts {
+		if d.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		d.Attempts++
+		d.StatusCode, d.Response, d.Error = send(w, e, payload)
+		d.Success = d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+		if d.Success {
+			break
+		}
+	}
+	d.Updated = time.Now()
+	if !d.Success {
+		log.Warnf("failed send %s to webhook [%s] after %d attempts: %d %s", e.Event, w.Name, d.Attempts, d.StatusCode, d.Error)
+	}
+	if d.ID == 0 {
+		return
+	}
+	if err := op.UpdateWebhookDelivery(d); err != nil {
+		log.Errorf("failed update webhook delivery: %+v", err)
+	}
+}
+
+func send(w *model.Webhook, e Event, payload []byte) (int, string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "alist-webhook")
+	req.Header.Set("X-Alist-Event", e.Event)
+	req.Header.Set("X-Alist-Delivery", e.ID)
+	req.Header.Set("X-Alist-Timestamp", strconv.FormatInt(e.Time.Unix(), 10))
+	if w.Secret != "" {
+		req.Header.Set("X-Alist-Signature", "sha256="+Sign(w.Secret, payload))
+	}
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseLen))
+	return res.StatusCode, string(body), ""
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, receivers verify X-Alist-Signature with it
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PurgeDeliveries removes the deliveries beyond the latest maxDeliveries of each webhook
+func PurgeDeliveries() {
+	n, err := op.DeleteWebhookDeliveriesBeyond(maxDeliveries)
+	if err != nil {
+		log.Errorf("failed delete old webhook deliveries: %+v", err)
+	} else if n > 0 {
+		log.Infof("deleted %d old webhook deliveries", n)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
@@ -16,7 +18,9 @@ type Client interface {
 	AddFromLink(link string, savePath string, id string) error
 	GetInfo(id string) (TorrentInfo, error)
 	GetFiles(id string) ([]FileInfo, error)
+	SetFilePriority(id string, indexes []int, priority int) error
 	Delete(id string, deleteFiles bool) error
+	SetSpeedLimit(download, upload int64) error
 }
 
 type client struct {
@@ -328,6 +332,35 @@ func (c *client) GetFiles(id string) ([]FileInfo, error) {
 	return infos, nil
 }
 
+// SetFilePriority sets the priority of the files, 0 for not downloading them
+func (c *client) SetFilePriority(id string, indexes []int, priority int) error {
+	err := c.checkAuthorization()
+	if err != nil {
+		return err
+	}
+
+	info, err := c.GetInfo(id)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(indexes))
+	for i, index := range indexes {
+		ids[i] = strconv.Itoa(index)
+	}
+	v := url.Values{}
+	v.Set("hash", info.Hash)
+	v.Set("id", strings.Join(ids, "|"))
+	v.Set("priority", strconv.Itoa(priority))
+	response, err := c.post("/api/v2/torrents/filePrio", v)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != 200 {
+		return errors.New("failed to set qbittorrent file priority")
+	}
+	return nil
+}
+
 func (c *client) Delete(id string, deleteFiles bool) error {
 	err := c.checkAuthorization()
 	if err != nil {
@@ -364,3 +397,26 @@ func (c *client) Delete(id string, deleteFiles bool) error {
 	}
 	return nil
 }
+
+// SetSpeedLimit sets the global speeds in bytes/s, 0 means no limit
+func (c *client) SetSpeedLimit(download, upload int64) error {
+	err := c.checkAuthorization()
+	if err != nil {
+		return err
+	}
+	for path, limit := range map[string]int64{
+		"/api/v2/transfer/setDownloadLimit": download,
+		"/api/v2/transfer/setUploadLimit":   upload,
+	} {
+		v := url.Values{}
+		v.Set("limit", strconv.FormatInt(limit, 10))
+		response, err := c.post(path, v)
+		if err != nil {
+			return err
+		}
+		if response.StatusCode != 200 {
+			return errors.New("failed to set qbittorrent speed limit")
+		}
+	}
+	return nil
+}
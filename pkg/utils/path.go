@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"net/url"
 	stdpath "path"
 	"strings"
@@ -101,3 +102,14 @@ func JoinBasePath(basePath, reqPath string) (string, error) {
 func GetFullPath(mountPath, path string) string {
 	return stdpath.Join(GetActualMountPath(mountPath), path)
 }
+
+// NumberedName adds the number before the extension of the name like "a (1).txt",
+// which is used to rename the file conflicting with an existing one
+func NumberedName(name string, n int) string {
+	ext := stdpath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if base == "" {
+		base, ext = name, ""
+	}
+	return fmt.Sprintf("%s (%d)%s", base, n, ext)
+}
@@ -20,3 +20,17 @@ func TestFixAndCleanPath(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberedName(t *testing.T) {
+	datas := map[string]string{
+		"a.txt":     "a (1).txt",
+		"a":         "a (1)",
+		".bashrc":   ".bashrc (1)",
+		"a.tar.zst": "a.tar (1).zst",
+	}
+	for key, value := range datas {
+		if got := NumberedName(key, 1); got != value {
+			t.Errorf("numbered name of %s is %s, want %s", key, got, value)
+		}
+	}
+}
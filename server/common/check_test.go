@@ -22,3 +22,25 @@ func TestIsApply(t *testing.T) {
 		}
 	}
 }
+
+func TestRangeStartsAtZero(t *testing.T) {
+	datas := []struct {
+		r      string
+		size   int64
+		result bool
+	}{
+		{"", 100, true},
+		{"bytes=0-", 100, true},
+		{"bytes=50-99", 100, false},
+		{"bytes=50-99, 0-10", 100, true},
+		{"bytes=-100", 100, true},
+		{"bytes=-100", -1, false},
+		{"bytes=0-", -1, true},
+		{"invalid", 100, true},
+	}
+	for i, data := range datas {
+		if RangeStartsAtZero(data.r, data.size) != data.result {
+			t.Errorf("test case %d failed", i)
+		}
+	}
+}
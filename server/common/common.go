@@ -2,11 +2,13 @@ package common
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"strings"
 
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
@@ -97,3 +99,22 @@ func GetHttpReq(ctx context.Context) *http.Request {
 	}
 	return nil
 }
+
+// RangeStartsAtZero reports whether any of the ranges of the Range header contains the first byte,
+// so a download is counted once for the requests of a player. An invalid header is counted as well
+// since the whole file may be served. size is -1 if unknown, then the suffix ranges aren't counted.
+func RangeStartsAtZero(r string, size int64) bool {
+	if size < 0 {
+		size = math.MaxInt64
+	}
+	ranges, err := http_range.ParseRange(r, size)
+	if err != nil || len(ranges) == 0 {
+		return true
+	}
+	for _, rg := range ranges {
+		if rg.Start == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/dlclark/regexp2"
+)
+
+// SearchAccess returns what the user can see in the index under the parent with the password, it follows
+// CanAccessWithRoles except that the nodes in the protected or hidden dirs are denied too, so that their names
+// don't leak. The hide rules out of the parent are left out since they make the searchers check the nodes in memory.
+func SearchAccess(u *model.User, password, parent string) (*model.SearchAccess, error) {
+	access := &model.SearchAccess{}
+	if roots := model.GetAllBasePathsFromRoles(u); !utils.SliceContains(roots, "/") {
+		access.Roots = roots
+	}
+	metas, _, err := op.GetMetas(1, model.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	metasByPath := make(map[string]*model.Meta, len(metas))
+	hides := make(map[string][]*regexp2.Regexp)
+	for i := range metas {
+		meta := &metas[i]
+		metasByPath[meta.Path] = meta
+		perm := MergeRolePermissions(u, meta.Path)
+		if meta.Password != "" && meta.Password != password && !HasPermission(perm, PermAccessWithoutPassword) {
+			denied := model.DeniedDir{Path: meta.Path, Sub: meta.PSub}
+			for _, sub := range metas {
+				if sub.Path != meta.Path && utils.IsSubPath(meta.Path, sub.Path) {
+					denied.Except = append(denied.Except, sub.Path)
+				}
+			}
+			access.Denied = append(access.Denied, denied)
+		}
+		inScope := utils.IsSubPath(parent, meta.Path) || utils.IsSubPath(meta.Path, parent)
+		if meta.Hide != "" && inScope && !HasPermission(perm, PermSeeHides) {
+			for _, hide := range strings.Split(meta.Hide, "\n") {
+				hides[meta.Path] = append(hides[meta.Path], regexp2.MustCompile(hide, regexp2.None))
+			}
+		}
+	}
+	if len(hides) > 0 {
+		access.Hidden = hiddenFunc(metasByPath, hides)
+	}
+	return access, nil
+}
+
+// hiddenFunc checks the name with the hide rules of the nearest meta of the parent, and the parents in the same way
+func hiddenFunc(metas map[string]*model.Meta, hides map[string][]*regexp2.Regexp) func(parent, name string) bool {
+	nearestMeta := func(dir string) *model.Meta {
+		for {
+			if meta, ok := metas[dir]; ok {
+				return meta
+			}
+			if dir == "/" {
+				return nil
+			}
+			dir = stdpath.Dir(dir)
+		}
+	}
+	hiddenIn := func(parent, name string) bool {
+		meta := nearestMeta(parent)
+		if meta == nil || !IsApply(meta.Path, parent, meta.HSub) {
+			return false
+		}
+		for _, re := range hides[meta.Path] {
+			if isMatch, _ := re.MatchString(name); isMatch {
+				return true
+			}
+		}
+		return false
+	}
+	// the nodes of a search share the parents
+	hiddenDirs := make(map[string]bool)
+	var dirHidden func(dir string) bool
+	dirHidden = func(dir string) bool {
+		if dir == "/" {
+			return false
+		}
+		if hidden, ok := hiddenDirs[dir]; ok {
+			return hidden
+		}
+		parent := stdpath.Dir(dir)
+		hidden := dirHidden(parent) || hiddenIn(parent, stdpath.Base(dir))
+		hiddenDirs[dir] = hidden
+		return hidden
+	}
+	return func(parent, name string) bool {
+		return dirHidden(parent) || hiddenIn(parent, name)
+	}
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/dlclark/regexp2"
+)
+
+func TestHiddenFunc(t *testing.T) {
+	metas := map[string]*model.Meta{
+		"/a":   {Path: "/a", Hide: "^secret$", HSub: true},
+		"/a/b": {Path: "/a/b"},
+	}
+	hides := map[string][]*regexp2.Regexp{"/a": {regexp2.MustCompile("^secret$", regexp2.None)}}
+	hidden := hiddenFunc(metas, hides)
+	datas := []struct {
+		parent string
+		name   string
+		result bool
+	}{
+		{"/a", "secret", true},
+		{"/a/secret/x", "file.txt", true},
+		{"/a/c", "secret", true},
+		{"/a/b", "secret", false},
+		{"/", "secret", false},
+	}
+	for _, data := range datas {
+		if hidden(data.parent, data.name) != data.result {
+			t.Errorf("hidden(%s, %s) should be %v", data.parent, data.name, data.result)
+		}
+	}
+}
+
+func TestDeniedDir(t *testing.T) {
+	datas := []struct {
+		denied model.DeniedDir
+		parent string
+		result bool
+	}{
+		{model.DeniedDir{Path: "/a", Sub: true}, "/a/b", true},
+		{model.DeniedDir{Path: "/a", Sub: true, Except: []string{"/a/b"}}, "/a/b/c", false},
+		{model.DeniedDir{Path: "/a"}, "/a", true},
+		{model.DeniedDir{Path: "/a"}, "/a/b", false},
+	}
+	for _, data := range datas {
+		if data.denied.Denies(data.parent) != data.result {
+			t.Errorf("%+v denies %s should be %v", data.denied, data.parent, data.result)
+		}
+	}
+}
@@ -145,7 +145,9 @@ func (d *FtpMainDriver) AuthUser(cc ftpserver.ClientContext, user, pass string)
 	}
 	ctx = context.WithValue(ctx, "client_ip", cc.RemoteAddr().String())
 	ctx = context.WithValue(ctx, "proxy_header", d.proxyHeader)
-	return ftp.NewAferoAdapter(ctx), nil
+	adapter := ftp.NewAferoAdapter(ctx)
+	adapter.SetClientContext(cc)
+	return adapter, nil
 }
 
 func (d *FtpMainDriver) GetTLSConfig() (*tls.Config, error) {
@@ -15,12 +15,19 @@ import (
 type AferoAdapter struct {
 	ctx          context.Context
 	nextFileSize int64
+	// cc is the ftp client, nil for sftp
+	cc ftpserver.ClientContext
 }
 
 func NewAferoAdapter(ctx context.Context) *AferoAdapter {
 	return &AferoAdapter{ctx: ctx}
 }
 
+// SetClientContext sets the ftp client, the command of which tells whether the working dir is being changed
+func (a *AferoAdapter) SetClientContext(cc ftpserver.ClientContext) {
+	a.cc = cc
+}
+
 func (a *AferoAdapter) Create(_ string) (afero.File, error) {
 	// See also GetHandle
 	return nil, errs.NotImplement
@@ -57,9 +64,19 @@ func (a *AferoAdapter) Rename(oldName, newName string) error {
 }
 
 func (a *AferoAdapter) Stat(name string) (os.FileInfo, error) {
+	// ftpserverlib changes the working dir only to a dir, so the archive is stated as a dir for CWD
+	if a.cc != nil {
+		if cmd := a.cc.GetLastCommand(); cmd == "CWD" || cmd == "XCWD" {
+			return StatDir(a.ctx, name)
+		}
+	}
 	return Stat(a.ctx, name)
 }
 
+func (a *AferoAdapter) StatDir(name string) (os.FileInfo, error) {
+	return StatDir(a.ctx, name)
+}
+
 func (a *AferoAdapter) Name() string {
 	return "AList FTP Endpoint"
 }
@@ -97,6 +114,11 @@ func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserve
 	_, err = fs.Get(a.ctx, path, &fs.GetArgs{})
 	exists := err == nil
 	if (flags&os.O_CREATE) == 0 && !exists {
+		if (flags & os.O_WRONLY) == 0 {
+			if h, e := OpenArchiveDownload(a.ctx, path, offset); e == nil {
+				return h, nil
+			}
+		}
 		return nil, errs.ObjectNotFound
 	}
 	if (flags&os.O_EXCL) != 0 && exists {
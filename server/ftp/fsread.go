@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/pkg/errors"
+	"io"
 	fs2 "io/fs"
 	"net/http"
 	"os"
@@ -80,6 +81,67 @@ func (f *FileDownloadProxy) Close() error {
 	return f.reader.Close()
 }
 
+type ArchiveDownloadProxy struct {
+	ftpserver.FileTransfer
+	ctx context.Context
+	rc  io.ReadCloser
+}
+
+// OpenArchiveDownload opens the file in the archive browsed as a dir, the file is extracted on the fly
+// so that it can't be seeked
+func OpenArchiveDownload(ctx context.Context, reqPath string, offset int64) (*ArchiveDownloadProxy, error) {
+	user := ctx.Value("user").(*model.User)
+	if !canBrowseArchive(user, reqPath) {
+		return nil, errs.PermissionDenied
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return nil, err
+		}
+	}
+	if !common.CanAccessWithRoles(user, meta, reqPath, ctx.Value("meta_pass").(string)) {
+		return nil, errs.PermissionDenied
+	}
+	rc, _, err := fs.ArchiveBrowseOpen(ctx, reqPath)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err = io.CopyN(io.Discard, rc, offset); err != nil {
+			_ = rc.Close()
+			return nil, err
+		}
+	}
+	return &ArchiveDownloadProxy{ctx: ctx, rc: rc}, nil
+}
+
+func (f *ArchiveDownloadProxy) Read(p []byte) (n int, err error) {
+	n, err = f.rc.Read(p)
+	if err != nil {
+		return
+	}
+	err = stream.ClientDownloadLimit.WaitN(f.ctx, n)
+	return
+}
+
+func (f *ArchiveDownloadProxy) Write(p []byte) (n int, err error) {
+	return 0, errs.NotSupport
+}
+
+func (f *ArchiveDownloadProxy) Seek(offset int64, whence int) (int64, error) {
+	return 0, errs.NotSupport
+}
+
+func (f *ArchiveDownloadProxy) Close() error {
+	return f.rc.Close()
+}
+
+func canBrowseArchive(user *model.User, reqPath string) bool {
+	perm := common.MergeRolePermissions(user, reqPath)
+	return common.HasPermission(perm, common.PermReadArchives)
+}
+
 type OsFileInfoAdapter struct {
 	obj model.Obj
 }
@@ -113,6 +175,15 @@ func (o *OsFileInfoAdapter) Sys() any {
 }
 
 func Stat(ctx context.Context, path string) (os.FileInfo, error) {
+	return stat(ctx, path, false)
+}
+
+// StatDir stats the path like Stat, but the archive is taken as its root dir, it's used to change the working dir
+func StatDir(ctx context.Context, path string) (os.FileInfo, error) {
+	return stat(ctx, path, true)
+}
+
+func stat(ctx context.Context, path string, dir bool) (os.FileInfo, error) {
 	user := ctx.Value("user").(*model.User)
 	reqPath, err := user.JoinPath(path)
 	if err != nil {
@@ -129,6 +200,15 @@ func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 		return nil, errs.PermissionDenied
 	}
 	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
+	if (err != nil || dir && !obj.IsDir()) && canBrowseArchive(user, reqPath) {
+		browse := fs.ArchiveBrowseGet
+		if dir {
+			browse = fs.ArchiveBrowseDir
+		}
+		if aObj, e := browse(ctx, reqPath); e == nil {
+			obj, err = aObj, nil
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +232,11 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 		return nil, errs.PermissionDenied
 	}
 	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
+	if err != nil && canBrowseArchive(user, reqPath) {
+		if aObjs, e := fs.ArchiveBrowseList(ctx, reqPath); e == nil {
+			objs, err = aObjs, nil
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +245,9 @@ type ArchiveDecompressReq struct {
 	InnerPath     string        `json:"inner_path" form:"inner_path"`
 	CacheFull     bool          `json:"cache_full" form:"cache_full"`
 	PutIntoNewDir bool          `json:"put_into_new_dir" form:"put_into_new_dir"`
+	// InnerPaths are decompressed in one task instead of InnerPath, the paths can be glob patterns
+	InnerPaths     []string `json:"inner_paths" form:"inner_paths"`
+	ConflictPolicy string   `json:"conflict_policy" form:"conflict_policy"`
 }
 
 func FsArchiveDecompress(c *gin.Context) {
@@ -253,6 +256,21 @@ func FsArchiveDecompress(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	switch req.ConflictPolicy {
+	case "", model.ConflictOverwrite, model.ConflictSkip, model.ConflictRename:
+	default:
+		common.ErrorStrResp(c, fmt.Sprintf("invalid conflict policy [%s]", req.ConflictPolicy), 400)
+		return
+	}
+	innerPaths := make([]string, 0, len(req.InnerPaths))
+	for _, p := range req.InnerPaths {
+		p = utils.FixAndCleanPath(p)
+		if _, err := stdpath.Match(p, ""); err != nil {
+			common.ErrorStrResp(c, fmt.Sprintf("invalid inner path [%s]", p), 400)
+			return
+		}
+		innerPaths = append(innerPaths, p)
+	}
 	user := c.MustGet("user").(*model.User)
 	srcPaths := make([]string, 0, len(req.Name))
 	for _, name := range req.Name {
@@ -295,8 +313,10 @@ func FsArchiveDecompress(c *gin.Context) {
 				},
 				InnerPath: utils.FixAndCleanPath(req.InnerPath),
 			},
-			CacheFull:     req.CacheFull,
-			PutIntoNewDir: req.PutIntoNewDir,
+			CacheFull:      req.CacheFull,
+			PutIntoNewDir:  req.PutIntoNewDir,
+			InnerPaths:     innerPaths,
+			ConflictPolicy: req.ConflictPolicy,
 		})
 		if e != nil {
 			if errors.Is(e, errs.WrongArchivePassword) {
@@ -315,6 +335,151 @@ func FsArchiveDecompress(c *gin.Context) {
 	})
 }
 
+type ArchiveCompressReq struct {
+	SrcDir      string   `json:"src_dir" form:"src_dir"`
+	Names       []string `json:"names" form:"names"`
+	DstDir      string   `json:"dst_dir" form:"dst_dir"`
+	ArchiveName string   `json:"archive_name" form:"archive_name"`
+	ArchivePass string   `json:"archive_pass" form:"archive_pass"`
+	Password    string   `json:"password" form:"password"`
+	Overwrite   bool     `json:"overwrite" form:"overwrite"`
+}
+
+func FsArchiveCompress(c *gin.Context) {
+	var req ArchiveCompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Names) == 0 {
+		common.ErrorStrResp(c, "Empty file names", 400)
+		return
+	}
+	if req.ArchiveName == "" || stdpath.Base(req.ArchiveName) != req.ArchiveName {
+		common.ErrorStrResp(c, "Invalid archive name", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, srcDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !common.HasPermission(common.MergeRolePermissions(user, srcDir), common.PermCopy) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	perm := common.MergeRolePermissions(user, dstDir)
+	if !common.HasPermission(perm, common.PermWrite) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	srcPaths := []string{srcDir}
+	for _, name := range req.Names {
+		if name == "" || stdpath.Base(name) != name {
+			common.ErrorStrResp(c, fmt.Sprintf("Invalid file name [%s]", name), 400)
+			return
+		}
+		srcPaths = append(srcPaths, stdpath.Join(srcDir, name))
+	}
+	for _, srcPath := range srcPaths {
+		meta, err := op.GetNearestMeta(srcPath)
+		if err != nil {
+			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				common.ErrorResp(c, err, 500, true)
+				return
+			}
+		}
+		if !common.CanAccessWithRoles(user, meta, srcPath, req.Password) {
+			common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+			return
+		}
+	}
+	if !req.Overwrite {
+		if res, _ := fs.Get(c, stdpath.Join(dstDir, req.ArchiveName), &fs.GetArgs{NoLog: true}); res != nil {
+			common.ErrorStrResp(c, fmt.Sprintf("file [%s] exists", req.ArchiveName), 403)
+			return
+		}
+	}
+	t, err := fs.ArchiveCompress(c, srcDir, req.Names, dstDir, req.ArchiveName, req.ArchivePass)
+	if err != nil {
+		if errors.Is(err, errs.UnknownArchiveFormat) {
+			common.ErrorResp(c, err, 400)
+		} else {
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
+type ArchiveTestReq struct {
+	Path        string `json:"path" form:"path"`
+	Password    string `json:"password" form:"password"`
+	ArchivePass string `json:"archive_pass" form:"archive_pass"`
+}
+
+func FsArchiveTest(c *gin.Context) {
+	var req ArchiveTestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	perm := common.MergeRolePermissions(user, reqPath)
+	if !common.HasPermission(perm, common.PermReadArchives) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	t, err := fs.ArchiveTest(c, reqPath, req.ArchivePass)
+	if err != nil {
+		if errors.Is(err, errs.UnknownArchiveFormat) || errs.IsNotSupportError(err) {
+			common.ErrorResp(c, err, 400)
+		} else {
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
 func ArchiveDown(c *gin.Context) {
 	archiveRawPath := c.MustGet("path").(string)
 	innerPath := utils.FixAndCleanPath(c.Query("inner"))
@@ -0,0 +1,65 @@
+package handles
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type AuditLogsReq struct {
+	model.PageReq
+	model.AuditLogFilter
+}
+
+func ListAuditLogs(c *gin.Context) {
+	var req AuditLogsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := op.GetAuditLogs(req.AuditLogFilter, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
+
+// ExportAuditLogs writes the matched logs as JSON lines
+func ExportAuditLogs(c *gin.Context) {
+	var req model.AuditLogFilter
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit_%s.jsonl"`, time.Now().Format("20060102150405")))
+	w := bufio.NewWriter(c.Writer)
+	encoder := utils.Json.NewEncoder(w)
+	err := op.WalkAuditLogs(req, 500, func(logs []model.AuditLog) error {
+		for i := range logs {
+			if err := encoder.Encode(&logs[i]); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		// the status is sent already, the client gets a truncated file
+		log.Errorf("failed export audit logs: %+v", err)
+	}
+}
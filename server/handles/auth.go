@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/device"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -69,12 +70,14 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	// check username
 	user, err := op.GetUserByName(req.Username)
 	if err != nil {
+		auditLogin(c, &model.User{Username: req.Username}, "password", "", err)
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
 		return
 	}
 	// validate password hash
 	if err := user.ValidatePwdStaticHash(req.Password); err != nil {
+		auditLogin(c, user, "password", "", err)
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
 		return
@@ -82,6 +85,7 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	// check 2FA
 	if user.OtpSecret != "" {
 		if !totp.Validate(req.OtpCode, user.OtpSecret) {
+			auditLogin(c, user, "password", "", errors.New("invalid 2FA code"))
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
 			loginCache.Set(ip, count+1)
 			return
@@ -96,6 +100,7 @@ func loginHash(c *gin.Context, req *LoginReq) {
 		user.ID, clientID))
 
 	if err := device.EnsureActiveOnLogin(user.ID, key, c.Request.UserAgent(), c.ClientIP()); err != nil {
+		auditLogin(c, user, "password", key, err)
 		if errors.Is(err, errs.TooManyDevices) {
 			common.ErrorResp(c, err, 403)
 		} else {
@@ -106,6 +111,7 @@ func loginHash(c *gin.Context, req *LoginReq) {
 
 	// generate token
 	token, err := common.GenerateToken(user)
+	auditLogin(c, user, "password", key, err)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
@@ -114,6 +120,17 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	loginCache.Del(ip)
 }
 
+// auditLogin records the login attempt of the user, method tells how the user signed in
+func auditLogin(c *gin.Context, user *model.User, method, deviceKey string, err error) {
+	audit.Log(c, model.AuditLog{
+		UserID:    user.ID,
+		Username:  user.Username,
+		DeviceKey: deviceKey,
+		Action:    audit.ActionLogin,
+		Detail:    method,
+	}, err)
+}
+
 type RegisterReq struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -148,6 +165,7 @@ type UserResp struct {
 	Otp         bool                    `json:"otp"`
 	RoleNames   []string                `json:"role_names"`
 	Permissions []model.PermissionEntry `json:"permissions"`
+	Quota       *model.Quota            `json:"quota,omitempty"`
 }
 
 // CurrentUser get current user by token
@@ -186,6 +204,15 @@ func CurrentUser(c *gin.Context) {
 		})
 	}
 
+	if !user.IsGuest() {
+		quota, err := op.GetQuota(user)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		userResp.Quota = quota
+	}
+
 	common.SuccessResp(c, userResp)
 }
 
@@ -262,7 +289,9 @@ func Verify2FA(c *gin.Context) {
 		return
 	}
 	user.OtpSecret = req.Secret
-	if err := op.UpdateUser(user); err != nil {
+	err := op.UpdateUser(user)
+	audit.Log(c, model.AuditLog{Action: audit.ActionEnable2FA}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -278,6 +307,7 @@ func LogOut(c *gin.Context) {
 		c.Set("session_inactive", true)
 	}
 	err := common.InvalidateToken(c.GetHeader("Authorization"))
+	audit.Log(c, model.AuditLog{Action: audit.ActionLogout}, err)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
@@ -11,6 +11,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
@@ -128,6 +129,7 @@ func localProxy(c *gin.Context, link *model.Link, file model.Obj, proxyRange boo
 	if proxyRange {
 		common.ProxyRange(link, file.GetSize())
 	}
+	defer func() { metrics.AddServedBytes(c.FullPath(), int64(c.Writer.Size())) }()
 	Writer := &common.WrittenResponseWriter{ResponseWriter: c.Writer}
 
 	//优先处理md文件
@@ -18,21 +18,38 @@ type UpdateIndexReq struct {
 	//IgnorePaths []string `json:"ignore_paths"`
 }
 
+type BuildIndexReq struct {
+	// Incremental only indexes the changed dirs, it resumes the last incremental index if it's not done.
+	// It's unavailable for bleve.
+	Incremental bool `json:"incremental"`
+}
+
 func BuildIndex(c *gin.Context) {
+	var req BuildIndexReq
+	if err := c.ShouldBind(&req); err != nil && c.Request.ContentLength > 0 {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	if search.Running() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
-	go func() {
-		ctx := context.Background()
-		err := search.Clear(ctx)
-		if err != nil {
-			log.Errorf("clear index error: %+v", err)
+	if req.Incremental {
+		if !search.Config(c).AutoUpdate {
+			common.ErrorStrResp(c, "incremental index is not supported for current index, e.g. bleve", 400)
 			return
 		}
-		err = search.BuildIndex(context.Background(), []string{"/"},
-			conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), true)
-		if err != nil {
+		go func() {
+			if err := search.BuildIndexIncremental(context.Background(), []string{"/"},
+				setting.GetInt(conf.MaxIndexDepth, 20)); err != nil {
+				log.Errorf("build incremental index error: %+v", err)
+			}
+		}()
+		common.SuccessResp(c)
+		return
+	}
+	go func() {
+		if err := search.Rebuild(context.Background()); err != nil {
 			log.Errorf("build index error: %+v", err)
 		}
 	}()
@@ -54,17 +71,7 @@ func UpdateIndex(c *gin.Context) {
 		return
 	}
 	go func() {
-		ctx := context.Background()
-		for _, path := range req.Paths {
-			err := search.Del(ctx, path)
-			if err != nil {
-				log.Errorf("delete index on %s error: %+v", path, err)
-				return
-			}
-		}
-		err := search.BuildIndex(context.Background(), req.Paths,
-			conf.SlicesMap[conf.IgnorePaths], req.MaxDepth, false)
-		if err != nil {
+		if err := search.UpdatePaths(context.Background(), req.Paths, req.MaxDepth); err != nil {
 			log.Errorf("update index error: %+v", err)
 		}
 	}()
@@ -113,6 +113,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 
 	// generate token
 	token, err := common.GenerateToken(user)
+	auditLogin(c, user, "ldap", "", err)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
@@ -29,7 +29,7 @@ func SetAria2(c *gin.Context) {
 		{Key: conf.Aria2Uri, Value: req.Uri, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 		{Key: conf.Aria2Secret, Value: req.Secret, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -61,7 +61,7 @@ func SetQbittorrent(c *gin.Context) {
 		{Key: conf.QbittorrentUrl, Value: req.Url, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 		{Key: conf.QbittorrentSeedtime, Value: req.Seedtime, Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -92,7 +92,7 @@ func SetTransmission(c *gin.Context) {
 		{Key: conf.TransmissionUri, Value: req.Uri, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 		{Key: conf.TransmissionSeedtime, Value: req.Seedtime, Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -136,7 +136,7 @@ func Set115(c *gin.Context) {
 	items := []model.SettingItem{
 		{Key: conf.Pan115TempDir, Value: req.TempDir, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -180,7 +180,7 @@ func SetPikPak(c *gin.Context) {
 	items := []model.SettingItem{
 		{Key: conf.PikPakTempDir, Value: req.TempDir, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -224,7 +224,7 @@ func SetThunder(c *gin.Context) {
 	items := []model.SettingItem{
 		{Key: conf.ThunderTempDir, Value: req.TempDir, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
 	}
-	if err := op.SaveSettingItems(items); err != nil {
+	if err := saveSettingItems(c, items); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -240,6 +240,42 @@ func SetThunder(c *gin.Context) {
 	common.SuccessResp(c, "ok")
 }
 
+type SetYtDlpReq struct {
+	Path       string `json:"path" form:"path"`
+	FfmpegPath string `json:"ffmpeg_path" form:"ffmpeg_path"`
+	Format     string `json:"format" form:"format"`
+	Args       string `json:"args" form:"args"`
+}
+
+func SetYtDlp(c *gin.Context) {
+	var req SetYtDlpReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items := []model.SettingItem{
+		{Key: conf.YtDlpPath, Value: req.Path, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtDlpFfmpegPath, Value: req.FfmpegPath, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtDlpFormat, Value: req.Format, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtDlpArgs, Value: req.Args, Type: conf.TypeText, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
+	if err := saveSettingItems(c, items); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	_tool, err := tool.Tools.Get("yt-dlp")
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	version, err := _tool.Init()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, version)
+}
+
 func OfflineDownloadTools(c *gin.Context) {
 	tools := tool.Tools.Names()
 	common.SuccessResp(c, tools)
@@ -250,6 +286,10 @@ type AddOfflineDownloadReq struct {
 	Path         string   `json:"path"`
 	Tool         string   `json:"tool"`
 	DeletePolicy string   `json:"delete_policy"`
+	// SelectedFiles are the files returned by resolve_offline_download, only for a single url
+	SelectedFiles []tool.TorrentFile `json:"selected_files"`
+	// Checksum like sha256:<hex> is checked before the transfer, only for a single url
+	Checksum string `json:"checksum"`
 }
 
 func AddOfflineDownload(c *gin.Context) {
@@ -274,13 +314,23 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorStrResp(c, "permission denied", 403)
 		return
 	}
+	if len(req.SelectedFiles) > 0 && len(req.Urls) != 1 {
+		common.ErrorStrResp(c, "files can only be selected for a single url", 400)
+		return
+	}
+	if req.Checksum != "" && len(req.Urls) != 1 {
+		common.ErrorStrResp(c, "checksum can only be given for a single url", 400)
+		return
+	}
 	var tasks []task.TaskExtensionInfo
 	for _, url := range req.Urls {
 		t, err := tool.AddURL(c, &tool.AddURLArgs{
-			URL:          url,
-			DstDirPath:   reqPath,
-			Tool:         req.Tool,
-			DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
+			URL:           url,
+			DstDirPath:    reqPath,
+			Tool:          req.Tool,
+			DeletePolicy:  tool.DeletePolicy(req.DeletePolicy),
+			SelectedFiles: req.SelectedFiles,
+			Checksum:      req.Checksum,
 		})
 		if err != nil {
 			common.ErrorResp(c, err, 500)
@@ -294,3 +344,42 @@ func AddOfflineDownload(c *gin.Context) {
 		"tasks": getTaskInfos(tasks),
 	})
 }
+
+type ResolveOfflineDownloadReq struct {
+	Url  string `json:"url"`
+	Path string `json:"path"`
+	Tool string `json:"tool"`
+}
+
+// ResolveOfflineDownload returns the files of the magnet or the torrent url, so that some of them can be selected to download
+func ResolveOfflineDownload(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var req ResolveOfflineDownloadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	perm := common.MergeRolePermissions(user, reqPath)
+	if !common.HasPermission(perm, common.PermAddOfflineDownload) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	files, err := tool.ResolveURL(c, req.Tool, req.Url)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"files": files,
+	})
+}
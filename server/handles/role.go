@@ -57,11 +57,15 @@ func CreateRole(c *gin.Context) {
 
 func UpdateRole(c *gin.Context) {
 	var req struct {
-		ID               uint                    `json:"id"`
-		Name             string                  `json:"name" binding:"required"`
-		Description      string                  `json:"description"`
-		PermissionScopes []model.PermissionEntry `json:"permission_scopes"`
-		Default          *bool                   `json:"default"`
+		ID                  uint                    `json:"id"`
+		Name                string                  `json:"name" binding:"required"`
+		Description         string                  `json:"description"`
+		PermissionScopes    []model.PermissionEntry `json:"permission_scopes"`
+		Default             *bool                   `json:"default"`
+		QuotaBytes          *int64                  `json:"quota_bytes"`
+		QuotaFiles          *int64                  `json:"quota_files"`
+		MaxOfflineDownloads *int                    `json:"max_offline_downloads"`
+		MaxTransferSpeed    *int                    `json:"max_transfer_speed"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		common.ErrorResp(c, err, 400)
@@ -86,6 +90,18 @@ func UpdateRole(c *gin.Context) {
 	if req.Default != nil {
 		role.Default = *req.Default
 	}
+	if req.QuotaBytes != nil {
+		role.QuotaBytes = *req.QuotaBytes
+	}
+	if req.QuotaFiles != nil {
+		role.QuotaFiles = *req.QuotaFiles
+	}
+	if req.MaxOfflineDownloads != nil {
+		role.MaxOfflineDownloads = *req.MaxOfflineDownloads
+	}
+	if req.MaxTransferSpeed != nil {
+		role.MaxTransferSpeed = *req.MaxTransferSpeed
+	}
 	if err := op.UpdateRole(role); err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
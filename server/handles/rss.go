@@ -0,0 +1,206 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/rss"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type RssItemsReq struct {
+	model.PageReq
+	ID uint `json:"id" form:"id" binding:"required"`
+}
+
+// ListRssFeeds returns the feeds of the current user, all feeds for the admin
+func ListRssFeeds(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	var userID uint
+	if !user.IsAdmin() {
+		userID = user.ID
+	}
+	feeds, total, err := op.GetRssFeeds(userID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: feeds,
+		Total:   total,
+	})
+}
+
+func GetRssFeed(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	feed, ok := getOwnRssFeed(c, uint(id))
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, feed)
+}
+
+func CreateRssFeed(c *gin.Context) {
+	var req model.RssFeed
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if !validRssFeed(c, user, &req) {
+		return
+	}
+	req.ID = 0
+	req.UserID = user.ID
+	if err := op.CreateRssFeed(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	rss.ScheduleFeed(req)
+	common.SuccessResp(c, req)
+}
+
+func UpdateRssFeed(c *gin.Context) {
+	var req model.RssFeed
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	old, ok := getOwnRssFeed(c, req.ID)
+	if !ok {
+		return
+	}
+	// the feed is polled under its owner, even if it's updated by the admin
+	owner, err := op.GetUserById(old.UserID)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !validRssFeed(c, owner, &req) {
+		return
+	}
+	req.UserID = old.UserID
+	if err := op.UpdateRssFeed(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	rss.ScheduleFeed(req)
+	common.SuccessResp(c)
+}
+
+// validRssFeed checks the feed, the dst path is kept relative to the base path of the user like the other requests
+func validRssFeed(c *gin.Context, user *model.User, f *model.RssFeed) bool {
+	if f.Interval < 0 {
+		common.ErrorStrResp(c, "interval can't be negative", 400)
+		return false
+	}
+	if _, _, err := rss.Filters(f); err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	if _, err := tool.Tools.Get(f.Tool); err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	dstPath, err := user.JoinPath(f.DstPath)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	if !rss.CanSubscribe(user, dstPath) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return false
+	}
+	return true
+}
+
+func DeleteRssFeed(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, ok := getOwnRssFeed(c, uint(id)); !ok {
+		return
+	}
+	if err := op.DeleteRssFeedById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	rss.UnscheduleFeed(uint(id))
+	common.SuccessResp(c)
+}
+
+// PollRssFeed polls the feed now, the new items matched are queued
+func PollRssFeed(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	feed, ok := getOwnRssFeed(c, uint(id))
+	if !ok {
+		return
+	}
+	queued, err := rss.Poll(c, feed)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"queued": queued,
+	})
+}
+
+// ListRssItems returns the history of the feed, the newest first
+func ListRssItems(c *gin.Context) {
+	var req RssItemsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if _, ok := getOwnRssFeed(c, req.ID); !ok {
+		return
+	}
+	items, total, err := op.GetRssItems(req.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+// getOwnRssFeed returns the feed if it's owned by the current user or the user is the admin
+func getOwnRssFeed(c *gin.Context, id uint) (*model.RssFeed, bool) {
+	feed, err := op.GetRssFeedById(id)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return nil, false
+	}
+	user := c.MustGet("user").(*model.User)
+	if feed.UserID != user.ID && !user.IsAdmin() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return nil, false
+	}
+	return feed, true
+}
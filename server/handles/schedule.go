@@ -0,0 +1,119 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/scheduler"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListScheduledJobs(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	jobs, total, err := op.GetScheduledJobs(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: jobs,
+		Total:   total,
+	})
+}
+
+func GetScheduledJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := op.GetScheduledJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, job)
+}
+
+func CreateScheduledJob(c *gin.Context) {
+	var req model.ScheduledJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := scheduler.Validate(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.CreateScheduledJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	scheduler.Put(req)
+	common.SuccessResp(c, req)
+}
+
+func UpdateScheduledJob(c *gin.Context) {
+	var req model.ScheduledJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := scheduler.Validate(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := op.GetScheduledJobById(req.ID); err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err := op.UpdateScheduledJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	scheduler.Put(req)
+	common.SuccessResp(c)
+}
+
+func DeleteScheduledJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteScheduledJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	scheduler.Remove(uint(id))
+	common.SuccessResp(c)
+}
+
+// RunScheduledJob runs the job at once, the result is saved as the last result of the job
+func RunScheduledJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := op.GetScheduledJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err := scheduler.RunNow(*job); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
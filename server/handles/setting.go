@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
@@ -32,11 +33,14 @@ func getRoleOptions() string {
 func ResetToken(c *gin.Context) {
 	token := random.Token()
 	item := model.SettingItem{Key: "token", Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE}
-	if err := op.SaveSettingItem(&item); err != nil {
+	err := op.SaveSettingItem(&item)
+	audit.Log(c, model.AuditLog{Action: audit.ActionResetToken}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
 	sign.Instance()
+	sign.InstanceZip()
 	common.SuccessResp(c, token)
 }
 
@@ -82,6 +86,17 @@ func GetSetting(c *gin.Context) {
 	}
 }
 
+// saveSettingItems saves the items and records the keys of them in the audit log
+func saveSettingItems(c *gin.Context, items []model.SettingItem) error {
+	keys := make([]string, len(items))
+	for i := range items {
+		keys[i] = items[i].Key
+	}
+	err := op.SaveSettingItems(items)
+	audit.Log(c, model.AuditLog{Action: audit.ActionSaveSettings, Detail: strings.Join(keys, ",")}, err)
+	return err
+}
+
 func SaveSettings(c *gin.Context) {
 	var req []model.SettingItem
 	if err := c.ShouldBind(&req); err != nil {
@@ -104,7 +119,7 @@ func SaveSettings(c *gin.Context) {
 		}
 	}
 
-	if err := op.SaveSettingItems(req); err != nil {
+	if err := saveSettingItems(c, req); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -157,7 +172,9 @@ func ListSettings(c *gin.Context) {
 
 func DeleteSetting(c *gin.Context) {
 	key := c.Query("key")
-	if err := op.DeleteSettingItemByKey(key); err != nil {
+	err := op.DeleteSettingItemByKey(key)
+	audit.Log(c, model.AuditLog{Action: audit.ActionDeleteSetting, Detail: key}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
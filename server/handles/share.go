@@ -0,0 +1,326 @@
+package handles
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ShareCreateReq struct {
+	Path         string     `json:"path" binding:"required"`
+	MetaPassword string     `json:"meta_password"` // password of the meta protecting the path
+	Password     string     `json:"password"`
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int64      `json:"max_downloads"`
+	Remark       string     `json:"remark"`
+}
+
+type ShareUpdateReq struct {
+	ID           string     `json:"id" binding:"required"`
+	Password     *string    `json:"password"` // nil keeps the password, empty removes it
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int64      `json:"max_downloads"`
+	Disabled     bool       `json:"disabled"`
+	Remark       string     `json:"remark"`
+}
+
+func FsShareCreate(c *gin.Context) {
+	var req ShareCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "guest can't create share", 403)
+		return
+	}
+	if req.MaxDownloads < 0 {
+		common.ErrorStrResp(c, "max_downloads can't be negative", 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.MetaPassword) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	share := &model.Share{
+		UserID:       user.ID,
+		Path:         reqPath,
+		IsDir:        obj.IsDir(),
+		Expires:      req.Expires,
+		MaxDownloads: req.MaxDownloads,
+		Remark:       req.Remark,
+	}
+	share.SetPassword(req.Password)
+	if err = op.CreateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+func FsShareList(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "guest can't list shares", 403)
+		return
+	}
+	shares, total, err := op.GetSharesByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetShares(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+// getOwnShare returns the share if the user created it or is an admin
+func getOwnShare(c *gin.Context, id string) (*model.Share, bool) {
+	user := c.MustGet("user").(*model.User)
+	share, err := op.GetShareById(id)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return nil, false
+	}
+	if share.UserID != user.ID && !user.IsAdmin() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return nil, false
+	}
+	return share, true
+}
+
+func FsShareUpdate(c *gin.Context) {
+	var req ShareUpdateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.MaxDownloads < 0 {
+		common.ErrorStrResp(c, "max_downloads can't be negative", 400)
+		return
+	}
+	share, ok := getOwnShare(c, req.ID)
+	if !ok {
+		return
+	}
+	if req.Password != nil {
+		share.SetPassword(*req.Password)
+	}
+	share.Expires = req.Expires
+	share.MaxDownloads = req.MaxDownloads
+	share.Disabled = req.Disabled
+	share.Remark = req.Remark
+	if err := op.UpdateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+func FsShareDelete(c *gin.Context) {
+	share, ok := getOwnShare(c, c.Query("id"))
+	if !ok {
+		return
+	}
+	if err := op.DeleteShareById(share.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type ShareObjResp struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+	Type     int       `json:"type"`
+}
+
+type ShareListResp struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Path     string         `json:"path"` // path inside the share
+	Expires  *time.Time     `json:"expires"`
+	Content  []ShareObjResp `json:"content"`
+	Total    int64          `json:"total"`
+	Readme   string         `json:"readme"`
+	Header   string         `json:"header"`
+	Provider string         `json:"provider"`
+}
+
+// ShareBrowse serves /s/:id/*path, folders are listed and files are downloaded.
+// The share is checked against the permissions of its creator on every visit,
+// so removing the permission of the creator revokes the share as well.
+func ShareBrowse(c *gin.Context) {
+	share, err := op.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	password := c.Query("pwd")
+	if password == "" {
+		password = c.GetHeader("Share-Password")
+	}
+	if err = op.CheckShare(share, password); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	creator, err := op.GetUserById(share.UserID)
+	if err != nil || creator.Disabled {
+		common.ErrorStrResp(c, errs.ShareDisabled.Error(), 403)
+		return
+	}
+	innerPath := utils.FixAndCleanPath(c.Param("path"))
+	if !share.IsDir && innerPath != "/" {
+		common.ErrorStrResp(c, errs.ObjectNotFound.Error(), 404)
+		return
+	}
+	reqPath := stdpath.Join(share.Path, innerPath)
+	if model.IsTrashPath(reqPath) {
+		common.ErrorStrResp(c, errs.PermissionDenied.Error(), 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	c.Set("user", creator)
+	// the creator proved the knowledge of the password of the meta protecting the shared path
+	// when the share was created, the metas inside the share need the password of the visitor
+	metaPassword := c.Query("meta_pwd")
+	if meta != nil && utils.IsSubPath(meta.Path, share.Path) {
+		metaPassword = meta.Password
+	}
+	if !common.CanAccessWithRoles(creator, meta, reqPath, metaPassword) {
+		common.ErrorStrResp(c, errs.PermissionDenied.Error(), 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			common.ErrorResp(c, err, 404)
+		} else {
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	if !obj.IsDir() {
+		shareDown(c, share, reqPath, obj.GetSize())
+		return
+	}
+	var req model.PageReq
+	if err = c.ShouldBindQuery(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	objs, err := fs.List(c, reqPath, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	filtered := make([]model.Obj, 0, len(objs))
+	for _, o := range objs {
+		if common.CanReadPathByRole(creator, stdpath.Join(reqPath, o.GetName())) {
+			filtered = append(filtered, o)
+		}
+	}
+	total, objs := pagination(filtered, &req)
+	content := make([]ShareObjResp, 0, len(objs))
+	for _, o := range objs {
+		content = append(content, ShareObjResp{
+			Name:     o.GetName(),
+			Size:     o.GetSize(),
+			IsDir:    o.IsDir(),
+			Modified: o.ModTime(),
+			Type:     utils.GetObjType(o.GetName(), o.IsDir()),
+		})
+	}
+	provider := "unknown"
+	if storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{}); err == nil {
+		provider = storage.GetStorage().Driver
+	}
+	common.SuccessResp(c, ShareListResp{
+		ID:       share.ID,
+		Name:     stdpath.Base(share.Path),
+		Path:     innerPath,
+		Expires:  share.Expires,
+		Content:  content,
+		Total:    int64(total),
+		Readme:   getReadme(meta, reqPath),
+		Header:   getHeader(meta, reqPath),
+		Provider: provider,
+	})
+}
+
+func shareDown(c *gin.Context, share *model.Share, reqPath string, size int64) {
+	// a player requests many ranges of a file, only the requests starting at byte 0 are counted as downloads
+	if c.Request.Method == "GET" && common.RangeStartsAtZero(c.GetHeader("Range"), size) {
+		if err := op.CountShareDownload(share); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+	} else if share.IsDownloadLimitReached() {
+		common.ErrorStrResp(c, errs.ShareDownloadLimitReached.Error(), 403)
+		return
+	}
+	c.Set("path", reqPath)
+	Down(c)
+}
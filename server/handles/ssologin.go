@@ -261,6 +261,7 @@ func OIDCLoginCallback(c *gin.Context) {
 			}
 		}
 		token, err := common.GenerateToken(user)
+		auditLogin(c, user, "sso", "", err)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
 		}
@@ -427,6 +428,7 @@ func SSOLoginCallback(c *gin.Context) {
 		}
 	}
 	token, err := common.GenerateToken(user)
+	auditLogin(c, user, "sso", "", err)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
 	}
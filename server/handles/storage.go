@@ -126,27 +126,8 @@ func LoadAllStorages(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	// reset before responding, the requests coming right after see the storages are loading
 	conf.StoragesLoaded = false
-	go func(storages []model.Storage) {
-		for _, storage := range storages {
-			storageDriver, err := op.GetStorageByMountPath(storage.MountPath)
-			if err != nil {
-				log.Errorf("failed get storage driver: %+v", err)
-				continue
-			}
-			// drop the storage in the driver
-			if err := storageDriver.Drop(context.Background()); err != nil {
-				log.Errorf("failed drop storage: %+v", err)
-				continue
-			}
-			if err := op.LoadStorage(context.Background(), storage); err != nil {
-				log.Errorf("failed get enabled storages: %+v", err)
-				continue
-			}
-			log.Infof("success load storage: [%s], driver: [%s]",
-				storage.MountPath, storage.Driver)
-		}
-		conf.StoragesLoaded = true
-	}(storages)
+	go op.ReloadStorages(context.Background(), storages)
 	common.SuccessResp(c)
 }
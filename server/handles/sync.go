@@ -0,0 +1,151 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type RunSyncJobReq struct {
+	ID     uint `json:"id" form:"id" binding:"required"`
+	DryRun bool `json:"dry_run" form:"dry_run"`
+}
+
+func ListSyncJobs(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	jobs, total, err := op.GetSyncJobs(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: jobs,
+		Total:   total,
+	})
+}
+
+func GetSyncJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := op.GetSyncJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, job)
+}
+
+func CreateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !validSyncJob(c, &req) {
+		return
+	}
+	if err := op.CreateSyncJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	fs.ScheduleSyncJob(req)
+	common.SuccessResp(c, req)
+}
+
+func UpdateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !validSyncJob(c, &req) {
+		return
+	}
+	if _, err := op.GetSyncJobById(req.ID); err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err := op.UpdateSyncJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	fs.ScheduleSyncJob(req)
+	common.SuccessResp(c)
+}
+
+func validSyncJob(c *gin.Context, j *model.SyncJob) bool {
+	if !j.ValidMode() {
+		common.ErrorStrResp(c, "mode must be one of mirror, copy_new and bidirectional", 400)
+		return false
+	}
+	src, dst := utils.FixAndCleanPath(j.SrcPath), utils.FixAndCleanPath(j.DstPath)
+	if utils.IsSubPath(src, dst) || utils.IsSubPath(dst, src) {
+		common.ErrorStrResp(c, "src path and dst path can't contain each other", 400)
+		return false
+	}
+	if j.Interval < 0 {
+		common.ErrorStrResp(c, "interval can't be negative", 400)
+		return false
+	}
+	return true
+}
+
+func DeleteSyncJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteSyncJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	fs.UnscheduleSyncJob(uint(id))
+	common.SuccessResp(c)
+}
+
+func RunSyncJob(c *gin.Context) {
+	var req RunSyncJobReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := op.GetSyncJobById(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	t, err := fs.RunSyncJob(c, job, req.DryRun)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
+// ListSyncActions returns the actions of a sync task, which is the diff for a dry run
+func ListSyncActions(c *gin.Context) {
+	t, ok := fs.SyncTaskManager.GetByID(c.Query("tid"))
+	if !ok {
+		common.ErrorStrResp(c, "task not found", 404)
+		return
+	}
+	common.SuccessResp(c, t.Actions)
+}
@@ -26,6 +26,7 @@ type TaskInfo struct {
 	EndTime     *time.Time  `json:"end_time"`
 	TotalBytes  int64       `json:"total_bytes"`
 	Error       string      `json:"error"`
+	Report      any         `json:"report,omitempty"`
 }
 
 func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
@@ -56,9 +57,24 @@ func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
 		EndTime:     task.GetEndTime(),
 		TotalBytes:  task.GetTotalBytes(),
 		Error:       errMsg,
+		Report:      getTaskReport(task),
 	}
 }
 
+func getTaskReport(t any) any {
+	if r, ok := t.(task.TaskWithReport); ok {
+		return r.GetReport()
+	}
+	return nil
+}
+
+func getTaskReportDetail(t any) (any, bool) {
+	if r, ok := t.(task.TaskWithReport); ok {
+		return r.GetReportDetail(), true
+	}
+	return nil, false
+}
+
 func getTaskInfos[T task.TaskExtensionInfo](tasks []T) []TaskInfo {
 	return utils.MustSliceConvert(tasks, getTaskInfo[T])
 }
@@ -152,6 +168,14 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 	g.POST("/info", getTargetedHandler(manager, func(c *gin.Context, task T) {
 		common.SuccessResp(c, getTaskInfo(task))
 	}))
+	g.POST("/report", getTargetedHandler(manager, func(c *gin.Context, task T) {
+		detail, ok := getTaskReportDetail(task)
+		if !ok {
+			common.ErrorStrResp(c, "the task has no report", 400)
+			return
+		}
+		common.SuccessResp(c, detail)
+	}))
 	g.POST("/cancel", getTargetedHandler(manager, func(c *gin.Context, task T) {
 		manager.Cancel(task.GetID())
 		common.SuccessResp(c)
@@ -223,4 +247,8 @@ func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/s3_transition"), fs.S3TransitionTaskManager)
 	taskRoute(g.Group("/decompress"), fs.ArchiveDownloadTaskManager)
 	taskRoute(g.Group("/decompress_upload"), fs.ArchiveContentUploadTaskManager)
+	taskRoute(g.Group("/compress"), fs.ArchiveCompressTaskManager)
+	taskRoute(g.Group("/archive_test"), fs.ArchiveTestTaskManager)
+	taskRoute(g.Group("/sync"), fs.SyncTaskManager)
+	taskRoute(g.Group("/trash"), fs.TrashTaskManager)
 }
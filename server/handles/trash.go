@@ -0,0 +1,106 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type TrashItemsReq struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+func FsTrashList(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "guest can't list trash", 403)
+		return
+	}
+	items, total, err := op.GetTrashItemsByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+func ListTrash(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	items, total, err := op.GetTrashItems(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+// getOwnTrashItems returns the trash items if the user removed all of them or is an admin
+func getOwnTrashItems(c *gin.Context) ([]*model.TrashItem, bool) {
+	var req TrashItemsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return nil, false
+	}
+	user := c.MustGet("user").(*model.User)
+	items := make([]*model.TrashItem, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		item, err := op.GetTrashItemById(id)
+		if err != nil {
+			common.ErrorResp(c, err, 404)
+			return nil, false
+		}
+		if item.UserID != user.ID && !user.IsAdmin() {
+			common.ErrorStrResp(c, "permission denied", 403)
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
+
+func FsTrashRestore(c *gin.Context) {
+	items, ok := getOwnTrashItems(c)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if err := fs.RestoreTrashItem(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+func FsTrashPurge(c *gin.Context) {
+	items, ok := getOwnTrashItems(c)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if err := fs.PurgeTrashItem(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
@@ -1,10 +1,13 @@
 package handles
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/alist-org/alist/v3/pkg/utils"
 
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -149,7 +152,9 @@ func Cancel2FAById(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.Cancel2FAById(uint(id)); err != nil {
+	err = op.Cancel2FAById(uint(id))
+	audit.Log(c, model.AuditLog{Action: audit.ActionDisable2FA, Detail: "user id " + idStr}, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -165,3 +170,24 @@ func DelUserCache(c *gin.Context) {
 	}
 	common.SuccessResp(c)
 }
+
+// RecalculateUserQuota recalculates the quota usage of the user in the background
+func RecalculateUserQuota(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user, err := op.GetUserById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	go func() {
+		if err := fs.RecalculateQuotaUsage(context.Background(), user); err != nil {
+			log.Errorf("failed recalculate quota usage of user [%s]: %+v", user.Username, err)
+		}
+	}()
+	common.SuccessResp(c)
+}
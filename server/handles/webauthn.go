@@ -112,6 +112,7 @@ func FinishAuthnLogin(c *gin.Context) {
 	}
 
 	token, err := common.GenerateToken(user)
+	auditLogin(c, user, "webauthn", "", err)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
@@ -0,0 +1,130 @@
+package handles
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookDeliveriesReq struct {
+	model.PageReq
+	ID uint `json:"id" form:"id" binding:"required"`
+}
+
+func ListWebhooks(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	webhooks, total, err := op.GetWebhooks(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: webhooks,
+		Total:   total,
+	})
+}
+
+func GetWebhook(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	webhook, err := op.GetWebhookById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, webhook)
+}
+
+func CreateWebhook(c *gin.Context) {
+	var req model.Webhook
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !validWebhook(c, &req) {
+		return
+	}
+	if err := op.CreateWebhook(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, req)
+	}
+}
+
+func UpdateWebhook(c *gin.Context) {
+	var req model.Webhook
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !validWebhook(c, &req) {
+		return
+	}
+	if _, err := op.GetWebhookById(req.ID); err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if err := op.UpdateWebhook(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func validWebhook(c *gin.Context, w *model.Webhook) bool {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		common.ErrorStrResp(c, "url must be an absolute http(s) url", 400)
+		return false
+	}
+	if w.PathPrefix != "" {
+		w.PathPrefix = utils.FixAndCleanPath(w.PathPrefix)
+	}
+	return true
+}
+
+func DeleteWebhook(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteWebhookById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListWebhookDeliveries(c *gin.Context) {
+	var req WebhookDeliveriesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	deliveries, total, err := op.GetWebhookDeliveries(req.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: deliveries,
+		Total:   total,
+	})
+}
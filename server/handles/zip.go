@@ -0,0 +1,272 @@
+package handles
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ZipLinkReq struct {
+	Dir      string   `json:"dir" form:"dir"`
+	Names    []string `json:"names" form:"names"`
+	Password string   `json:"password" form:"password"`
+}
+
+// FsZipLink checks the access of the user and returns the signed link downloading the objs in the dir as a zip,
+// the whole dir is downloaded if no names are given
+func FsZipLink(c *gin.Context) {
+	var req ZipLinkReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	dir, err := user.JoinPath(req.Dir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, dir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(dir)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccessWithRoles(user, meta, dir, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	for _, name := range req.Names {
+		if name == "" || name == "." || name == ".." || stdpath.Base(name) != name {
+			common.ErrorStrResp(c, fmt.Sprintf("invalid name [%s]", name), 400)
+			return
+		}
+		if ok, _ := canZip(user, meta, stdpath.Join(dir, name)); !ok {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	query := url.Values{}
+	for _, name := range req.Names {
+		query.Add("name", name)
+	}
+	query.Set("user", user.Username)
+	query.Set("sign", sign.SignZip(zipSignData(dir, user.Username, req.Names)))
+	common.SuccessResp(c, gin.H{
+		"url": fmt.Sprintf("%s/z%s?%s", common.GetApiUrl(c.Request), utils.EncodePath(dir, true), query.Encode()),
+	})
+}
+
+// skippedZipEntries is the name of the entry listing the paths skipped for their passwords, it's added only if any
+const skippedZipEntries = "skipped_files.txt"
+
+func zipSignData(dir, username string, names []string) string {
+	return strings.Join(append([]string{dir, username}, names...), "\n")
+}
+
+// canZip reports whether the user can download the path in the zip, only the password of the meta
+// checked when the link is created is known, the paths protected by other passwords are skipped.
+// locked is true if the path is skipped only for its password, those are listed in the skippedZipEntries entry
+func canZip(user *model.User, dirMeta *model.Meta, path string) (ok, locked bool) {
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false, false
+	}
+	var password string
+	if meta != nil && dirMeta != nil && meta.ID == dirMeta.ID {
+		password = meta.Password
+	}
+	if common.CanAccessWithRoles(user, meta, path, password) {
+		return true, false
+	}
+	return false, meta != nil && common.CanAccessWithRoles(user, meta, path, meta.Password)
+}
+
+// ZipDown streams the objs in the dir as a zip in store mode, the entries are fetched one by one
+// without staging in the temp dir
+func ZipDown(c *gin.Context) {
+	dir := utils.FixAndCleanPath(c.Param("path"))
+	c.Set("path", dir)
+	names := c.QueryArray("name")
+	username := c.Query("user")
+	if err := sign.VerifyZip(zipSignData(dir, username, names), strings.TrimSuffix(c.Query("sign"), "/")); err != nil {
+		common.ErrorResp(c, err, 401)
+		return
+	}
+	user, err := op.GetUserByName(username)
+	if err != nil || user.Disabled {
+		common.ErrorStrResp(c, "the user of the link is unavailable", 401)
+		return
+	}
+	c.Set("user", user)
+	if !common.CheckPathLimitWithRoles(user, dir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(dir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	var objs []model.Obj
+	if len(names) == 0 {
+		objs, err = fs.List(c, dir, &fs.ListArgs{})
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	} else {
+		for _, name := range names {
+			obj, err := fs.Get(c, stdpath.Join(dir, name), &fs.GetArgs{})
+			if err != nil {
+				common.ErrorResp(c, err, 500)
+				return
+			}
+			objs = append(objs, obj)
+		}
+	}
+	filename := stdpath.Base(dir)
+	if len(names) == 1 {
+		filename = names[0]
+	}
+	if filename == "/" {
+		filename = "download"
+	}
+	filename += ".zip"
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, filename, url.PathEscape(filename)))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	zw := zip.NewWriter(c.Writer)
+	var skipped []string
+	for _, obj := range objs {
+		err = fs.WalkFS(c, -1, stdpath.Join(dir, obj.GetName()), obj, func(path string, info model.Obj) error {
+			name := strings.TrimPrefix(strings.TrimPrefix(path, dir), "/")
+			if ok, locked := canZip(user, meta, path); !ok {
+				if locked {
+					skipped = append(skipped, name)
+				}
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				_, err := zw.CreateHeader(&zip.FileHeader{
+					Name:     name + "/",
+					Method:   zip.Store,
+					Modified: info.ModTime(),
+				})
+				return err
+			}
+			return writeZipEntry(c, zw, name, path, info)
+		})
+		if err != nil {
+			// the response is sent already, the client gets a broken zip
+			log.Errorf("failed zip [%s] in %s: %+v", obj.GetName(), dir, err)
+			return
+		}
+	}
+	if len(skipped) > 0 {
+		// the response is already started, so the paths protected by other passwords are listed in the zip
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     skippedZipEntries,
+			Method:   zip.Store,
+			Modified: time.Now(),
+		})
+		if err == nil {
+			_, err = io.WriteString(w, "skipped since they are protected by other passwords:\n"+strings.Join(skipped, "\n")+"\n")
+		}
+		if err != nil {
+			log.Errorf("failed list skipped paths in zip of %s: %+v", dir, err)
+			return
+		}
+	}
+	if err = zw.Close(); err != nil {
+		log.Errorf("failed close zip of %s: %+v", dir, err)
+	}
+}
+
+func writeZipEntry(c *gin.Context, zw *zip.Writer, name, path string, obj model.Obj) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: obj.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+	if obj.GetSize() == 0 {
+		return nil
+	}
+	link, file, err := fs.Link(c, path, model.LinkArgs{
+		IP:     c.ClientIP(),
+		Header: http.Header{},
+	})
+	if err != nil {
+		return err
+	}
+	rc, err := openZipEntry(c, link, file)
+	if err != nil {
+		return errors.WithMessagef(err, "failed open [%s]", path)
+	}
+	defer rc.Close()
+	n, err := utils.CopyWithBuffer(w, rc)
+	if err != nil {
+		return err
+	}
+	if n != file.GetSize() {
+		return errors.Errorf("the size of [%s] is %d, but %d bytes read", path, file.GetSize(), n)
+	}
+	return nil
+}
+
+func openZipEntry(ctx context.Context, link *model.Link, file model.Obj) (io.ReadCloser, error) {
+	if link.MFile != nil {
+		if _, err := link.MFile.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return &stream.RateLimitFile{
+			File:    link.MFile,
+			Limiter: stream.ServerDownloadLimit,
+			Ctx:     ctx,
+		}, nil
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		var err error
+		rrc, err = stream.GetRangeReadCloserFromLink(file.GetSize(), link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rrc = &stream.RateLimitRangeReadCloser{
+		RangeReadCloserIF: rrc,
+		Limiter:           stream.ServerDownloadLimit,
+	}
+	return rrc.RangeRead(ctx, http_range.Range{Start: 0, Length: file.GetSize()})
+}
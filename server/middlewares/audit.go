@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditDownload records the download in the audit log after it is served,
+// the ranges after the first one requested by a player are not logged again
+func AuditDownload(c *gin.Context) {
+	c.Next()
+	if !common.RangeStartsAtZero(c.GetHeader("Range"), -1) {
+		return
+	}
+	l := model.AuditLog{
+		Action: audit.ActionDownload,
+		Path:   c.GetString("path"),
+		Bytes:  int64(max(c.Writer.Size(), 0)),
+	}
+	if c.Writer.Status() == http.StatusFound {
+		l.Detail = "redirect"
+	}
+	var err error
+	if c.Writer.Status() >= 400 {
+		err = fmt.Errorf("response status %d", c.Writer.Status())
+	}
+	audit.Log(c, l, err)
+}
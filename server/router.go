@@ -4,6 +4,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -43,8 +44,8 @@ func Init(e *gin.Engine) {
 
 	downloadLimiter := middlewares.DownloadRateLimiter(stream.ClientDownloadLimit)
 	signCheck := middlewares.Down(sign.Verify)
-	g.GET("/d/*path", signCheck, downloadLimiter, handles.Down)
-	g.GET("/p/*path", signCheck, downloadLimiter, handles.Proxy)
+	g.GET("/d/*path", middlewares.AuditDownload, signCheck, downloadLimiter, handles.Down)
+	g.GET("/p/*path", middlewares.AuditDownload, signCheck, downloadLimiter, handles.Proxy)
 	g.HEAD("/d/*path", signCheck, handles.Down)
 	g.HEAD("/p/*path", signCheck, handles.Proxy)
 	archiveSignCheck := middlewares.Down(sign.VerifyArchive)
@@ -54,6 +55,11 @@ func Init(e *gin.Engine) {
 	g.HEAD("/ad/*path", archiveSignCheck, handles.ArchiveDown)
 	g.HEAD("/ap/*path", archiveSignCheck, handles.ArchiveProxy)
 	g.HEAD("/ae/*path", archiveSignCheck, handles.ArchiveInternalExtract)
+	g.GET("/z/*path", middlewares.AuditDownload, downloadLimiter, handles.ZipDown)
+	g.GET("/s/:id", downloadLimiter, handles.ShareBrowse)
+	g.GET("/s/:id/*path", downloadLimiter, handles.ShareBrowse)
+	g.HEAD("/s/:id", handles.ShareBrowse)
+	g.HEAD("/s/:id/*path", handles.ShareBrowse)
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -94,8 +100,13 @@ func Init(e *gin.Engine) {
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 	public.Any("/archive_extensions", handles.ArchiveExtensions)
 
+	if conf.Conf.Metrics.Enable && conf.Conf.Metrics.Listen == "" {
+		g.GET("/metrics", middlewares.Auth, middlewares.AuthAdmin, gin.WrapH(metrics.Handler()))
+	}
+
 	_fs(auth.Group("/fs"))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
+	_rss(auth.Group("/rss", middlewares.AuthNotGuest))
 	_label(auth.Group("/label"))
 	_labelFileBinding(auth.Group("/label_file_binding"))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
@@ -107,6 +118,16 @@ func Init(e *gin.Engine) {
 	})
 }
 
+func _rss(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListRssFeeds)
+	g.GET("/get", handles.GetRssFeed)
+	g.POST("/create", handles.CreateRssFeed)
+	g.POST("/update", handles.UpdateRssFeed)
+	g.POST("/delete", handles.DeleteRssFeed)
+	g.POST("/poll", handles.PollRssFeed)
+	g.GET("/items", handles.ListRssItems)
+}
+
 func admin(g *gin.RouterGroup) {
 	meta := g.Group("/meta")
 	meta.GET("/list", handles.ListMetas)
@@ -123,6 +144,7 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.POST("/recalculate_quota", handles.RecalculateUserQuota)
 	user.GET("/sshkey/list", handles.ListPublicKeys)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
 
@@ -160,6 +182,7 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_115", handles.Set115)
 	setting.POST("/set_pikpak", handles.SetPikPak)
 	setting.POST("/set_thunder", handles.SetThunder)
+	setting.POST("/set_ytdlp", handles.SetYtDlp)
 
 	// retain /admin/task API to ensure compatibility with legacy automation scripts
 	_task(g.Group("/task"))
@@ -191,6 +214,41 @@ func admin(g *gin.RouterGroup) {
 	session.GET("/list", handles.ListSessions)
 	session.POST("/evict", handles.EvictSession)
 
+	share := g.Group("/share")
+	share.GET("/list", handles.ListShares)
+
+	trash := g.Group("/trash")
+	trash.GET("/list", handles.ListTrash)
+
+	webhook := g.Group("/webhook")
+	webhook.GET("/list", handles.ListWebhooks)
+	webhook.GET("/get", handles.GetWebhook)
+	webhook.POST("/create", handles.CreateWebhook)
+	webhook.POST("/update", handles.UpdateWebhook)
+	webhook.POST("/delete", handles.DeleteWebhook)
+	webhook.GET("/deliveries", handles.ListWebhookDeliveries)
+
+	audit := g.Group("/audit")
+	audit.Any("/list", handles.ListAuditLogs)
+	audit.GET("/export", handles.ExportAuditLogs)
+
+	syncJob := g.Group("/sync")
+	syncJob.GET("/list", handles.ListSyncJobs)
+	syncJob.GET("/get", handles.GetSyncJob)
+	syncJob.POST("/create", handles.CreateSyncJob)
+	syncJob.POST("/update", handles.UpdateSyncJob)
+	syncJob.POST("/delete", handles.DeleteSyncJob)
+	syncJob.POST("/run", handles.RunSyncJob)
+	syncJob.GET("/actions", handles.ListSyncActions)
+
+	schedule := g.Group("/schedule")
+	schedule.GET("/list", handles.ListScheduledJobs)
+	schedule.GET("/get", handles.GetScheduledJob)
+	schedule.POST("/create", handles.CreateScheduledJob)
+	schedule.POST("/update", handles.UpdateScheduledJob)
+	schedule.POST("/delete", handles.DeleteScheduledJob)
+	schedule.POST("/run", handles.RunScheduledJob)
+
 }
 
 func _fs(g *gin.RouterGroup) {
@@ -216,10 +274,23 @@ func _fs(g *gin.RouterGroup) {
 	// g.POST("/add_qbit", handles.AddQbittorrent)
 	// g.POST("/add_transmission", handles.SetTransmission)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.POST("/resolve_offline_download", handles.ResolveOfflineDownload)
+	g.POST("/zip_link", handles.FsZipLink)
 	a := g.Group("/archive")
 	a.Any("/meta", handles.FsArchiveMeta)
 	a.Any("/list", handles.FsArchiveList)
 	a.POST("/decompress", handles.FsArchiveDecompress)
+	a.POST("/compress", handles.FsArchiveCompress)
+	a.POST("/test", handles.FsArchiveTest)
+	s := g.Group("/share")
+	s.Any("/list", handles.FsShareList)
+	s.POST("/create", handles.FsShareCreate)
+	s.POST("/update", handles.FsShareUpdate)
+	s.POST("/delete", handles.FsShareDelete)
+	t := g.Group("/trash")
+	t.Any("/list", handles.FsTrashList)
+	t.POST("/restore", handles.FsTrashRestore)
+	t.POST("/purge", handles.FsTrashPurge)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -11,6 +11,8 @@ import (
 
 type DriverAdapter struct {
 	FtpDriver *ftp.AferoAdapter
+	// realPath is the path resolved by the last REALPATH, the clients stat it to change the working dir
+	realPath string
 }
 
 func (s *DriverAdapter) OpenFile(_ string, _ uint32, _ *sftpd.Attr) (sftpd.File, error) {
@@ -40,11 +42,17 @@ func (s *DriverAdapter) Rmdir(name string) error {
 }
 
 func (s *DriverAdapter) Stat(name string, _ bool) (*sftpd.Attr, error) {
-	stat, err := s.FtpDriver.Stat(name)
+	stat := s.FtpDriver.Stat
+	if s.realPath != "" && utils.FixAndCleanPath(name) == s.realPath {
+		// the archive is stated as a dir only for changing the working dir, the later stats get it as a file
+		stat = s.FtpDriver.StatDir
+		s.realPath = ""
+	}
+	info, err := stat(name)
 	if err != nil {
 		return nil, err
 	}
-	return fileInfoToSftpAttr(stat), nil
+	return fileInfoToSftpAttr(info), nil
 }
 
 func (s *DriverAdapter) SetStat(_ string, _ *sftpd.Attr) error {
@@ -60,7 +68,8 @@ func (s *DriverAdapter) CreateLink(_, _ string, _ uint32) error {
 }
 
 func (s *DriverAdapter) RealPath(path string) (string, error) {
-	return utils.FixAndCleanPath(path), nil
+	s.realPath = utils.FixAndCleanPath(path)
+	return s.realPath, nil
 }
 
 func (s *DriverAdapter) GetHandle(name string, flags uint32, _ *sftpd.Attr, offset uint64) (sftpd.FileTransfer, error) {
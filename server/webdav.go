@@ -29,7 +29,7 @@ var handler *webdav.Handler
 func WebDav(dav *gin.RouterGroup) {
 	handler = &webdav.Handler{
 		Prefix:     path.Join(conf.URL.Path, "/dav"),
-		LockSystem: webdav.NewMemLS(),
+		LockSystem: newLockSystem(),
 		Logger: func(request *http.Request, err error) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
@@ -49,9 +49,18 @@ func WebDav(dav *gin.RouterGroup) {
 	dav.Handle("MOVE", "/*path", ServeWebDAV)
 }
 
+func newLockSystem() webdav.LockSystem {
+	if conf.Conf.WebDAV.LockSystem == "database" {
+		return webdav.NewPersistentLS(webdav.DBLockStore{})
+	}
+	return webdav.NewMemLS()
+}
+
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = context.WithValue(ctx, "client_ip", c.ClientIP())
+	ctx = context.WithValue(ctx, "device_key", c.GetString("device_key"))
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
@@ -9,12 +9,16 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strconv"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
+	log "github.com/sirupsen/logrus"
 )
 
 // slashClean is equivalent to but slightly more efficient than
@@ -80,8 +84,7 @@ func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // walkFn returns path.SkipDir, walkFS will skip traversal of this node.
 func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj, err error) error) error {
 	// This implementation is based on Walk's code in the standard path/path package.
-	err := walkFn(name, info, nil)
-	if err != nil {
+	if err := walkFn(name, info, nil); err != nil {
 		if info.IsDir() && err == filepath.SkipDir {
 			return nil
 		}
@@ -96,7 +99,13 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	meta, _ := op.GetNearestMeta(name)
 	user := ctx.Value("user").(*model.User)
 	// Read directory names.
-	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
+	var objs []model.Obj
+	var err error
+	if fs.InArchive(info) {
+		objs, err = fs.ArchiveBrowseList(ctx, name)
+	} else {
+		objs, err = fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
+	}
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return walkFn(name, info, err)
@@ -127,3 +136,34 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	}
 	return nil
 }
+
+// canBrowseArchive reports whether the user can browse the archives at the path as dirs
+func canBrowseArchive(ctx context.Context, reqPath string) bool {
+	user := ctx.Value("user").(*model.User)
+	perm := common.MergeRolePermissions(user, reqPath)
+	return common.HasPermission(perm, common.PermReadArchives)
+}
+
+// serveArchiveFile serves the file in the archive, the range isn't supported since the file is extracted on the fly
+func serveArchiveFile(w http.ResponseWriter, r *http.Request, reqPath string, fi model.Obj) (int, error) {
+	rc, size, err := fs.ArchiveBrowseOpen(r.Context(), reqPath)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", utils.GetMimeType(fi.GetName()))
+	if size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	// the archive is read under the server download limit, the extracted file is served under the client one like ftp
+	_, err = utils.CopyWithBuffer(w, &stream.RateLimitReader{
+		Reader:  rc,
+		Limiter: stream.ClientDownloadLimit,
+		Ctx:     r.Context(),
+	})
+	if err != nil {
+		log.Errorf("failed serve [%s] in archive: %+v", reqPath, err)
+	}
+	return 0, nil
+}
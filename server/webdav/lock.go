@@ -125,12 +125,17 @@ type memLS struct {
 	byName  map[string]*memLSNode
 	byToken map[string]*memLSNode
 	gen     uint64
+	// newToken generates tokens instead of gen if it is set
+	newToken func() string
 	// byExpiry only contains those nodes whose LockDetails have a finite
 	// Duration and are yet to expire.
 	byExpiry byExpiry
 }
 
 func (m *memLS) nextToken() string {
+	if m.newToken != nil {
+		return m.newToken()
+	}
 	m.gen++
 	return strconv.FormatUint(m.gen, 10)
 }
@@ -288,6 +293,22 @@ func (m *memLS) Unlock(now time.Time, token string) error {
 	return nil
 }
 
+// restore puts back a lock created earlier, held is whether the lock is held by a Confirm call.
+func (m *memLS) restore(token string, details LockDetails, expiry time.Time, held bool) {
+	details.Root = slashClean(details.Root)
+	n := m.create(details.Root)
+	n.token = token
+	m.byToken[n.token] = n
+	n.details = details
+	n.held = held
+	if n.details.Duration >= 0 {
+		n.expiry = expiry
+		if !held {
+			heap.Push(&m.byExpiry, n)
+		}
+	}
+}
+
 func (m *memLS) canCreate(name string, zeroDepth bool) bool {
 	return walkToRoot(name, func(name0 string, first bool) bool {
 		n := m.byName[name0]
@@ -0,0 +1,209 @@
+package webdav
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LockStore keeps the locks of the LockSystem returned by NewPersistentLS.
+type LockStore interface {
+	// Transaction runs fn with a store whose reads and writes are atomic, the
+	// transactions of all the instances sharing the store are serialized.
+	Transaction(fn func(s LockStore) error) error
+	ListLocks() ([]model.WebDAVLock, error)
+	SaveLock(l *model.WebDAVLock) error
+	DeleteLocks(tokens ...string) error
+}
+
+// DBLockStore is a LockStore backed by the database of alist.
+type DBLockStore struct {
+	tx *gorm.DB
+}
+
+func (s DBLockStore) Transaction(fn func(s LockStore) error) error {
+	return db.WebDAVLockTransaction(func(tx *gorm.DB) error {
+		return fn(DBLockStore{tx: tx})
+	})
+}
+
+func (s DBLockStore) db() *gorm.DB {
+	if s.tx == nil {
+		return db.GetDb()
+	}
+	return s.tx
+}
+
+func (s DBLockStore) ListLocks() ([]model.WebDAVLock, error) {
+	return db.GetWebDAVLocks(s.db())
+}
+
+func (s DBLockStore) SaveLock(l *model.WebDAVLock) error {
+	return db.SaveWebDAVLock(s.db(), l)
+}
+
+func (s DBLockStore) DeleteLocks(tokens ...string) error {
+	return db.DeleteWebDAVLocks(s.db(), tokens...)
+}
+
+// NewPersistentLS returns a LockSystem keeping its locks in store, so the locks
+// survive restarts and are shared by every instance using the same store.
+//
+// Every operation loads the locks into a memLS and writes the changes back in
+// one transaction of the store, so the semantics are exactly the ones of NewMemLS. Whether a lock is held by
+// a Confirm call is only known by the instance serving that request.
+func NewPersistentLS(store LockStore) LockSystem {
+	return &persistentLS{
+		store: store,
+		held:  make(map[string]bool),
+	}
+}
+
+type persistentLS struct {
+	mu    sync.Mutex
+	store LockStore
+	held  map[string]bool
+}
+
+func newLockToken() string {
+	return "opaquelocktoken:" + uuid.NewString()
+}
+
+func (p *persistentLS) load(store LockStore) (*memLS, map[string]model.WebDAVLock, error) {
+	locks, err := store.ListLocks()
+	if err != nil {
+		return nil, nil, err
+	}
+	m := NewMemLS().(*memLS)
+	m.newToken = newLockToken
+	saved := make(map[string]model.WebDAVLock, len(locks))
+	for _, l := range locks {
+		saved[l.Token] = l
+		m.restore(l.Token, LockDetails{
+			Root:      l.Root,
+			Duration:  time.Duration(l.Duration),
+			OwnerXML:  l.OwnerXML,
+			ZeroDepth: l.ZeroDepth,
+		}, l.Expiry, p.held[l.Token])
+	}
+	return m, saved, nil
+}
+
+// save writes the difference between m and saved to the store
+func (p *persistentLS) save(store LockStore, m *memLS, saved map[string]model.WebDAVLock) error {
+	var removed []string
+	for token := range saved {
+		if _, ok := m.byToken[token]; !ok {
+			removed = append(removed, token)
+		}
+	}
+	if len(removed) > 0 {
+		if err := store.DeleteLocks(removed...); err != nil {
+			return err
+		}
+	}
+	for token, n := range m.byToken {
+		l := model.WebDAVLock{
+			Token:     token,
+			Root:      n.details.Root,
+			OwnerXML:  n.details.OwnerXML,
+			ZeroDepth: n.details.ZeroDepth,
+			Duration:  int64(n.details.Duration),
+			Expiry:    n.expiry,
+		}
+		if old, ok := saved[token]; ok && old.Root == l.Root && old.OwnerXML == l.OwnerXML &&
+			old.ZeroDepth == l.ZeroDepth && old.Duration == l.Duration && old.Expiry.Equal(l.Expiry) {
+			continue
+		}
+		if err := store.SaveLock(&l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do runs fn on the locks loaded from the store and writes the changes back in
+// one transaction, expired locks are removed from the store even if fn fails.
+func (p *persistentLS) do(fn func(m *memLS) error) error {
+	var fnErr error
+	err := p.store.Transaction(func(store LockStore) error {
+		m, saved, err := p.load(store)
+		if err != nil {
+			return err
+		}
+		fnErr = fn(m)
+		return p.save(store, m, saved)
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+func (p *persistentLS) Confirm(now time.Time, name0, name1 string, conditions ...Condition) (func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var tokens []string
+	err := p.do(func(m *memLS) error {
+		if _, err := m.Confirm(now, name0, name1, conditions...); err != nil {
+			return err
+		}
+		for token, n := range m.byToken {
+			if n.held && !p.held[token] {
+				tokens = append(tokens, token)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		p.held[token] = true
+	}
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, token := range tokens {
+			delete(p.held, token)
+		}
+	}, nil
+}
+
+func (p *persistentLS) Create(now time.Time, details LockDetails) (token string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err = p.do(func(m *memLS) error {
+		token, err = m.Create(now, details)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (p *persistentLS) Refresh(now time.Time, token string, duration time.Duration) (details LockDetails, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err = p.do(func(m *memLS) error {
+		details, err = m.Refresh(now, token, duration)
+		return err
+	})
+	if err != nil {
+		return LockDetails{}, err
+	}
+	return details, nil
+}
+
+func (p *persistentLS) Unlock(now time.Time, token string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.do(func(m *memLS) error {
+		return m.Unlock(now, token)
+	})
+}
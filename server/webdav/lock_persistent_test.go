@@ -0,0 +1,131 @@
+package webdav
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type memLockStore map[string]model.WebDAVLock
+
+func (s memLockStore) Transaction(fn func(s LockStore) error) error {
+	return fn(s)
+}
+
+func (s memLockStore) ListLocks() ([]model.WebDAVLock, error) {
+	locks := make([]model.WebDAVLock, 0, len(s))
+	for _, l := range s {
+		locks = append(locks, l)
+	}
+	return locks, nil
+}
+
+func (s memLockStore) SaveLock(l *model.WebDAVLock) error {
+	s[l.Token] = *l
+	return nil
+}
+
+func (s memLockStore) DeleteLocks(tokens ...string) error {
+	for _, token := range tokens {
+		delete(s, token)
+	}
+	return nil
+}
+
+func TestPersistentLS(t *testing.T) {
+	p := NewPersistentLS(memLockStore{}).(*persistentLS)
+	testLockSystem(t, p, func() error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		m, _, err := p.load(p.store)
+		if err != nil {
+			return err
+		}
+		return m.consistent()
+	})
+}
+
+func TestPersistentLSRestart(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := memLockStore{}
+	ls := NewPersistentLS(store)
+	infinite, err := ls.Create(now, LockDetails{Root: "/a", Duration: infiniteTimeout, OwnerXML: "<owner/>"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	short, err := ls.Create(now, LockDetails{Root: "/b", Duration: time.Second, ZeroDepth: true})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(store) != 2 {
+		t.Fatalf("store has %d locks, want 2", len(store))
+	}
+
+	// a new instance, e.g. after a restart or on another replica
+	ls = NewPersistentLS(store)
+	if _, err = ls.Create(now, LockDetails{Root: "/a/c", Duration: infiniteTimeout}); err != ErrLocked {
+		t.Fatalf("Create under an infinite-depth lock: got %v, want ErrLocked", err)
+	}
+	release, err := ls.Confirm(now, "/a/c", "", Condition{Token: infinite})
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if err = ls.Unlock(now, infinite); err != ErrLocked {
+		t.Fatalf("Unlock a held lock: got %v, want ErrLocked", err)
+	}
+	release()
+	if err = ls.Unlock(now, infinite); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	later := now.Add(2 * time.Second)
+	if _, err = ls.Refresh(later, short, time.Second); err != ErrNoSuchLock {
+		t.Fatalf("Refresh an expired lock: got %v, want ErrNoSuchLock", err)
+	}
+	if len(store) != 0 {
+		t.Fatalf("store has %d locks, want 0", len(store))
+	}
+}
+
+func TestPersistentLSConcurrent(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file:"+t.TempDir()+"/data.db?_busy_timeout=5000"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+
+	// the instances only share the database, each Create of the same root races with the others
+	now := time.Unix(0, 0)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewPersistentLS(DBLockStore{}).Create(now, LockDetails{Root: "/a", Duration: infiniteTimeout})
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			} else if err != ErrLocked {
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Fatalf("%d instances locked /a, want 1", created)
+	}
+	locks, err := DBLockStore{}.ListLocks()
+	if err != nil || len(locks) != 1 {
+		t.Fatalf("store has %v locks, want 1: %v", locks, err)
+	}
+}
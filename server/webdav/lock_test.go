@@ -450,8 +450,12 @@ func TestMemLSExpiry(t *testing.T) {
 }
 
 func TestMemLS(t *testing.T) {
-	now := time.Unix(0, 0)
 	m := NewMemLS().(*memLS)
+	testLockSystem(t, m, m.consistent)
+}
+
+func testLockSystem(t *testing.T, m LockSystem, consistent func() error) {
+	now := time.Unix(0, 0)
 	rng := rand.New(rand.NewSource(0))
 	tokens := map[string]string{}
 	nConfirm, nCreate, nRefresh, nUnlock := 0, 0, 0, 0
@@ -474,7 +478,7 @@ func TestMemLS(t *testing.T) {
 				if err != nil {
 					t.Fatalf("iteration #%d: Confirm %q: %v", i, name, err)
 				}
-				if err := m.consistent(); err != nil {
+				if err := consistent(); err != nil {
 					t.Fatalf("iteration #%d: inconsistent state: %v", i, err)
 				}
 				release()
@@ -516,7 +520,7 @@ func TestMemLS(t *testing.T) {
 			}
 		}
 
-		if err := m.consistent(); err != nil {
+		if err := consistent(); err != nil {
 			t.Fatalf("iteration #%d: inconsistent state: %v", i, err)
 		}
 	}
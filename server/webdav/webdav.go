@@ -227,6 +227,11 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		return http.StatusForbidden, err
 	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
+	if err != nil && canBrowseArchive(ctx, reqPath) {
+		if afi, e := fs.ArchiveBrowseGet(ctx, reqPath); e == nil {
+			fi, err = afi, nil
+		}
+	}
 	if err != nil {
 		return http.StatusNotFound, err
 	}
@@ -237,6 +242,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if fi.IsDir() {
 		return http.StatusMethodNotAllowed, nil
 	}
+	if fs.InArchive(fi) {
+		return serveArchiveFile(w, r, reqPath, fi)
+	}
 	// Let ServeContent determine the Content-Type header.
 	storage, _ := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
 	downProxyUrl := storage.GetStorage().DownProxyUrl
@@ -628,12 +636,23 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 		return 403, err
 	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
+	if err != nil && canBrowseArchive(ctx, reqPath) {
+		if afi, e := fs.ArchiveBrowseGet(ctx, reqPath); e == nil {
+			fi, err = afi, nil
+		}
+	}
 	if err != nil {
 		if errs.IsNotFoundError(err) {
 			return http.StatusNotFound, err
 		}
 		return http.StatusMethodNotAllowed, err
 	}
+	// the archive is browsed as a dir by the path ending with a slash like file.zip/
+	if !fi.IsDir() && strings.HasSuffix(r.URL.Path, "/") && canBrowseArchive(ctx, reqPath) {
+		if afi, e := fs.ArchiveBrowseDir(ctx, reqPath); e == nil {
+			fi = afi
+		}
+	}
 	depth := infiniteDepth
 	if hdr := r.Header.Get("Depth"); hdr != "" {
 		depth = parseDepth(hdr)